@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"phonecall-cost-processor-service/internal/infrastructure/config"
+	"phonecall-cost-processor-service/internal/infrastructure/fx"
+	"phonecall-cost-processor-service/internal/infrastructure/postgres"
+)
+
+func main() {
+	file := flag.String("file", "", "CSV de cotizaciones con columnas date,from,to,rate")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("❌ Falta -file")
+	}
+
+	in, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("❌ Error abriendo %s: %v", *file, err)
+	}
+	defer in.Close()
+
+	rates, err := fx.ReadRatesCSV(in)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	cfg := config.Load()
+	db, err := postgres.NewPostgresConnection(cfg.DBUrl)
+	if err != nil {
+		log.Fatalf("❌ Error conectando a PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	if err := postgres.NewPostgresFXRateRepository(db).SaveRates(rates); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	log.Printf("✅ %d cotizaciones importadas", len(rates))
+}
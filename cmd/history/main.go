@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"phonecall-cost-processor-service/internal/infrastructure/config"
+	"phonecall-cost-processor-service/internal/infrastructure/postgres"
+)
+
+func main() {
+	callID := flag.String("call-id", "", "call_id cuya historia se lista")
+	flag.Parse()
+	if *callID == "" {
+		log.Fatal("❌ -call-id es obligatorio")
+	}
+
+	cfg := config.Load()
+	db, err := postgres.NewPostgresConnection(cfg.DBUrl)
+	if err != nil {
+		log.Fatalf("❌ Error conectando a PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	events, err := postgres.NewPostgresCallRepository(db).CallHistory(*callID)
+	if err != nil {
+		log.Fatalf("❌ Error leyendo historial: %v", err)
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write([]string{"at", "type", "detail"})
+	for _, e := range events {
+		_ = w.Write([]string{e.At.Format(time.RFC3339), e.Type, e.Detail})
+	}
+	w.Flush()
+}
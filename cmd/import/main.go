@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"phonecall-cost-processor-service/internal/application"
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/model/services"
+	portclient "phonecall-cost-processor-service/internal/domain/port/client"
+	"phonecall-cost-processor-service/internal/infrastructure/cdr"
+	"phonecall-cost-processor-service/internal/infrastructure/client"
+	"phonecall-cost-processor-service/internal/infrastructure/config"
+	"phonecall-cost-processor-service/internal/infrastructure/handler"
+	"phonecall-cost-processor-service/internal/infrastructure/messaging"
+	"phonecall-cost-processor-service/internal/infrastructure/postgres"
+)
+
+// releasingUseCase reprocesa los eventos estacionados de cada llamada
+// importada, como hace el dispatcher después de un new_incoming_call.
+type releasingUseCase struct {
+	application.IIncomingCallUseCase
+	events *messaging.EventReleaser
+}
+
+func (uc releasingUseCase) Execute(call model.NewIncomingCall) error {
+	if err := uc.IIncomingCallUseCase.Execute(call); err != nil {
+		return err
+	}
+	return uc.events.Release(call.CallID)
+}
+
+func main() {
+	file := flag.String("file", "", "archivo CDR a importar (CSV o JSON-lines)")
+	format := flag.String("format", "", "csv | jsonl (por defecto se infiere de la extensión)")
+	mapping := flag.String("map", "", "mapping de columnas campo=columna separado por comas (ej: call_id=id,caller=origen)")
+	batchSize := flag.Int("batch", 500, "filas por lote")
+	workers := flag.Int("workers", 1, "filas de cada lote procesadas a la vez (con COST_BATCH_WINDOW sus costos se consultan en lote)")
+	checkpoint := flag.String("checkpoint", "", "archivo de checkpoint (por defecto <file>.checkpoint)")
+	errorsPath := flag.String("errors", "", "reporte de filas rechazadas (por defecto <file>.errors.csv)")
+	tsFormats := flag.String("timestamp-formats", "", "formatos de start_timestamp separados por comas (por defecto TIMESTAMP_FORMATS)")
+	timezone := flag.String("timezone", "", "zona horaria de los timestamps sin offset del carrier (por defecto TIMESTAMP_TIMEZONE)")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("❌ Falta -file")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+	if *checkpoint == "" {
+		*checkpoint = *file + ".checkpoint"
+	}
+	if *errorsPath == "" {
+		*errorsPath = *file + ".errors.csv"
+	}
+
+	cfg := config.Load()
+	if *tsFormats == "" {
+		*tsFormats = cfg.TimestampFormats
+	}
+	if *timezone == "" {
+		*timezone = cfg.TimestampZone
+	}
+
+	columns, err := cdr.ParseColumnMapping(*mapping)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	columns.Timestamps, err = config.NewTimestampParser(*tsFormats, *timezone)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	in, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("❌ Error abriendo %s: %v", *file, err)
+	}
+	defer in.Close()
+
+	var reader cdr.Reader
+	switch *format {
+	case "csv":
+		reader, err = cdr.NewCSVReader(in, columns)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	case "jsonl", "ndjson":
+		reader = cdr.NewJSONLinesReader(in, columns)
+	default:
+		log.Fatalf("❌ Formato no soportado: %s", *format)
+	}
+
+	// Al retomar se agrega al reporte existente; un import nuevo lo reemplaza.
+	reportFlags := os.O_CREATE | os.O_APPEND | os.O_WRONLY
+	if _, err := os.Stat(*checkpoint); errors.Is(err, os.ErrNotExist) {
+		reportFlags |= os.O_TRUNC
+	}
+	report, err := os.OpenFile(*errorsPath, reportFlags, 0o644)
+	if err != nil {
+		log.Fatalf("❌ Error abriendo reporte de errores: %v", err)
+	}
+	defer report.Close()
+
+	db, err := postgres.NewPostgresConnection(cfg.DBUrl)
+	if err != nil {
+		log.Fatalf("❌ Error conectando a PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	callRepo := postgres.NewPostgresCallRepository(db)
+	authOpts, err := cfg.CostAPIAuthOptions()
+	if err != nil {
+		log.Fatalf("❌ Error configurando autenticación de la API de costos: %v", err)
+	}
+	costBatchSize, costBatchWindow, err := cfg.CostBatching()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	var costClient portclient.CostClient = client.NewHttpCostClient(cfg.CostAPIUrl, append(authOpts, client.WithBatchSize(costBatchSize))...)
+	if costBatchWindow > 0 && *workers > 1 {
+		log.Printf("📦 Agrupando consultas de costo en lotes de hasta %d cada %s", costBatchSize, costBatchWindow)
+		costClient = client.NewMicroBatcher(costClient, costBatchSize, costBatchWindow)
+	}
+	fx := services.NewFXConverter(postgres.NewPostgresFXRateRepository(db), cfg.BaseCurrency)
+	serviceOpts := []services.CallServiceOption{services.WithFXNormalization(fx)}
+	phones, err := cfg.PhoneNormalizer()
+	if err != nil {
+		log.Fatalf("❌ PHONE_DEFAULT_COUNTRY inválido: %v", err)
+	}
+	if phones != nil {
+		serviceOpts = append(serviceOpts, services.WithPhoneNormalization(phones))
+	}
+	creditPolicy, err := cfg.CreditPolicy()
+	if err != nil {
+		log.Fatalf("❌ QUALITY_CREDITS inválido: %v", err)
+	}
+	// Los eventos que el servicio estacionó esperando estas llamadas
+	parked := map[string]messaging.Handler{
+		"refund_reversed":    handler.NewRefundReversedHandler(application.NewRefundReversedUseCase(callRepo)),
+		"call_quality_issue": handler.NewCallQualityIssueHandler(application.NewCallQualityIssueUseCase(callRepo, creditPolicy)),
+		"call_cost_adjusted": handler.NewCallCostAdjustedHandler(application.NewCallCostAdjustedUseCase(callRepo)),
+	}
+	useCase := releasingUseCase{
+		IIncomingCallUseCase: application.NewIncomingCallUseCase(services.NewCallService(callRepo, costClient, serviceOpts...)),
+		events:               messaging.NewEventReleaser(parked, callRepo, messaging.NewMetrics()),
+	}
+
+	res, err := cdr.NewImporter(useCase, *batchSize, *checkpoint, report, nil, cdr.WithWorkers(*workers)).Run(reader)
+	if err != nil {
+		log.Fatalf("❌ Import interrumpido (se puede retomar con el mismo comando): %v", err)
+	}
+
+	log.Printf("✅ Import finalizado: leídas=%d importadas=%d rechazadas=%d salteadas=%d (errores en %s)",
+		res.Read, res.Imported, res.Rejected, res.Skipped, *errorsPath)
+}
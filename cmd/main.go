@@ -1,15 +1,26 @@
 package main
 
 import (
+	"expvar"
 	"log"
+	"net/http"
 	"phonecall-cost-processor-service/internal/application"
 	"phonecall-cost-processor-service/internal/domain/model/services"
+	portclient "phonecall-cost-processor-service/internal/domain/port/client"
+	portmessaging "phonecall-cost-processor-service/internal/domain/port/messaging"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+	"time"
 
+	"phonecall-cost-processor-service/internal/infrastructure/admin"
 	"phonecall-cost-processor-service/internal/infrastructure/client"
 	"phonecall-cost-processor-service/internal/infrastructure/config"
 	"phonecall-cost-processor-service/internal/infrastructure/handler"
+	"phonecall-cost-processor-service/internal/infrastructure/memory"
+	"phonecall-cost-processor-service/internal/infrastructure/messaging"
+	"phonecall-cost-processor-service/internal/infrastructure/messaging/jsonl"
 	"phonecall-cost-processor-service/internal/infrastructure/postgres"
 	"phonecall-cost-processor-service/internal/infrastructure/rabbitmq"
+	"phonecall-cost-processor-service/internal/infrastructure/rating"
 	"phonecall-cost-processor-service/mock"
 )
 
@@ -18,44 +29,266 @@ func main() {
 	// 🚀 Iniciar API de costos mock si estás en local
 	mock.StartMockCostAPI()
 
-	// PostgreSQL
-	db, err := postgres.NewPostgresConnection(cfg.DBUrl)
-	if err != nil {
-		log.Fatalf("❌ Error conectando a PostgreSQL: %v", err)
+	// Repositorio: PostgreSQL o en memoria para desarrollo local sin Docker
+	var callRepo repository.CallRepository
+	var fxRates repository.FXRateReader
+	var rateCards repository.RateCardReader
+	if cfg.CallRepository == "memory" {
+		log.Println("⚠️ Usando repositorio en memoria: los datos se pierden al reiniciar")
+		callRepo = memory.NewCallRepository()
+		fxRates = memory.NewFXRateRepository()
+	} else {
+		db, err := postgres.NewPostgresConnection(cfg.DBUrl)
+		if err != nil {
+			log.Fatalf("❌ Error conectando a PostgreSQL: %v", err)
+		}
+		defer db.Close()
+		callRepo = postgres.NewPostgresCallRepository(db)
+		fxRates = postgres.NewPostgresFXRateRepository(db)
+		rateCards = postgres.NewPostgresRateCardRepository(db)
 	}
-	defer db.Close()
 
-	// RabbitMQ
-	rabbitConn, rabbitCh, err := rabbitmq.NewRabbitConn(cfg.RabbitURL)
-	if err != nil {
-		log.Fatalf("❌ Error conectando a RabbitMQ: %v", err)
+	// Tarifarios: archivos versionados en RATE_CARDS_DIR o la tabla rate_cards
+	if cfg.RateCardsDir != "" {
+		fileCards, err := rating.LoadDir(cfg.RateCardsDir)
+		if err != nil {
+			log.Fatalf("❌ Error cargando tarifarios: %v", err)
+		}
+		rateCards = fileCards
 	}
-	defer rabbitConn.Close()
-	defer rabbitCh.Close()
 
 	// Dependencias
-	callRepo := postgres.NewPostgresCallRepository(db)
-	costClient := client.NewHttpCostClient(cfg.CostAPIUrl)
-	callService := services.NewCallService(callRepo, costClient)
+	authOpts, err := cfg.CostAPIAuthOptions()
+	if err != nil {
+		log.Fatalf("❌ Error configurando autenticación de la API de costos: %v", err)
+	}
+	batchSize, batchWindow, err := cfg.CostBatching()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	// El consumidor procesa un mensaje a la vez: no hay consultas concurrentes
+	// que agrupar y la ventana solo demoraría cada una.
+	if batchWindow > 0 {
+		log.Fatalf("❌ COST_BATCH_WINDOW solo aplica a cmd/import -workers; el consumidor procesa un mensaje a la vez")
+	}
+	// Las credenciales de COST_API_* son solo de COST_API_URL: los proveedores
+	// del ruteo usan las suyas y la API en sombra va sin credenciales.
+	var costClient portclient.CostClient = client.NewHttpCostClient(cfg.CostAPIUrl, append(authOpts, client.WithBatchSize(batchSize))...)
+	if cfg.CostRoutingFile != "" {
+		costClient = newRoutingCostClient(cfg.CostRoutingFile, callRepo, rateCards, client.WithBatchSize(batchSize))
+	}
+	newRatingEngine := func() *services.RatingEngine {
+		if rateCards == nil {
+			log.Fatal("❌ El motor de tarifación local requiere RATE_CARDS_DIR con el repositorio en memoria")
+		}
+		return services.NewRatingEngine(callRepo, rateCards)
+	}
+	if cfg.LocalRating != "off" {
+		engine := newRatingEngine()
+		switch cfg.LocalRating {
+		case "primary":
+			log.Println("ℹ️ Tarifando llamadas con el motor local")
+			costClient = engine
+		case "fallback":
+			costClient = client.NewFallbackCostClient(costClient, engine)
+		default:
+			log.Fatalf("❌ LOCAL_RATING inválido: %s", cfg.LocalRating)
+		}
+	}
+	fx := services.NewFXConverter(fxRates, cfg.BaseCurrency)
+	serviceOpts := []services.CallServiceOption{services.WithFXNormalization(fx)}
+	phones, err := cfg.PhoneNormalizer()
+	if err != nil {
+		log.Fatalf("❌ PHONE_DEFAULT_COUNTRY inválido: %v", err)
+	}
+	if phones != nil {
+		log.Printf("☎️ Normalizando números a E.164 (país por defecto %s)", cfg.PhoneCountry)
+		serviceOpts = append(serviceOpts, services.WithPhoneNormalization(phones))
+	}
+
+	// Proveedor en sombra: se consulta en paralelo y solo se guarda para comparar
+	switch cfg.ShadowProvider {
+	case "off":
+	case "local":
+		serviceOpts = append(serviceOpts, services.WithShadowPricing("local", newRatingEngine(), callRepo))
+	case "api":
+		serviceOpts = append(serviceOpts, services.WithShadowPricing("api", client.NewHttpCostClient(cfg.ShadowCostAPIUrl), callRepo))
+	default:
+		log.Fatalf("❌ SHADOW_PROVIDER inválido: %s", cfg.ShadowProvider)
+	}
+	if cfg.ShadowProvider != "off" {
+		log.Printf("👥 Tarifación en sombra activa con proveedor %s", cfg.ShadowProvider)
+	}
+	callService := services.NewCallService(callRepo, costClient, serviceOpts...)
 
 	// Casos de uso
 	incomingUseCase := application.NewIncomingCallUseCase(callService)
 	refundUseCase := application.NewRefundCallUseCase(callRepo)
+	reversalUseCase := application.NewRefundReversedUseCase(callRepo)
+	creditPolicy, err := cfg.CreditPolicy()
+	if err != nil {
+		log.Fatalf("❌ QUALITY_CREDITS inválido: %v", err)
+	}
+	qualityUseCase := application.NewCallQualityIssueUseCase(callRepo, creditPolicy)
+	adjustUseCase := application.NewCallCostAdjustedUseCase(callRepo)
+	var bulkUseCaseOpts []application.BulkRefundOption
+	if phones != nil {
+		bulkUseCaseOpts = append(bulkUseCaseOpts, application.WithCallerNormalization(phones))
+	}
+	bulkRefundUseCase := application.NewBulkRefundUseCase(callRepo, refundUseCase, 500, bulkUseCaseOpts...)
+
+	// Fuente de mensajes
+	var source portmessaging.MessageSource
+	var bulkRefundOpts []handler.BulkRefundHandlerOption
+	switch cfg.MessageSource {
+	case "file":
+		fileSource, err := jsonl.Open(cfg.MessageFile)
+		if err != nil {
+			log.Fatalf("❌ Error abriendo %s: %v", cfg.MessageFile, err)
+		}
+		source = fileSource
+	default:
+		rabbitConn, rabbitCh, err := rabbitmq.NewRabbitConn(cfg.RabbitURL)
+		if err != nil {
+			log.Fatalf("❌ Error conectando a RabbitMQ: %v", err)
+		}
+		defer rabbitConn.Close()
+		source = rabbitmq.NewSource(rabbitCh, cfg.RabbitQueue)
+		// Los refunds masivos se aplican de a un lote por mensaje
+		bulkRefundOpts = append(bulkRefundOpts, handler.WithContinuation(rabbitmq.NewPublisher(rabbitCh, cfg.RabbitQueue, "/phonecall-cost-processor/bulk-refund")))
+	}
+	defer source.Close()
 
 	// Handlers
-	incomingHandler := handler.NewIncomingCallHandler(incomingUseCase)
+	timestamps, err := cfg.TimestampParser()
+	if err != nil {
+		log.Fatalf("❌ TIMESTAMP_FORMATS/TIMESTAMP_TIMEZONE inválidos: %v", err)
+	}
+	sourceTimestamps, err := cfg.SourceTimestampParsers()
+	if err != nil {
+		log.Fatalf("❌ TIMESTAMP_TIMEZONES inválido: %v", err)
+	}
+	incomingHandler := handler.NewIncomingCallHandler(incomingUseCase, handler.WithTimestampParser(timestamps), handler.WithSourceTimestampParsers(sourceTimestamps))
 	refundHandler := handler.NewRefundCallHandler(refundUseCase)
+	reversalHandler := handler.NewRefundReversedHandler(reversalUseCase)
+	qualityHandler := handler.NewCallQualityIssueHandler(qualityUseCase)
+	adjustHandler := handler.NewCallCostAdjustedHandler(adjustUseCase)
+	bulkRefundHandler := handler.NewBulkRefundHandler(bulkRefundUseCase, bulkRefundOpts...)
+
+	handlerMap := map[string]messaging.Handler{
+		"new_incoming_call":  incomingHandler,
+		"refund_call":        refundHandler,
+		"refund_reversed":    reversalHandler,
+		"call_quality_issue": qualityHandler,
+		"call_cost_adjusted": adjustHandler,
+		"bulk_refund":        bulkRefundHandler,
+	}
+
+	// Métricas: contadores por tipo de mensaje y resultado en /debug/vars
+	metrics := messaging.NewMetrics()
+	metrics.Publish("dispatcher")
+	expiredPlaceholders := new(expvar.Int)
+	expvar.Publish("placeholders_expired", expiredPlaceholders)
+	expiredEvents := new(expvar.Int)
+	expvar.Publish("pending_events_expired", expiredEvents)
+	if cfg.MetricsAddr != "" {
+		go func() {
+			log.Printf("📊 Métricas en http://%s/debug/vars", cfg.MetricsAddr)
+			if err := http.ListenAndServe(cfg.MetricsAddr, expvar.Handler()); err != nil {
+				log.Printf("⚠️ Servidor de métricas detenido: %v", err)
+			}
+		}()
+	}
+
+	// Expiración de placeholders REFUND_PARTIALLY y eventos estacionados cuya
+	// llamada no llegó, en un mismo barrido
+	ttl, pendingTTL, sweep, err := cfg.Expiry()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if ttl > 0 || pendingTTL > 0 {
+		var sweeps []func()
+		if ttl > 0 {
+			expireUseCase := application.NewExpirePlaceholdersUseCase(callRepo, ttl, application.WithExpiredCounter(expiredPlaceholders))
+			sweeps = append(sweeps, func() {
+				if _, err := expireUseCase.Execute(); err != nil {
+					log.Printf("⚠️ Error expirando placeholders: %v", err)
+				}
+			})
+			log.Printf("⏳ Expirando placeholders sin llamada después de %s (cada %s)", ttl, sweep)
+		}
+		if pendingTTL > 0 {
+			expireEvents := application.NewExpirePendingEventsUseCase(callRepo, pendingTTL, application.WithExpiredEventsCounter(expiredEvents))
+			sweeps = append(sweeps, func() {
+				if _, err := expireEvents.Execute(); err != nil {
+					log.Printf("⚠️ Error expirando eventos estacionados: %v", err)
+				}
+			})
+			log.Printf("⏳ Descartando eventos estacionados sin llamada después de %s (cada %s)", pendingTTL, sweep)
+		}
+		expire := func() {
+			for _, s := range sweeps {
+				s()
+			}
+		}
+		go func() {
+			// Primer barrido al arrancar, sin esperar un intervalo completo
+			expire()
+			for range time.Tick(sweep) {
+				expire()
+			}
+		}()
+	}
+
+	// API de administración: ajustes manuales de costo y refunds masivos
+	if cfg.AdminAddr != "" {
+		if cfg.AdminToken == "" {
+			log.Fatalf("❌ ADMIN_ADDR requiere ADMIN_TOKEN")
+		}
+		go func() {
+			log.Printf("🛠️ API de administración en http://%s/admin", cfg.AdminAddr)
+			if err := http.ListenAndServe(cfg.AdminAddr, admin.NewHandler(adjustUseCase, bulkRefundUseCase, callRepo, cfg.AdminToken)); err != nil {
+				log.Printf("⚠️ API de administración detenida: %v", err)
+			}
+		}()
+	}
 
-	handlerMap := map[string]rabbitmq.Handler{
-		"new_incoming_call": incomingHandler,
-		"refund_call":       refundHandler,
+	retries, err := cfg.RetryPolicy()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
 	}
 
-	// Consumidor
-	if err := rabbitmq.StartConsumingMessages(rabbitCh, cfg.RabbitQueue, handlerMap); err != nil {
+	// Consumidor: corre hasta que la fuente se agota (archivo) o se cierra la conexión.
+	// Los errores reintentables se reintentan con backoff hasta MESSAGE_MAX_ATTEMPTS.
+	// Los eventos de llamadas que todavía no llegaron se estacionan hasta su
+	// new_incoming_call; refund_call conserva su placeholder REFUND_PARTIALLY.
+	dispatcher := messaging.NewDispatcher(handlerMap,
+		messaging.WithMetrics(metrics),
+		messaging.WithRetryPolicy(retries),
+		messaging.WithEarlyEvents(callRepo, "refund_call"))
+	if err := dispatcher.Run(source); err != nil {
 		log.Fatalf("❌ Error iniciando consumidor: %v", err)
 	}
-	
-	// Mantenemos el programa corriendo para escuchar mensajes
-	select {}
+}
+
+// Varios proveedores de costo ruteados según COST_ROUTING_FILE
+func newRoutingCostClient(path string, callRepo repository.CallRepository, rateCards repository.RateCardReader, opts ...client.Option) portclient.CostClient {
+	routing, err := client.LoadRoutingConfig(path)
+	if err != nil {
+		log.Fatalf("❌ Error cargando ruteo de proveedores: %v", err)
+	}
+	var local portclient.CostClient
+	if rateCards != nil {
+		local = services.NewRatingEngine(callRepo, rateCards)
+	}
+	providers, err := routing.NewClients(local, opts...)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	router, err := client.NewRoutingCostClient(callRepo, routing.Routing, providers)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	log.Printf("🔀 Ruteando costos entre %d proveedores", len(providers))
+	return router
 }
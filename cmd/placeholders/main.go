@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"phonecall-cost-processor-service/internal/application"
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/infrastructure/config"
+	"phonecall-cost-processor-service/internal/infrastructure/postgres"
+)
+
+func main() {
+	buckets := flag.String("buckets", "", "cortes de antigüedad separados por coma, p. ej. 1h,24h,168h (por defecto 1h,1d,7d,30d)")
+	expire := flag.Duration("expire", 0, "si se indica, expira antes los placeholders más viejos que esta duración")
+	flag.Parse()
+
+	bounds := model.DefaultAgingBounds
+	if *buckets != "" {
+		bounds = nil
+		for _, s := range strings.Split(*buckets, ",") {
+			d, err := time.ParseDuration(strings.TrimSpace(s))
+			if err != nil {
+				log.Fatalf("❌ -buckets inválido: %v", err)
+			}
+			if d <= 0 || (len(bounds) > 0 && d <= bounds[len(bounds)-1]) {
+				log.Fatalf("❌ -buckets inválido: los cortes deben ser positivos y crecientes")
+			}
+			bounds = append(bounds, d)
+		}
+	}
+
+	cfg := config.Load()
+	db, err := postgres.NewPostgresConnection(cfg.DBUrl)
+	if err != nil {
+		log.Fatalf("❌ Error conectando a PostgreSQL: %v", err)
+	}
+	defer db.Close()
+	repo := postgres.NewPostgresCallRepository(db)
+
+	if *expire > 0 {
+		if _, err := application.NewExpirePlaceholdersUseCase(repo, *expire).Execute(); err != nil {
+			log.Fatalf("❌ Error expirando placeholders: %v", err)
+		}
+	}
+
+	aging, err := application.NewPlaceholderAgingReportUseCase(repo).Execute(time.Now().UTC(), bounds)
+	if err != nil {
+		log.Fatalf("❌ Error generando reporte: %v", err)
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write([]string{"bucket", "count", "oldest"})
+	for _, b := range aging {
+		oldest := ""
+		if b.Oldest != nil {
+			oldest = b.Oldest.UTC().Format(time.RFC3339)
+		}
+		_ = w.Write([]string{b.Label(), strconv.Itoa(b.Count), oldest})
+	}
+	w.Flush()
+}
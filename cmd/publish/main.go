@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"phonecall-cost-processor-service/internal/infrastructure/config"
+	"phonecall-cost-processor-service/internal/infrastructure/rabbitmq"
+	"phonecall-cost-processor-service/internal/infrastructure/rabbitmq/dto"
+	"phonecall-cost-processor-service/internal/infrastructure/traffic"
+
+	"github.com/google/uuid"
+)
+
+func main() {
+	msgType := flag.String("type", traffic.TypeNewIncomingCall, "tipo de mensaje: new_incoming_call | refund_call | refund_reversed | call_quality_issue | call_cost_adjusted")
+	callID := flag.String("call-id", "", "call_id del mensaje (por defecto un UUID nuevo para new_incoming_call)")
+	caller := flag.String("caller", "+12025550100", "número de origen")
+	receiver := flag.String("receiver", "+5491122223333", "número de destino")
+	duration := flag.Int("duration", 60, "duración en segundos")
+	start := flag.String("start", "", "start_timestamp RFC3339, epoch en ms o local (por defecto ahora)")
+	reason := flag.String("reason", "Refund manual", "motivo del refund o del ajuste de costo")
+	refundID := flag.String("refund-id", "", "refund_id del refund (idempotencia) o del refund a anular con refund_reversed")
+	amount := flag.String("amount", "", "monto de un refund parcial, en la moneda de -currency")
+	currency := flag.String("currency", "", "moneda del monto de un refund parcial o del costo ajustado")
+	percent := flag.Int("percent", 0, "porcentaje de un refund parcial (1-100)")
+	severity := flag.String("severity", "medium", "severidad del problema de calidad: low | medium | high | critical")
+	issueType := flag.String("issue-type", "dropped", "tipo de problema de calidad")
+	cost := flag.String("cost", "", "costo fijado por call_cost_adjusted, en la moneda de -currency")
+	actor := flag.String("actor", "", "quién ajusta el costo con call_cost_adjusted")
+
+	file := flag.String("file", "", "archivo con mensajes {type, body} (objeto, array o JSON-lines)")
+
+	generate := flag.Int("generate", 0, "cantidad de llamadas sintéticas a generar")
+	duplicates := flag.Float64("duplicates", 0, "fracción de llamadas generadas que se publican duplicadas")
+	refunds := flag.Float64("refunds", 0, "fracción de llamadas generadas que reciben refund")
+	outOfOrder := flag.Bool("out-of-order", false, "publicar el refund antes que la llamada")
+
+	rate := flag.Float64("rate", 0, "mensajes por segundo (0 = sin límite)")
+	cloudEvents := flag.Bool("cloudevents", false, "publicar como CloudEvents estructurados")
+	source := flag.String("source", "/phonecall-cost-processor/publisher", "atributo source de los CloudEvents")
+	queue := flag.String("queue", "", "cola destino (por defecto RABBITMQ_QUEUE)")
+	flag.Parse()
+
+	msgs, err := buildMessages(*file, *generate, traffic.GenerateOptions{
+		Count:          *generate,
+		DuplicateRatio: *duplicates,
+		RefundRatio:    *refunds,
+		OutOfOrder:     *outOfOrder,
+	}, func() (traffic.Message, error) {
+		if *callID == "" {
+			if *msgType != traffic.TypeNewIncomingCall {
+				return traffic.Message{}, fmt.Errorf("falta -call-id para %s", *msgType)
+			}
+			*callID = uuid.NewString()
+		}
+		switch *msgType {
+		case traffic.TypeRefundCall:
+			refund := dto.RefundCallDTO{CallID: *callID, Reason: *reason, RefundID: *refundID, Currency: *currency}
+			refund.Amount = json.Number(*amount)
+			if *percent != 0 {
+				refund.Percent = percent
+			}
+			return traffic.NewMessage(*msgType, refund)
+		case traffic.TypeRefundReversed:
+			return traffic.NewMessage(*msgType, dto.RefundReversedDTO{CallID: *callID, RefundID: *refundID, Reason: *reason})
+		case traffic.TypeCallQualityIssue:
+			return traffic.NewMessage(*msgType, dto.CallQualityIssueDTO{CallID: *callID, Severity: *severity, IssueType: *issueType})
+		case traffic.TypeCallCostAdjusted:
+			return traffic.NewMessage(*msgType, dto.CallCostAdjustedDTO{CallID: *callID, Cost: json.Number(*cost), Currency: *currency, Reason: *reason, Actor: *actor})
+		}
+		ts := *start
+		if ts == "" {
+			ts = time.Now().UTC().Format(time.RFC3339)
+		}
+		return traffic.NewMessage(*msgType, dto.NewIncomingCallDTO{
+			CallID:         *callID,
+			Caller:         *caller,
+			Receiver:       *receiver,
+			DurationInSec:  *duration,
+			StartTimestamp: dto.Timestamp(ts),
+		})
+	})
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	cfg := config.Load()
+	if *queue == "" {
+		*queue = cfg.RabbitQueue
+	}
+	conn, ch, err := rabbitmq.NewRabbitConn(cfg.RabbitURL)
+	if err != nil {
+		log.Fatalf("❌ Error conectando a RabbitMQ: %v", err)
+	}
+	defer conn.Close()
+	defer ch.Close()
+	publisher := rabbitmq.NewPublisher(ch, *queue, *source)
+
+	var tick <-chan time.Time
+	if *rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for i, m := range msgs {
+		if tick != nil {
+			<-tick
+		}
+		if *cloudEvents {
+			err = publisher.Publish(m.Type, m.CallID, m.Body)
+		} else {
+			err = publisher.PublishLegacy(m.Type, m.Body)
+		}
+		if err != nil {
+			log.Fatalf("❌ Error publicando mensaje %d: %v", i+1, err)
+		}
+		log.Printf("📤 %s call_id=%s", m.Type, m.CallID)
+	}
+	log.Printf("✅ %d mensajes publicados en %s", len(msgs), *queue)
+}
+
+func buildMessages(file string, generate int, opts traffic.GenerateOptions, fromFlags func() (traffic.Message, error)) ([]traffic.Message, error) {
+	switch {
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		return traffic.ParseMessages(data)
+	case generate > 0:
+		return traffic.Generate(opts)
+	default:
+		m, err := fromFlags()
+		if err != nil {
+			return nil, err
+		}
+		return []traffic.Message{m}, nil
+	}
+}
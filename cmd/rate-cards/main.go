@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"phonecall-cost-processor-service/internal/infrastructure/config"
+	"phonecall-cost-processor-service/internal/infrastructure/postgres"
+	"phonecall-cost-processor-service/internal/infrastructure/rating"
+)
+
+func main() {
+	flag.Usage = func() {
+		log.Println("uso: rate-cards tarifario.json [otro.json ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		log.Fatal("❌ Falta al menos un tarifario")
+	}
+
+	cfg := config.Load()
+	db, err := postgres.NewPostgresConnection(cfg.DBUrl)
+	if err != nil {
+		log.Fatalf("❌ Error conectando a PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	repo := postgres.NewPostgresRateCardRepository(db)
+	for _, path := range flag.Args() {
+		card, err := rating.ReadFile(path)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if err := repo.SaveRateCard(card); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		log.Printf("✅ Tarifario %s cargado (vigente desde %s)", card.Version, card.EffectiveFrom.Format("2006-01-02 15:04 MST"))
+	}
+}
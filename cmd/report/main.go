@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"phonecall-cost-processor-service/internal/application"
+	"phonecall-cost-processor-service/internal/domain/model/services"
+	"phonecall-cost-processor-service/internal/infrastructure/config"
+	"phonecall-cost-processor-service/internal/infrastructure/postgres"
+)
+
+func main() {
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	from := flag.String("from", monthStart.Format("2006-01-02"), "fecha inicial inclusive (2006-01-02)")
+	to := flag.String("to", monthStart.AddDate(0, 1, 0).Format("2006-01-02"), "fecha final exclusiva (2006-01-02)")
+	currency := flag.String("currency", "", "moneda del reporte (por defecto BASE_CURRENCY)")
+	flag.Parse()
+
+	fromDate, err := time.Parse("2006-01-02", *from)
+	if err != nil {
+		log.Fatalf("❌ -from inválido: %v", err)
+	}
+	toDate, err := time.Parse("2006-01-02", *to)
+	if err != nil {
+		log.Fatalf("❌ -to inválido: %v", err)
+	}
+
+	cfg := config.Load()
+	if *currency == "" {
+		*currency = cfg.BaseCurrency
+	}
+	db, err := postgres.NewPostgresConnection(cfg.DBUrl)
+	if err != nil {
+		log.Fatalf("❌ Error conectando a PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	fx := services.NewFXConverter(postgres.NewPostgresFXRateRepository(db), cfg.BaseCurrency)
+	report := application.NewCallerTotalsReportUseCase(postgres.NewPostgresCallRepository(db), fx)
+
+	totals, err := report.Execute(fromDate, toDate, *currency)
+	if err != nil {
+		log.Fatalf("❌ Error generando reporte: %v", err)
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write([]string{"caller", "calls", "total", "currency", "provider_total", "adjusted_calls"})
+	for _, t := range totals {
+		_ = w.Write([]string{t.Caller, strconv.Itoa(t.Calls), t.Total.String(), t.Total.Currency,
+			t.ProviderTotal.String(), strconv.Itoa(t.Adjusted)})
+	}
+	w.Flush()
+}
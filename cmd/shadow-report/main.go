@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"phonecall-cost-processor-service/internal/application"
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/model/services"
+	"phonecall-cost-processor-service/internal/infrastructure/config"
+	"phonecall-cost-processor-service/internal/infrastructure/postgres"
+)
+
+func main() {
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	from := flag.String("from", monthStart.Format("2006-01-02"), "fecha inicial inclusive (2006-01-02)")
+	to := flag.String("to", monthStart.AddDate(0, 1, 0).Format("2006-01-02"), "fecha final exclusiva (2006-01-02)")
+	absolute := flag.String("tolerance", "0.01", "diferencia absoluta tolerada, en unidades de la moneda")
+	percent := flag.String("tolerance-pct", "0", "diferencia tolerada en % del costo facturado")
+	prefixDigits := flag.Int("prefix-digits", 4, "dígitos del receiver usados como prefijo")
+	group := flag.String("group", "call", "call | prefix | currency")
+	flag.Parse()
+
+	fromDate, err := time.Parse("2006-01-02", *from)
+	if err != nil {
+		log.Fatalf("❌ -from inválido: %v", err)
+	}
+	toDate, err := time.Parse("2006-01-02", *to)
+	if err != nil {
+		log.Fatalf("❌ -to inválido: %v", err)
+	}
+	tolerance, err := model.ParseTolerance(*absolute, *percent)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	cfg := config.Load()
+	db, err := postgres.NewPostgresConnection(cfg.DBUrl)
+	if err != nil {
+		log.Fatalf("❌ Error conectando a PostgreSQL: %v", err)
+	}
+	defer db.Close()
+
+	fx := services.NewFXConverter(postgres.NewPostgresFXRateRepository(db), cfg.BaseCurrency)
+	uc := application.NewShadowDiscrepancyReportUseCase(postgres.NewPostgresCallRepository(db), fx)
+
+	report, err := uc.Execute(fromDate, toDate, tolerance, *prefixDigits)
+	if err != nil {
+		log.Fatalf("❌ Error generando reporte: %v", err)
+	}
+	log.Printf("ℹ️ Comparadas=%d con diferencia=%d errores en sombra=%d salteadas=%d",
+		report.Compared, len(report.Calls), report.ShadowErrors, report.Skipped)
+
+	w := csv.NewWriter(os.Stdout)
+	switch *group {
+	case "call":
+		_ = w.Write([]string{"call_id", "receiver", "prefix", "start_timestamp", "cost", "shadow_cost", "difference", "currency"})
+		for _, c := range report.Calls {
+			_ = w.Write([]string{c.CallID, c.Receiver, c.Prefix, c.StartTimestamp.Format(time.RFC3339),
+				c.Cost.String(), c.Shadow.String(), c.Difference.String(), c.Cost.Currency})
+		}
+	case "prefix":
+		writeSummaries(w, report.ByPrefix)
+	case "currency":
+		writeSummaries(w, report.ByCurrency)
+	default:
+		log.Fatalf("❌ -group inválido: %s", *group)
+	}
+	w.Flush()
+}
+
+func writeSummaries(w *csv.Writer, summaries []model.DiscrepancySummary) {
+	_ = w.Write([]string{"prefix", "currency", "compared", "discrepancies", "net_difference"})
+	for _, s := range summaries {
+		_ = w.Write([]string{s.Prefix, s.Currency, strconv.Itoa(s.Compared), strconv.Itoa(s.Discrepancies), s.Difference.String()})
+	}
+}
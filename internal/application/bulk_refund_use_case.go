@@ -0,0 +1,122 @@
+package application
+
+import (
+	"fmt"
+	"log"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type IBulkRefundUseCase interface {
+	Execute(bulk model.BulkRefund) (model.BulkRefundProgress, error)
+	// ExecuteBatch aplica solo el lote siguiente; el avance queda Done cuando
+	// no quedan llamadas.
+	ExecuteBatch(bulk model.BulkRefund) (model.BulkRefundProgress, error)
+}
+
+type BulkRefundOption func(*BulkRefundUseCase)
+
+// WithCallerNormalization normaliza los callers del filtro a E.164 con
+// phones, como guarda las llamadas services.WithPhoneNormalization.
+func WithCallerNormalization(phones *model.PhoneNormalizer) BulkRefundOption {
+	return func(uc *BulkRefundUseCase) { uc.phones = phones }
+}
+
+// BulkRefundUseCase aplica un refund masivo en lotes a través de
+// RefundCallUseCase. Al terminar cada lote guarda el avance, así un refund
+// masivo interrumpido retoma desde el último lote completo; reprocesar un
+// lote no duplica refunds porque cada uno lleva el RefundID del masivo.
+type BulkRefundUseCase struct {
+	repo      repository.BulkRefundRepository
+	refunds   IRefundCallUseCase
+	batchSize int
+	phones    *model.PhoneNormalizer
+}
+
+func NewBulkRefundUseCase(repo repository.BulkRefundRepository, refunds IRefundCallUseCase, batchSize int, opts ...BulkRefundOption) *BulkRefundUseCase {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	uc := &BulkRefundUseCase{repo: repo, refunds: refunds, batchSize: batchSize}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute aplica todos los lotes y devuelve el avance acumulado. Un refund
+// masivo ya terminado no se vuelve a aplicar.
+func (uc *BulkRefundUseCase) Execute(bulk model.BulkRefund) (model.BulkRefundProgress, error) {
+	bulk, p, err := uc.start(bulk)
+	for err == nil && !p.Done {
+		p, err = uc.batch(bulk, p)
+	}
+	return p, err
+}
+
+// ExecuteBatch es Execute de a un lote: lo usa el mensaje bulk_refund para
+// no bloquear al consumidor durante todo el refund masivo.
+func (uc *BulkRefundUseCase) ExecuteBatch(bulk model.BulkRefund) (model.BulkRefundProgress, error) {
+	bulk, p, err := uc.start(bulk)
+	if err != nil || p.Done {
+		return p, err
+	}
+	return uc.batch(bulk, p)
+}
+
+func (uc *BulkRefundUseCase) start(bulk model.BulkRefund) (model.BulkRefund, model.BulkRefundProgress, error) {
+	if err := bulk.Validate(); err != nil {
+		return bulk, model.BulkRefundProgress{}, err
+	}
+	if uc.phones != nil {
+		f, err := bulk.Filter.NormalizeCallers(uc.phones)
+		if err != nil {
+			return bulk, model.BulkRefundProgress{}, err
+		}
+		bulk.Filter = f
+	}
+	p, err := uc.repo.BulkRefundProgress(bulk.ID())
+	if err == nil && !p.Done && p.Cursor != "" {
+		log.Printf("⏩ Retomando refund masivo %s después de call_id=%s", p.BulkID, p.Cursor)
+	}
+	return bulk, p, err
+}
+
+func (uc *BulkRefundUseCase) batch(bulk model.BulkRefund, p model.BulkRefundProgress) (model.BulkRefundProgress, error) {
+	ids, err := uc.repo.RefundCandidates(bulk.Filter, p.Cursor, uc.batchSize)
+	if err != nil {
+		return p, err
+	}
+	if len(ids) == 0 {
+		p.Done = true
+		return p, uc.repo.SaveBulkRefundProgress(p)
+	}
+
+	next := p
+	for _, id := range ids {
+		applied, err := uc.refunds.Execute(bulk.RefundFor(id))
+		switch {
+		case err == nil && applied:
+			next.Refunded++
+		case err == nil:
+			// Ya tenía un refund total, o este masivo se lo aplicó en un lote
+			// que se cortó antes de guardar el avance
+			log.Printf("ℹ️ Refund masivo %s sin cambios para call_id=%s: ya tenía el refund", p.BulkID, id)
+		case model.IsPermanent(err):
+			// p. ej. un refund parcial que supera lo que queda por reembolsar
+			log.Printf("⚠️ Refund masivo %s rechazado para call_id=%s: %v", p.BulkID, id, err)
+			next.Rejected++
+		default:
+			// Sin guardar el avance: el lote se reprocesa al retomar
+			return p, fmt.Errorf("error aplicando refund masivo %s a call_id=%s: %w", p.BulkID, id, err)
+		}
+	}
+	next.Matched += len(ids)
+	next.Cursor = ids[len(ids)-1]
+	if err := uc.repo.SaveBulkRefundProgress(next); err != nil {
+		return p, err
+	}
+	log.Printf("📦 Progreso refund masivo %s: encontradas=%d reembolsadas=%d rechazadas=%d", next.BulkID, next.Matched, next.Refunded, next.Rejected)
+	return next, nil
+}
@@ -0,0 +1,186 @@
+package application
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockBulkRefundRepository struct {
+	CallIDs  []string
+	Progress map[string]model.BulkRefundProgress
+	Saves    int
+}
+
+func (m *MockBulkRefundRepository) RefundCandidates(_ model.BulkRefundFilter, afterCallID string, limit int) ([]string, error) {
+	var ids []string
+	for _, id := range m.CallIDs {
+		if id > afterCallID && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *MockBulkRefundRepository) BulkRefundProgress(bulkID string) (model.BulkRefundProgress, error) {
+	if p, ok := m.Progress[bulkID]; ok {
+		return p, nil
+	}
+	return model.BulkRefundProgress{BulkID: bulkID}, nil
+}
+
+func (m *MockBulkRefundRepository) SaveBulkRefundProgress(p model.BulkRefundProgress) error {
+	if m.Progress == nil {
+		m.Progress = make(map[string]model.BulkRefundProgress)
+	}
+	m.Progress[p.BulkID] = p
+	m.Saves++
+	return nil
+}
+
+type MockRefundCallUseCase struct {
+	Refunds []model.RefundCall
+	Errs    map[string]error
+	// Refunded son las llamadas que ya tenían el refund: Execute no cambia nada.
+	Refunded map[string]bool
+}
+
+func (m *MockRefundCallUseCase) Execute(call model.RefundCall) (bool, error) {
+	if err := m.Errs[call.CallID]; err != nil {
+		return false, err
+	}
+	if m.Refunded[call.CallID] {
+		return false, nil
+	}
+	m.Refunds = append(m.Refunds, call)
+	return true, nil
+}
+
+func outageRefund() model.BulkRefund {
+	return model.BulkRefund{
+		Filter: model.BulkRefundFilter{
+			CallerPrefix: "+54911",
+			From:         time.Date(2024, 8, 29, 10, 0, 0, 0, time.UTC),
+			To:           time.Date(2024, 8, 29, 12, 0, 0, 0, time.UTC),
+		},
+		Reason: "Corte de red",
+	}
+}
+
+func TestBulkRefundUseCase_Execute(t *testing.T) {
+	repo := &MockBulkRefundRepository{CallIDs: []string{"a", "b", "c", "d", "e"}}
+	refunds := &MockRefundCallUseCase{Errs: map[string]error{"c": &model.ValidationError{Entity: "refund_call"}}}
+	bulk := outageRefund()
+
+	p, err := NewBulkRefundUseCase(repo, refunds, 2).Execute(bulk)
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.BulkRefundProgress{BulkID: bulk.ID(), Cursor: "e", Matched: 5, Refunded: 4, Rejected: 1, Done: true}, p)
+	assert.Equal(t, 4, repo.Saves, "un guardado por lote más el final")
+	if assert.Len(t, refunds.Refunds, 4) {
+		assert.Equal(t, bulk.RefundFor("a"), refunds.Refunds[0])
+		assert.Equal(t, "bulk-"+bulk.ID(), refunds.Refunds[0].RefundID)
+	}
+
+	// Reenviar el mismo refund masivo no vuelve a aplicarlo
+	refunds.Refunds = nil
+	again, err := NewBulkRefundUseCase(repo, refunds, 2).Execute(bulk)
+	assert.NoError(t, err)
+	assert.Equal(t, p, again)
+	assert.Empty(t, refunds.Refunds)
+}
+
+func TestBulkRefundUseCase_Execute_ResumesAfterError(t *testing.T) {
+	repo := &MockBulkRefundRepository{CallIDs: []string{"a", "b", "c", "d", "e"}}
+	refunds := &MockRefundCallUseCase{Errs: map[string]error{"d": errors.New("db down")}}
+	bulk := outageRefund()
+	useCase := NewBulkRefundUseCase(repo, refunds, 2)
+
+	p, err := useCase.Execute(bulk)
+	assert.Error(t, err)
+	assert.Equal(t, model.BulkRefundProgress{BulkID: bulk.ID(), Cursor: "b", Matched: 2, Refunded: 2}, p)
+
+	// Al retomar se reprocesa el lote interrumpido (c, d) sin repetir el anterior
+	refunds.Errs = nil
+	refunds.Refunds = nil
+	p, err = useCase.Execute(bulk)
+	assert.NoError(t, err)
+	assert.Equal(t, model.BulkRefundProgress{BulkID: bulk.ID(), Cursor: "e", Matched: 5, Refunded: 5, Done: true}, p)
+	assert.Len(t, refunds.Refunds, 3)
+}
+
+func TestBulkRefundUseCase_Execute_Invalid(t *testing.T) {
+	repo := &MockBulkRefundRepository{CallIDs: []string{"a"}}
+	bulk := outageRefund()
+	bulk.Filter.CallerPrefix = ""
+
+	_, err := NewBulkRefundUseCase(repo, &MockRefundCallUseCase{}, 2).Execute(bulk)
+
+	assert.True(t, model.IsPermanent(err))
+	assert.Zero(t, repo.Saves)
+}
+
+func TestBulkRefundUseCase_Execute_DoesNotCountNoOps(t *testing.T) {
+	repo := &MockBulkRefundRepository{CallIDs: []string{"a", "b", "c"}}
+	refunds := &MockRefundCallUseCase{Refunded: map[string]bool{"b": true}}
+
+	p, err := NewBulkRefundUseCase(repo, refunds, 2).Execute(outageRefund())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, p.Matched)
+	assert.Equal(t, 2, p.Refunded, "b ya tenía el refund")
+	assert.Zero(t, p.Rejected)
+}
+
+func TestBulkRefundUseCase_ExecuteBatch(t *testing.T) {
+	repo := &MockBulkRefundRepository{CallIDs: []string{"a", "b", "c"}}
+	refunds := &MockRefundCallUseCase{}
+	bulk := outageRefund()
+	useCase := NewBulkRefundUseCase(repo, refunds, 2)
+
+	p, err := useCase.ExecuteBatch(bulk)
+	assert.NoError(t, err)
+	assert.Equal(t, model.BulkRefundProgress{BulkID: bulk.ID(), Cursor: "b", Matched: 2, Refunded: 2}, p)
+
+	p, err = useCase.ExecuteBatch(bulk)
+	assert.NoError(t, err)
+	assert.Equal(t, model.BulkRefundProgress{BulkID: bulk.ID(), Cursor: "c", Matched: 3, Refunded: 3}, p)
+
+	p, err = useCase.ExecuteBatch(bulk)
+	assert.NoError(t, err)
+	assert.True(t, p.Done)
+	assert.Len(t, refunds.Refunds, 3)
+}
+
+type filterRecordingRepository struct {
+	MockBulkRefundRepository
+	filter model.BulkRefundFilter
+}
+
+func (m *filterRecordingRepository) RefundCandidates(f model.BulkRefundFilter, afterCallID string, limit int) ([]string, error) {
+	m.filter = f
+	return m.MockBulkRefundRepository.RefundCandidates(f, afterCallID, limit)
+}
+
+func TestBulkRefundUseCase_Execute_NormalizesCallers(t *testing.T) {
+	phones, err := model.NewPhoneNormalizer("AR")
+	assert.NoError(t, err)
+	repo := &filterRecordingRepository{}
+	bulk := outageRefund()
+	bulk.Filter.CallerPrefix = ""
+	bulk.Filter.Callers = []string{"011 1234-5678"}
+
+	_, err = NewBulkRefundUseCase(repo, &MockRefundCallUseCase{}, 2, WithCallerNormalization(phones)).Execute(bulk)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"+541112345678"}, repo.filter.Callers)
+
+	bulk.Filter.Callers = nil
+	bulk.Filter.CallerPrefix = "011"
+	_, err = NewBulkRefundUseCase(repo, &MockRefundCallUseCase{}, 2, WithCallerNormalization(phones)).Execute(bulk)
+	assert.True(t, model.IsPermanent(err), "caller_prefix debe venir en E.164")
+}
@@ -0,0 +1,28 @@
+package application
+
+import (
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type ICallCostAdjustedUseCase interface {
+	// Execute devuelve false si el mismo ajuste ya estaba guardado.
+	Execute(adj model.CostAdjustment) (bool, error)
+}
+
+// CallCostAdjustedUseCase fija a mano el costo facturado de una llamada. Lo
+// usan el mensaje call_cost_adjusted y el endpoint de administración.
+type CallCostAdjustedUseCase struct {
+	adjustments repository.CostAdjustmentWriter
+}
+
+func NewCallCostAdjustedUseCase(adjustments repository.CostAdjustmentWriter) *CallCostAdjustedUseCase {
+	return &CallCostAdjustedUseCase{adjustments: adjustments}
+}
+
+func (uc *CallCostAdjustedUseCase) Execute(adj model.CostAdjustment) (bool, error) {
+	if err := adj.Validate(); err != nil {
+		return false, err
+	}
+	return uc.adjustments.AdjustCost(adj)
+}
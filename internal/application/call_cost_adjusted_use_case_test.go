@@ -0,0 +1,45 @@
+package application
+
+import (
+	"testing"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockCostAdjustmentWriter struct {
+	saved []model.CostAdjustment
+}
+
+func (m *mockCostAdjustmentWriter) AdjustCost(adj model.CostAdjustment) (bool, error) {
+	m.saved = append(m.saved, adj)
+	return true, nil
+}
+
+func TestCallCostAdjustedUseCase_Execute(t *testing.T) {
+	writer := &mockCostAdjustmentWriter{}
+	uc := NewCallCostAdjustedUseCase(writer)
+	adj := model.CostAdjustment{
+		CallID: "550e8400-e29b-41d4-a716-446655440000",
+		Cost:   model.MustParseMoney("7.00", "ARS"),
+		Reason: "descuento contractual",
+		Actor:  "finanzas@telco",
+	}
+
+	saved, err := uc.Execute(adj)
+
+	assert.NoError(t, err)
+	assert.True(t, saved)
+	assert.Equal(t, []model.CostAdjustment{adj}, writer.saved)
+}
+
+func TestCallCostAdjustedUseCase_Execute_Invalid(t *testing.T) {
+	writer := &mockCostAdjustmentWriter{}
+	uc := NewCallCostAdjustedUseCase(writer)
+
+	_, err := uc.Execute(model.CostAdjustment{CallID: "550e8400-e29b-41d4-a716-446655440000", Cost: model.MustParseMoney("7.00", "ARS")})
+
+	assert.True(t, model.IsPermanent(err))
+	assert.Empty(t, writer.saved)
+}
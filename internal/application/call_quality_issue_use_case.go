@@ -0,0 +1,32 @@
+package application
+
+import (
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type ICallQualityIssueUseCase interface {
+	// Execute devuelve el crédito registrado, o nil si la llamada ya tenía
+	// uno igual o mayor.
+	Execute(issue model.CallQualityIssue) (*model.QualityCredit, error)
+}
+
+// CallQualityIssueUseCase acredita un porcentaje del costo de la llamada
+// según la severidad del problema.
+type CallQualityIssueUseCase struct {
+	credits repository.QualityCreditWriter
+	policy  model.CreditPolicy
+}
+
+func NewCallQualityIssueUseCase(credits repository.QualityCreditWriter, policy model.CreditPolicy) *CallQualityIssueUseCase {
+	return &CallQualityIssueUseCase{credits: credits, policy: policy}
+}
+
+func (uc *CallQualityIssueUseCase) Execute(issue model.CallQualityIssue) (*model.QualityCredit, error) {
+	credit := uc.policy.Credit(issue)
+	saved, err := uc.credits.SaveQualityCredit(credit)
+	if err != nil || !saved {
+		return nil, err
+	}
+	return &credit, nil
+}
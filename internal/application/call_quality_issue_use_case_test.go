@@ -0,0 +1,56 @@
+package application
+
+import (
+	"errors"
+	"testing"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockQualityCreditWriter struct {
+	saved   []model.QualityCredit
+	exists  bool
+	failure error
+}
+
+func (m *mockQualityCreditWriter) SaveQualityCredit(credit model.QualityCredit) (bool, error) {
+	if m.failure != nil {
+		return false, m.failure
+	}
+	if m.exists {
+		return false, nil
+	}
+	m.saved = append(m.saved, credit)
+	return true, nil
+}
+
+func TestCallQualityIssueUseCase_AppliesPolicyPercent(t *testing.T) {
+	credits := &mockQualityCreditWriter{}
+	uc := NewCallQualityIssueUseCase(credits, model.CreditPolicy{model.SeverityHigh: 30})
+
+	credit, err := uc.Execute(model.CallQualityIssue{CallID: "abc", Severity: model.SeverityHigh, IssueType: "one_way_audio"})
+
+	assert.NoError(t, err)
+	want := model.QualityCredit{CallID: "abc", Severity: model.SeverityHigh, IssueType: "one_way_audio", Percent: 30}
+	assert.Equal(t, &want, credit)
+	assert.Equal(t, []model.QualityCredit{want}, credits.saved)
+}
+
+func TestCallQualityIssueUseCase_ExistingCreditIsKept(t *testing.T) {
+	uc := NewCallQualityIssueUseCase(&mockQualityCreditWriter{exists: true}, model.DefaultCreditPolicy)
+
+	credit, err := uc.Execute(model.CallQualityIssue{CallID: "abc", Severity: model.SeverityLow, IssueType: "jitter"})
+
+	assert.NoError(t, err)
+	assert.Nil(t, credit)
+}
+
+func TestCallQualityIssueUseCase_Error(t *testing.T) {
+	uc := NewCallQualityIssueUseCase(&mockQualityCreditWriter{failure: errors.New("db down")}, model.DefaultCreditPolicy)
+
+	_, err := uc.Execute(model.CallQualityIssue{CallID: "abc", Severity: model.SeverityHigh, IssueType: "dropped"})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package application
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/model/services"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type ICallerTotalsReportUseCase interface {
+	Execute(from, to time.Time, currency string) ([]model.CallerTotal, error)
+}
+
+// CallerTotalsReportUseCase totaliza lo facturado por caller en la moneda
+// pedida, convirtiendo cada llamada con la cotización vigente en su
+// start_timestamp. Factura el costo ajustado a mano si lo hay, descontando
+// refunds parciales y créditos por calidad, y totaliza aparte lo que cobró
+// el proveedor.
+type CallerTotalsReportUseCase struct {
+	calls repository.BillingReader
+	fx    *services.FXConverter
+}
+
+func NewCallerTotalsReportUseCase(calls repository.BillingReader, fx *services.FXConverter) *CallerTotalsReportUseCase {
+	return &CallerTotalsReportUseCase{calls: calls, fx: fx}
+}
+
+func (uc *CallerTotalsReportUseCase) Execute(from, to time.Time, currency string) ([]model.CallerTotal, error) {
+	calls, err := uc.calls.BilledCalls(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := map[string]*model.CallerTotal{}
+	for _, call := range calls {
+		provider, err := uc.convert(call, currency)
+		if err != nil {
+			return nil, fmt.Errorf("call_id=%s: %w", call.CallID, err)
+		}
+		amount := provider
+		if call.Adjustment != nil {
+			if amount, err = uc.convertAdjusted(call, currency); err != nil {
+				return nil, fmt.Errorf("call_id=%s: %w", call.CallID, err)
+			}
+		}
+		// Refunds y créditos se descuentan en la misma proporción que sobre
+		// el costo facturado.
+		net, err := call.NetCost()
+		if err != nil {
+			return nil, fmt.Errorf("call_id=%s: %w", call.CallID, err)
+		}
+		if billed := call.BilledCost(); net.Amount != billed.Amount {
+			amount = amount.Prorate(net.Amount, billed.Amount)
+		}
+
+		t, ok := totals[call.Caller]
+		if !ok {
+			t = &model.CallerTotal{Caller: call.Caller, Total: model.NewMoney(0, currency), ProviderTotal: model.NewMoney(0, currency)}
+			totals[call.Caller] = t
+		}
+		t.Calls++
+		if call.Adjustment != nil {
+			t.Adjusted++
+		}
+		t.Total.Amount += amount.Amount
+		t.ProviderTotal.Amount += provider.Amount
+	}
+
+	result := make([]model.CallerTotal, 0, len(totals))
+	for _, t := range totals {
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Caller < result[j].Caller })
+	return result, nil
+}
+
+// Se prefiere el costo normalizado al momento de facturar; si no existe se
+// convierte el costo original.
+func (uc *CallerTotalsReportUseCase) convert(call model.BilledCall, currency string) (model.Money, error) {
+	if call.Cost.Currency == currency || call.Cost.Amount == 0 {
+		return model.NewMoney(call.Cost.Amount, currency), nil
+	}
+	if call.BaseCost != nil {
+		return uc.fx.Convert(*call.BaseCost, currency, call.StartTimestamp)
+	}
+	return uc.fx.Convert(call.Cost, currency, call.StartTimestamp)
+}
+
+// El costo normalizado corresponde al precio del proveedor: el ajustado se
+// convierte siempre con la cotización de la llamada.
+func (uc *CallerTotalsReportUseCase) convertAdjusted(call model.BilledCall, currency string) (model.Money, error) {
+	cost := call.Adjustment.Cost
+	if cost.Currency == currency || cost.Amount == 0 {
+		return model.NewMoney(cost.Amount, currency), nil
+	}
+	return uc.fx.Convert(cost, currency, call.StartTimestamp)
+}
@@ -0,0 +1,136 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/model/services"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBillingReader struct {
+	calls []model.BilledCall
+}
+
+func (m *mockBillingReader) BilledCalls(from, to time.Time) ([]model.BilledCall, error) {
+	return m.calls, nil
+}
+
+type mockFXRates struct {
+	rates []model.FXRate
+}
+
+func (m *mockFXRates) GetRate(from, to string, on time.Time) (model.FXRate, error) {
+	var found *model.FXRate
+	for i, r := range m.rates {
+		if r.From == from && r.To == to && !r.Date.After(on) {
+			found = &m.rates[i]
+		}
+	}
+	if found == nil {
+		return model.FXRate{}, repository.ErrRateNotFound
+	}
+	return *found, nil
+}
+
+func mustRate(t *testing.T, date time.Time, from, to, rate string) model.FXRate {
+	r, err := model.ParseFXRate(date, from, to, rate)
+	assert.NoError(t, err)
+	return r
+}
+
+func TestCallerTotalsReport_AggregatesInChosenCurrency(t *testing.T) {
+	day1 := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	baseUSD := model.MustParseMoney("2.00", "USD")
+
+	calls := &mockBillingReader{calls: []model.BilledCall{
+		{CallID: "1", Caller: "+1", StartTimestamp: day1, Cost: model.MustParseMoney("1800", "ARS"), BaseCost: &baseUSD},
+		{CallID: "2", Caller: "+1", StartTimestamp: day2, Cost: model.MustParseMoney("3.00", "EUR")},
+		{CallID: "3", Caller: "+2", StartTimestamp: day2, Cost: model.MustParseMoney("1000", "ARS")},
+		{CallID: "4", Caller: "+2", StartTimestamp: day2, Status: "REFUNDED", Cost: model.NewMoney(0, "ARS")},
+	}}
+	rates := &mockFXRates{rates: []model.FXRate{
+		mustRate(t, day1, "USD", "ARS", "900"),
+		mustRate(t, day2, "USD", "ARS", "1000"),
+		mustRate(t, day1, "EUR", "USD", "1.10"),
+	}}
+	uc := NewCallerTotalsReportUseCase(calls, services.NewFXConverter(rates, "USD"))
+
+	totals, err := uc.Execute(day1, day2.AddDate(0, 0, 1), "ARS")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CallerTotal{
+		// 2 USD * 900 + 3 EUR * 1.10 * 1000 (EUR->USD->ARS)
+		{Caller: "+1", Calls: 2, Total: model.MustParseMoney("5100", "ARS"), ProviderTotal: model.MustParseMoney("5100", "ARS")},
+		{Caller: "+2", Calls: 2, Total: model.MustParseMoney("1000", "ARS"), ProviderTotal: model.MustParseMoney("1000", "ARS")},
+	}, totals)
+}
+
+func TestCallerTotalsReport_MissingRate(t *testing.T) {
+	calls := &mockBillingReader{calls: []model.BilledCall{
+		{CallID: "1", Caller: "+1", StartTimestamp: time.Now(), Cost: model.MustParseMoney("10", "EUR")},
+	}}
+	uc := NewCallerTotalsReportUseCase(calls, services.NewFXConverter(&mockFXRates{}, "USD"))
+
+	_, err := uc.Execute(time.Time{}, time.Now().Add(time.Hour), "ARS")
+
+	assert.ErrorIs(t, err, repository.ErrRateNotFound)
+}
+
+func TestCallerTotalsReport_RefundInOtherCurrency(t *testing.T) {
+	usd := model.MustParseMoney("1", "USD")
+	calls := &mockBillingReader{calls: []model.BilledCall{
+		{CallID: "1", Caller: "+1", StartTimestamp: time.Now(), Status: "OK", Cost: model.MustParseMoney("10", "ARS"),
+			Refunds: []model.RefundCall{{CallID: "1", RefundID: "r1", Amount: &usd}}},
+	}}
+	uc := NewCallerTotalsReportUseCase(calls, services.NewFXConverter(&mockFXRates{}, "USD"))
+
+	_, err := uc.Execute(time.Time{}, time.Now().Add(time.Hour), "ARS")
+
+	assert.ErrorContains(t, err, "call_id=1: refund r1")
+}
+
+func TestCallerTotalsReport_DiscountsQualityCredits(t *testing.T) {
+	day := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+	credit := &model.QualityCredit{CallID: "1", Severity: model.SeverityHigh, IssueType: "dropped", Percent: 25}
+
+	calls := &mockBillingReader{calls: []model.BilledCall{
+		{CallID: "1", Caller: "+1", StartTimestamp: day, Cost: model.MustParseMoney("10.00", "USD"), Credit: credit},
+		{CallID: "2", Caller: "+1", StartTimestamp: day, Cost: model.MustParseMoney("5.00", "USD")},
+	}}
+	uc := NewCallerTotalsReportUseCase(calls, services.NewFXConverter(&mockFXRates{}, "USD"))
+
+	totals, err := uc.Execute(day, day.Add(time.Hour), "USD")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CallerTotal{
+		{Caller: "+1", Calls: 2, Total: model.MustParseMoney("12.50", "USD"), ProviderTotal: model.MustParseMoney("15.00", "USD")},
+	}, totals)
+}
+
+func TestCallerTotalsReport_BillsAdjustedCost(t *testing.T) {
+	day := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+	half := 50
+	baseUSD := model.MustParseMoney("10.00", "USD")
+
+	calls := &mockBillingReader{calls: []model.BilledCall{
+		// 10 USD ajustado a 8000 ARS (8 USD), con un refund del 50%
+		{CallID: "1", Caller: "+1", StartTimestamp: day, Cost: model.MustParseMoney("10.00", "USD"), BaseCost: &baseUSD,
+			Adjustment: &model.CostAdjustment{CallID: "1", Cost: model.MustParseMoney("8000", "ARS"), Reason: "descuento", Actor: "finanzas"},
+			Refunds:    []model.RefundCall{{CallID: "1", Percent: &half}}},
+		{CallID: "2", Caller: "+1", StartTimestamp: day, Cost: model.MustParseMoney("5.00", "USD")},
+	}}
+	rates := &mockFXRates{rates: []model.FXRate{mustRate(t, day, "USD", "ARS", "1000")}}
+	uc := NewCallerTotalsReportUseCase(calls, services.NewFXConverter(rates, "USD"))
+
+	totals, err := uc.Execute(day, day.Add(time.Hour), "USD")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CallerTotal{
+		{Caller: "+1", Calls: 2, Adjusted: 1, Total: model.MustParseMoney("9.00", "USD"), ProviderTotal: model.MustParseMoney("15.00", "USD")},
+	}, totals)
+}
@@ -0,0 +1,59 @@
+package application
+
+import (
+	"expvar"
+	"log"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type IExpirePendingEventsUseCase interface {
+	Execute() ([]model.PendingEvent, error)
+}
+
+// ExpirePendingEventsUseCase descarta los eventos estacionados hace más de
+// ttl: su new_incoming_call no llegó y ya no se espera.
+type ExpirePendingEventsUseCase struct {
+	repo repository.PendingEventRepository
+	ttl  time.Duration
+	now  func() time.Time
+	// expired cuenta los eventos descartados para alertar desde /debug/vars
+	expired *expvar.Int
+}
+
+type ExpirePendingEventsOption func(*ExpirePendingEventsUseCase)
+
+// WithExpiredEventsCounter suma a counter cada evento descartado.
+func WithExpiredEventsCounter(counter *expvar.Int) ExpirePendingEventsOption {
+	return func(uc *ExpirePendingEventsUseCase) {
+		uc.expired = counter
+	}
+}
+
+func NewExpirePendingEventsUseCase(repo repository.PendingEventRepository, ttl time.Duration, opts ...ExpirePendingEventsOption) *ExpirePendingEventsUseCase {
+	uc := &ExpirePendingEventsUseCase{repo: repo, ttl: ttl, now: time.Now}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute descarta y alerta por cada evento descartado.
+func (uc *ExpirePendingEventsUseCase) Execute() ([]model.PendingEvent, error) {
+	expired, err := uc.repo.ExpirePendingEvents(uc.now().Add(-uc.ttl))
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range expired {
+		log.Printf("🚨 Evento %s estacionado descartado call_id=%s: sin new_incoming_call desde %s", e.Type, e.CallID, e.ReceivedAt.Format(time.RFC3339))
+	}
+	if uc.expired != nil {
+		uc.expired.Add(int64(len(expired)))
+	}
+	if len(expired) > 0 {
+		log.Printf("🚨 %d eventos estacionados descartados (más viejos que %s)", len(expired), uc.ttl)
+	}
+	return expired, nil
+}
@@ -0,0 +1,75 @@
+package application
+
+import (
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockPendingEventRepository struct {
+	Events         []model.PendingEvent
+	ReceivedBefore time.Time
+	Err            error
+}
+
+func (m *MockPendingEventRepository) ParkEvent(e model.PendingEvent) (bool, error) {
+	m.Events = append(m.Events, e)
+	return true, m.Err
+}
+
+func (m *MockPendingEventRepository) PendingEvents(callID string) ([]model.PendingEvent, error) {
+	return m.Events, m.Err
+}
+
+func (m *MockPendingEventRepository) DeletePendingEvent(callID string, seq int64) error {
+	return m.Err
+}
+
+func (m *MockPendingEventRepository) ExpirePendingEvents(receivedBefore time.Time) ([]model.PendingEvent, error) {
+	m.ReceivedBefore = receivedBefore
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	var expired, rest []model.PendingEvent
+	for _, e := range m.Events {
+		if e.ReceivedAt.Before(receivedBefore) {
+			expired = append(expired, e)
+		} else {
+			rest = append(rest, e)
+		}
+	}
+	m.Events = rest
+	return expired, nil
+}
+
+func TestExpirePendingEventsUseCase_Execute(t *testing.T) {
+	now := time.Date(2024, 9, 10, 12, 0, 0, 0, time.UTC)
+	old := model.PendingEvent{CallID: "a", Type: "call_quality_issue", Seq: 1, ReceivedAt: now.Add(-96 * time.Hour)}
+	recent := model.PendingEvent{CallID: "b", Type: "refund_reversed", Seq: 2, ReceivedAt: now.Add(-time.Hour)}
+	repo := &MockPendingEventRepository{Events: []model.PendingEvent{old, recent}}
+	counter := new(expvar.Int)
+	useCase := NewExpirePendingEventsUseCase(repo, 72*time.Hour, WithExpiredEventsCounter(counter))
+	useCase.now = func() time.Time { return now }
+
+	expired, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-72*time.Hour), repo.ReceivedBefore)
+	assert.Equal(t, []model.PendingEvent{old}, expired)
+	assert.Equal(t, []model.PendingEvent{recent}, repo.Events)
+
+	_, err = useCase.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), counter.Value())
+}
+
+func TestExpirePendingEventsUseCase_Execute_Error(t *testing.T) {
+	_, err := NewExpirePendingEventsUseCase(&MockPendingEventRepository{Err: errors.New("db down")}, time.Hour).Execute()
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package application
+
+import (
+	"expvar"
+	"log"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type IExpirePlaceholdersUseCase interface {
+	Execute() ([]model.Placeholder, error)
+}
+
+// ExpirePlaceholdersUseCase expira los placeholders REFUND_PARTIALLY más
+// viejos que ttl: su new_incoming_call no llegó y ya no se espera.
+type ExpirePlaceholdersUseCase struct {
+	repo repository.PlaceholderRepository
+	ttl  time.Duration
+	now  func() time.Time
+	// expired cuenta los placeholders expirados para alertar desde /debug/vars
+	expired *expvar.Int
+}
+
+type ExpirePlaceholdersOption func(*ExpirePlaceholdersUseCase)
+
+// WithExpiredCounter suma a counter cada placeholder expirado.
+func WithExpiredCounter(counter *expvar.Int) ExpirePlaceholdersOption {
+	return func(uc *ExpirePlaceholdersUseCase) {
+		uc.expired = counter
+	}
+}
+
+func NewExpirePlaceholdersUseCase(repo repository.PlaceholderRepository, ttl time.Duration, opts ...ExpirePlaceholdersOption) *ExpirePlaceholdersUseCase {
+	uc := &ExpirePlaceholdersUseCase{repo: repo, ttl: ttl, now: time.Now}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Execute expira y alerta por cada placeholder expirado.
+func (uc *ExpirePlaceholdersUseCase) Execute() ([]model.Placeholder, error) {
+	expired, err := uc.repo.ExpirePlaceholders(uc.now().Add(-uc.ttl))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range expired {
+		log.Printf("🚨 Placeholder expirado call_id=%s: refund sin new_incoming_call desde %s", p.CallID, p.CreatedAt.Format(time.RFC3339))
+	}
+	if uc.expired != nil {
+		uc.expired.Add(int64(len(expired)))
+	}
+	if len(expired) > 0 {
+		log.Printf("🚨 %d placeholders REFUND_PARTIALLY expirados (más viejos que %s)", len(expired), uc.ttl)
+	}
+	return expired, nil
+}
@@ -0,0 +1,77 @@
+package application
+
+import (
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockPlaceholderRepository struct {
+	List          []model.Placeholder
+	CreatedBefore time.Time
+	Err           error
+}
+
+func (m *MockPlaceholderRepository) Placeholders() ([]model.Placeholder, error) {
+	return m.List, m.Err
+}
+
+func (m *MockPlaceholderRepository) ExpirePlaceholders(createdBefore time.Time) ([]model.Placeholder, error) {
+	m.CreatedBefore = createdBefore
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	var expired []model.Placeholder
+	for _, p := range m.List {
+		if p.CreatedAt.Before(createdBefore) {
+			expired = append(expired, p)
+		}
+	}
+	return expired, nil
+}
+
+func TestExpirePlaceholdersUseCase_Execute(t *testing.T) {
+	now := time.Date(2024, 9, 10, 12, 0, 0, 0, time.UTC)
+	repo := &MockPlaceholderRepository{List: []model.Placeholder{
+		{CallID: "a", CreatedAt: now.Add(-96 * time.Hour)},
+		{CallID: "b", CreatedAt: now.Add(-time.Hour)},
+	}}
+	useCase := NewExpirePlaceholdersUseCase(repo, 72*time.Hour)
+	useCase.now = func() time.Time { return now }
+
+	expired, err := useCase.Execute()
+
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-72*time.Hour), repo.CreatedBefore)
+	assert.Equal(t, repo.List[:1], expired)
+}
+
+func TestExpirePlaceholdersUseCase_Execute_CountsExpired(t *testing.T) {
+	now := time.Date(2024, 9, 10, 12, 0, 0, 0, time.UTC)
+	repo := &MockPlaceholderRepository{List: []model.Placeholder{
+		{CallID: "a", CreatedAt: now.Add(-96 * time.Hour)},
+		{CallID: "b", CreatedAt: now.Add(-80 * time.Hour)},
+		{CallID: "c", CreatedAt: now.Add(-time.Hour)},
+	}}
+	counter := new(expvar.Int)
+	useCase := NewExpirePlaceholdersUseCase(repo, 72*time.Hour, WithExpiredCounter(counter))
+	useCase.now = func() time.Time { return now }
+
+	_, err := useCase.Execute()
+	assert.NoError(t, err)
+	_, err = useCase.Execute()
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(4), counter.Value())
+}
+
+func TestExpirePlaceholdersUseCase_Execute_Error(t *testing.T) {
+	_, err := NewExpirePlaceholdersUseCase(&MockPlaceholderRepository{Err: errors.New("db down")}, time.Hour).Execute()
+
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package application
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"phonecall-cost-processor-service/internal/domain/model"
 )
@@ -31,7 +32,7 @@ func TestIncomingCallUseCase_Execute(t *testing.T) {
 		Caller:         "+123456",
 		Receiver:       "+654321",
 		DurationInSec:  60,
-		StartTimestamp: "2025-07-25T03:00:00Z",
+		StartTimestamp: time.Date(2025, 7, 25, 3, 0, 0, 0, time.UTC),
 	}
 
 	err := useCase.Execute(call)
@@ -0,0 +1,30 @@
+package application
+
+import (
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type IPlaceholderAgingReportUseCase interface {
+	Execute(now time.Time, bounds []time.Duration) ([]model.AgingBucket, error)
+}
+
+// PlaceholderAgingReportUseCase cuenta los placeholders REFUND_PARTIALLY
+// vigentes por antigüedad (ver model.PlaceholderAging).
+type PlaceholderAgingReportUseCase struct {
+	repo repository.PlaceholderRepository
+}
+
+func NewPlaceholderAgingReportUseCase(repo repository.PlaceholderRepository) *PlaceholderAgingReportUseCase {
+	return &PlaceholderAgingReportUseCase{repo: repo}
+}
+
+func (uc *PlaceholderAgingReportUseCase) Execute(now time.Time, bounds []time.Duration) ([]model.AgingBucket, error) {
+	placeholders, err := uc.repo.Placeholders()
+	if err != nil {
+		return nil, err
+	}
+	return model.PlaceholderAging(placeholders, now, bounds), nil
+}
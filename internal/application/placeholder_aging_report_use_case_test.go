@@ -0,0 +1,36 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaceholderAgingReport(t *testing.T) {
+	now := time.Date(2024, 9, 10, 12, 0, 0, 0, time.UTC)
+	oldest := now.Add(-40 * 24 * time.Hour)
+	repo := &MockPlaceholderRepository{List: []model.Placeholder{
+		{CallID: "a", CreatedAt: oldest},
+		{CallID: "b", CreatedAt: now.Add(-2 * time.Hour)},
+		{CallID: "c", CreatedAt: now.Add(-3 * time.Hour)},
+		{CallID: "d", CreatedAt: now.Add(-time.Minute)},
+	}}
+
+	buckets, err := NewPlaceholderAgingReportUseCase(repo).Execute(now, model.DefaultAgingBounds)
+
+	assert.NoError(t, err)
+	var labels []string
+	var counts []int
+	for _, b := range buckets {
+		labels = append(labels, b.Label())
+		counts = append(counts, b.Count)
+	}
+	assert.Equal(t, []string{"<1h", "1h-1d", "1d-7d", "7d-30d", ">=30d"}, labels)
+	assert.Equal(t, []int{1, 2, 0, 0, 1}, counts)
+	assert.Equal(t, now.Add(-3*time.Hour), *buckets[1].Oldest)
+	assert.Nil(t, buckets[2].Oldest)
+	assert.Equal(t, oldest, *buckets[4].Oldest)
+}
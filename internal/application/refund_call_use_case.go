@@ -6,17 +6,19 @@ import (
 )
 
 type IRefundCallUseCase interface {
-	Execute(call model.RefundCall) error
+	// Execute devuelve false si el refund no cambió nada (ver
+	// repository.RefundRepository).
+	Execute(call model.RefundCall) (bool, error)
 }
 
 type RefundCallUseCase struct {
-	repo repository.CallRepository
+	repo repository.RefundRepository
 }
 
-func NewRefundCallUseCase(repo repository.CallRepository) *RefundCallUseCase {
+func NewRefundCallUseCase(repo repository.RefundRepository) *RefundCallUseCase {
 	return &RefundCallUseCase{repo: repo}
 }
 
-func (uc *RefundCallUseCase) Execute(call model.RefundCall) error {
+func (uc *RefundCallUseCase) Execute(call model.RefundCall) (bool, error) {
 	return uc.repo.ApplyRefund(call)
 }
@@ -7,50 +7,23 @@ import (
 	"phonecall-cost-processor-service/internal/domain/model"
 )
 
-type MockCallRepository struct {
+type MockRefundRepository struct {
 	Called     bool
 	RefundData model.RefundCall
 	ShouldErr  bool
 }
 
-// FillMissingCallData implements repository.CallRepository.
-func (m *MockCallRepository) FillMissingCallData(model.NewIncomingCall) error {
-	panic("unimplemented")
-}
-
-// MarkCallAsInvalid implements repository.CallRepository.
-func (m *MockCallRepository) MarkCallAsInvalid(callID string) error {
-	panic("unimplemented")
-}
-
-// GetCallStatus implements repository.CallRepository.
-func (m *MockCallRepository) GetCallStatus(callID string) (string, error) {
-	panic("unimplemented")
-}
-
-func (m *MockCallRepository) MarkCostAsFailed(callID string) error {
-	panic("unimplemented")
-}
-
-func (m *MockCallRepository) SaveIncomingCall(model.NewIncomingCall) error {
-	return nil
-}
-
-func (m *MockCallRepository) UpdateCallCost(string, float64, string) error {
-	return nil
-}
-
-func (m *MockCallRepository) ApplyRefund(refund model.RefundCall) error {
+func (m *MockRefundRepository) ApplyRefund(refund model.RefundCall) (bool, error) {
 	m.Called = true
 	m.RefundData = refund
 	if m.ShouldErr {
-		return errors.New("mock error")
+		return false, errors.New("mock error")
 	}
-	return nil
+	return true, nil
 }
 
 func TestRefundCallUseCase_ApplyRefund(t *testing.T) {
-	mockRepo := &MockCallRepository{}
+	mockRepo := &MockRefundRepository{}
 	useCase := NewRefundCallUseCase(mockRepo)
 
 	refund := model.RefundCall{
@@ -58,9 +31,9 @@ func TestRefundCallUseCase_ApplyRefund(t *testing.T) {
 		Reason: "Test reason",
 	}
 
-	err := useCase.Execute(refund)
-	if err != nil {
-		t.Errorf("unexpected error: %v", err)
+	applied, err := useCase.Execute(refund)
+	if err != nil || !applied {
+		t.Errorf("expected the refund to be applied, got %v, %v", applied, err)
 	}
 
 	if !mockRepo.Called {
@@ -73,7 +46,7 @@ func TestRefundCallUseCase_ApplyRefund(t *testing.T) {
 }
 
 func TestRefundCallUseCase_ApplyRefund_Error(t *testing.T) {
-	mockRepo := &MockCallRepository{ShouldErr: true}
+	mockRepo := &MockRefundRepository{ShouldErr: true}
 	useCase := NewRefundCallUseCase(mockRepo)
 
 	refund := model.RefundCall{
@@ -81,7 +54,7 @@ func TestRefundCallUseCase_ApplyRefund_Error(t *testing.T) {
 		Reason: "Test reason",
 	}
 
-	err := useCase.Execute(refund)
+	_, err := useCase.Execute(refund)
 	if err == nil {
 		t.Error("expected error but got none")
 	}
@@ -0,0 +1,24 @@
+package application
+
+import (
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type IRefundReversedUseCase interface {
+	Execute(reversal model.RefundReversal) error
+}
+
+// RefundReversedUseCase anula un refund emitido por error: la llamada vuelve
+// al estado y costo que tenía antes del refund.
+type RefundReversedUseCase struct {
+	repo repository.RefundReversalRepository
+}
+
+func NewRefundReversedUseCase(repo repository.RefundReversalRepository) *RefundReversedUseCase {
+	return &RefundReversedUseCase{repo: repo}
+}
+
+func (uc *RefundReversedUseCase) Execute(reversal model.RefundReversal) error {
+	return uc.repo.ReverseRefund(reversal)
+}
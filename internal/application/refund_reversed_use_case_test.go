@@ -0,0 +1,44 @@
+package application
+
+import (
+	"errors"
+	"testing"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+)
+
+type MockRefundReversalRepository struct {
+	Called    bool
+	Reversal  model.RefundReversal
+	ShouldErr bool
+}
+
+func (m *MockRefundReversalRepository) ReverseRefund(reversal model.RefundReversal) error {
+	m.Called = true
+	m.Reversal = reversal
+	if m.ShouldErr {
+		return errors.New("mock error")
+	}
+	return nil
+}
+
+func TestRefundReversedUseCase_Execute(t *testing.T) {
+	mockRepo := &MockRefundReversalRepository{}
+	useCase := NewRefundReversedUseCase(mockRepo)
+
+	reversal := model.RefundReversal{CallID: "abc-123", RefundID: "r1", Reason: "refund por error"}
+	if err := useCase.Execute(reversal); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !mockRepo.Called || mockRepo.Reversal != reversal {
+		t.Errorf("expected ReverseRefund(%+v), got %+v", reversal, mockRepo.Reversal)
+	}
+}
+
+func TestRefundReversedUseCase_Execute_Error(t *testing.T) {
+	useCase := NewRefundReversedUseCase(&MockRefundReversalRepository{ShouldErr: true})
+
+	if err := useCase.Execute(model.RefundReversal{CallID: "abc-123"}); err == nil {
+		t.Error("expected error but got none")
+	}
+}
@@ -0,0 +1,114 @@
+package application
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/model/services"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type IShadowDiscrepancyReportUseCase interface {
+	Execute(from, to time.Time, tolerance model.Tolerance, prefixDigits int) (model.DiscrepancyReport, error)
+}
+
+// ShadowDiscrepancyReportUseCase compara el costo facturado con el del
+// proveedor en sombra. Si las monedas difieren, el costo en sombra se
+// convierte a la moneda facturada con la cotización del día de la llamada.
+type ShadowDiscrepancyReportUseCase struct {
+	shadows repository.ShadowCostReader
+	fx      *services.FXConverter
+}
+
+func NewShadowDiscrepancyReportUseCase(shadows repository.ShadowCostReader, fx *services.FXConverter) *ShadowDiscrepancyReportUseCase {
+	return &ShadowDiscrepancyReportUseCase{shadows: shadows, fx: fx}
+}
+
+func (uc *ShadowDiscrepancyReportUseCase) Execute(from, to time.Time, tolerance model.Tolerance, prefixDigits int) (model.DiscrepancyReport, error) {
+	comparisons, err := uc.shadows.ShadowComparisons(from, to)
+	if err != nil {
+		return model.DiscrepancyReport{}, err
+	}
+
+	var report model.DiscrepancyReport
+	byPrefix := map[[2]string]*model.DiscrepancySummary{}
+	byCurrency := map[string]*model.DiscrepancySummary{}
+
+	for _, c := range comparisons {
+		if c.Cost == nil {
+			report.Skipped++
+			continue
+		}
+		if c.Shadow.Cost == nil {
+			report.ShadowErrors++
+			continue
+		}
+
+		cost := *c.Cost
+		shadow, err := uc.inCurrency(*c.Shadow.Cost, cost.Currency, c.StartTimestamp)
+		if err != nil {
+			log.Printf("⚠️ No se pudo comparar call_id=%s: %v", c.CallID, err)
+			report.Skipped++
+			continue
+		}
+
+		report.Compared++
+		prefix := model.DialPrefix(c.Receiver, prefixDigits)
+		diff := model.NewMoney(shadow.Amount-cost.Amount, cost.Currency)
+		exceeded := tolerance.Exceeded(cost, diff)
+		if exceeded {
+			report.Calls = append(report.Calls, model.CostDiscrepancy{
+				CallID:         c.CallID,
+				Receiver:       c.Receiver,
+				Prefix:         prefix,
+				StartTimestamp: c.StartTimestamp,
+				Cost:           cost,
+				Shadow:         shadow,
+				Difference:     diff,
+			})
+		}
+
+		key := [2]string{prefix, cost.Currency}
+		if byPrefix[key] == nil {
+			byPrefix[key] = &model.DiscrepancySummary{Prefix: prefix, Currency: cost.Currency, Difference: model.NewMoney(0, cost.Currency)}
+		}
+		if byCurrency[cost.Currency] == nil {
+			byCurrency[cost.Currency] = &model.DiscrepancySummary{Currency: cost.Currency, Difference: model.NewMoney(0, cost.Currency)}
+		}
+		for _, s := range []*model.DiscrepancySummary{byPrefix[key], byCurrency[cost.Currency]} {
+			s.Compared++
+			s.Difference.Amount += diff.Amount
+			if exceeded {
+				s.Discrepancies++
+			}
+		}
+	}
+
+	for _, s := range byPrefix {
+		report.ByPrefix = append(report.ByPrefix, *s)
+	}
+	sort.Slice(report.ByPrefix, func(i, j int) bool {
+		if report.ByPrefix[i].Prefix != report.ByPrefix[j].Prefix {
+			return report.ByPrefix[i].Prefix < report.ByPrefix[j].Prefix
+		}
+		return report.ByPrefix[i].Currency < report.ByPrefix[j].Currency
+	})
+	for _, s := range byCurrency {
+		report.ByCurrency = append(report.ByCurrency, *s)
+	}
+	sort.Slice(report.ByCurrency, func(i, j int) bool { return report.ByCurrency[i].Currency < report.ByCurrency[j].Currency })
+	return report, nil
+}
+
+func (uc *ShadowDiscrepancyReportUseCase) inCurrency(m model.Money, currency string, on time.Time) (model.Money, error) {
+	if m.Currency == currency {
+		return m, nil
+	}
+	if uc.fx == nil {
+		return model.Money{}, fmt.Errorf("moneda %s distinta de la facturada %s", m.Currency, currency)
+	}
+	return uc.fx.Convert(m, currency, on)
+}
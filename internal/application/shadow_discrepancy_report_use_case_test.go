@@ -0,0 +1,67 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/model/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockShadowReader struct {
+	comparisons []model.ShadowComparison
+}
+
+func (m *mockShadowReader) ShadowComparisons(from, to time.Time) ([]model.ShadowComparison, error) {
+	return m.comparisons, nil
+}
+
+func money(amount, currency string) *model.Money {
+	m := model.MustParseMoney(amount, currency)
+	return &m
+}
+
+func TestShadowDiscrepancyReport(t *testing.T) {
+	day := time.Date(2024, 9, 2, 10, 0, 0, 0, time.UTC)
+	shadows := &mockShadowReader{comparisons: []model.ShadowComparison{
+		{CallID: "igual", Receiver: "+5491111111111", StartTimestamp: day, Cost: money("10", "ARS"), Shadow: model.ShadowCost{Cost: money("10", "ARS")}},
+		{CallID: "dentro", Receiver: "+5491122222222", StartTimestamp: day, Cost: money("100", "ARS"), Shadow: model.ShadowCost{Cost: money("100.50", "ARS")}},
+		{CallID: "fuera", Receiver: "+5435133333333", StartTimestamp: day, Cost: money("10", "ARS"), Shadow: model.ShadowCost{Cost: money("12", "ARS")}},
+		{CallID: "usd", Receiver: "+12025550100", StartTimestamp: day, Cost: money("1.00", "USD"), Shadow: model.ShadowCost{Cost: money("1000", "ARS")}},
+		{CallID: "sin-tarifa", Receiver: "+33123456789", StartTimestamp: day, Cost: money("1", "EUR"), Shadow: model.ShadowCost{Error: "sin tarifa"}},
+		{CallID: "pendiente", Receiver: "+5491144444444", StartTimestamp: day, Shadow: model.ShadowCost{Cost: money("1", "ARS")}},
+	}}
+	rates := &mockFXRates{rates: []model.FXRate{mustRate(t, day, "USD", "ARS", "800")}}
+	uc := NewShadowDiscrepancyReportUseCase(shadows, services.NewFXConverter(rates, "USD"))
+
+	tolerance, err := model.ParseTolerance("0.01", "1")
+	assert.NoError(t, err)
+	report, err := uc.Execute(day, day.Add(time.Hour), tolerance, 3)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, report.Compared)
+	assert.Equal(t, 1, report.ShadowErrors)
+	assert.Equal(t, 1, report.Skipped)
+
+	var ids []string
+	for _, c := range report.Calls {
+		ids = append(ids, c.CallID)
+	}
+	assert.Equal(t, []string{"fuera", "usd"}, ids)
+	assert.Equal(t, model.MustParseMoney("2", "ARS"), report.Calls[0].Difference)
+	assert.Equal(t, "+543", report.Calls[0].Prefix)
+	assert.Equal(t, model.MustParseMoney("1.25", "USD"), report.Calls[1].Shadow)
+	assert.Equal(t, model.MustParseMoney("0.25", "USD"), report.Calls[1].Difference)
+
+	assert.Equal(t, []model.DiscrepancySummary{
+		{Prefix: "+120", Currency: "USD", Compared: 1, Discrepancies: 1, Difference: model.MustParseMoney("0.25", "USD")},
+		{Prefix: "+543", Currency: "ARS", Compared: 1, Discrepancies: 1, Difference: model.MustParseMoney("2", "ARS")},
+		{Prefix: "+549", Currency: "ARS", Compared: 2, Discrepancies: 0, Difference: model.MustParseMoney("0.50", "ARS")},
+	}, report.ByPrefix)
+	assert.Equal(t, []model.DiscrepancySummary{
+		{Currency: "ARS", Compared: 3, Discrepancies: 1, Difference: model.MustParseMoney("2.50", "ARS")},
+		{Currency: "USD", Compared: 1, Discrepancies: 1, Difference: model.MustParseMoney("0.25", "USD")},
+	}, report.ByCurrency)
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// CostAdjustment reemplaza el precio del proveedor de una llamada por uno
+// fijado por finanzas (descuento contractual, corrección del proveedor). El
+// costo del proveedor se conserva; los reportes facturan el ajustado.
+type CostAdjustment struct {
+	CallID string
+	Cost   Money
+	Reason string
+	Actor  string
+	// At lo completa el repositorio al guardar el ajuste.
+	At time.Time
+}
+
+func (a CostAdjustment) Validate() error {
+	v := &ValidationError{Entity: "call_cost_adjusted"}
+	validateCallID(v, a.CallID)
+	if !IsISOCurrency(a.Cost.Currency) {
+		v.add("currency", "no es ISO-4217: %q", a.Cost.Currency)
+	}
+	if a.Cost.IsNegative() {
+		v.add("cost", "no puede ser negativo: %s", a.Cost.String())
+	} else if IsISOCurrency(a.Cost.Currency) {
+		if err := a.Cost.CheckStorable(); err != nil {
+			v.add("cost", "%v", err)
+		}
+	}
+	if strings.TrimSpace(a.Reason) == "" {
+		v.add("reason", "es obligatorio")
+	}
+	if strings.TrimSpace(a.Actor) == "" {
+		v.add("actor", "es obligatorio")
+	}
+	return v.orNil()
+}
+
+// CheckRefunds rechaza un ajuste en otra moneda que los refunds por monto
+// vigentes de la llamada: NetCost y CheckRefund los descontarían del costo
+// ajustado y, con otra moneda, quedarían afuera sin aviso. Hay que anularlos
+// o ajustar en su moneda.
+func (a CostAdjustment) CheckRefunds(refunds []RefundCall) error {
+	v := &ValidationError{Entity: "call_cost_adjusted"}
+	for _, r := range refunds {
+		if r.Counts() && r.Amount != nil && r.Amount.Currency != a.Cost.Currency {
+			v.add("currency", "la llamada tiene el refund %s en %s", r.ID(), r.Amount.Currency)
+			break
+		}
+	}
+	return v.orNil()
+}
+
+// Same indica si b fija el mismo costo, motivo y autor que a: reguardarlo
+// no cambia nada.
+func (a CostAdjustment) Same(b CostAdjustment) bool {
+	return a.CallID == b.CallID && a.Cost == b.Cost && a.Reason == b.Reason && a.Actor == b.Actor
+}
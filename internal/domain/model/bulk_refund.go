@@ -0,0 +1,172 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BulkRefund reembolsa todas las llamadas que cumplen Filter, p. ej. las de
+// los números afectados por un corte de red. Sin Percent cada refund es total.
+type BulkRefund struct {
+	// BulkID identifica el refund masivo para retomarlo; si no viene se
+	// deriva del contenido (ver ID).
+	BulkID  string
+	Filter  BulkRefundFilter
+	Reason  string
+	Percent *int
+}
+
+// BulkRefundFilter selecciona llamadas por caller (lista exacta o prefijo),
+// start_timestamp en [From, To) y, opcionalmente, estado.
+type BulkRefundFilter struct {
+	Callers      []string
+	CallerPrefix string
+	From         time.Time
+	To           time.Time
+	// Statuses vacío acepta cualquier estado.
+	Statuses []string
+}
+
+// BulkRefundProgress es el avance de un refund masivo. Cursor es el último
+// call_id procesado: las llamadas se recorren ordenadas por call_id.
+type BulkRefundProgress struct {
+	BulkID   string
+	Cursor   string
+	Matched  int
+	Refunded int
+	Rejected int
+	Done     bool
+}
+
+var callStatuses = []string{"PENDING", "OK", "ERROR", "INVALID", "REFUNDED", "REFUND_PARTIALLY", "EXPIRED"}
+
+// ID es BulkID o, si no viene, un hash del filtro, el motivo y el
+// porcentaje: reenviar el mismo refund masivo lo retoma en vez de empezarlo
+// de nuevo.
+func (b BulkRefund) ID() string {
+	if b.BulkID != "" {
+		return b.BulkID
+	}
+	f := b.Filter
+	callers := append([]string(nil), f.Callers...)
+	sort.Strings(callers)
+	statuses := append([]string(nil), f.Statuses...)
+	sort.Strings(statuses)
+	percent := ""
+	if b.Percent != nil {
+		percent = fmt.Sprint(*b.Percent)
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s|%s|%s|%s|%s|%s", strings.Join(callers, ","), f.CallerPrefix,
+		f.From.UTC().Format(time.RFC3339Nano), f.To.UTC().Format(time.RFC3339Nano),
+		strings.Join(statuses, ","), b.Reason, percent)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// RefundFor es el refund que el refund masivo aplica a callID. Su RefundID
+// deriva del refund masivo, así reprocesar un lote no lo aplica dos veces.
+func (b BulkRefund) RefundFor(callID string) RefundCall {
+	return RefundCall{CallID: callID, Reason: b.Reason, RefundID: "bulk-" + b.ID(), Percent: b.Percent}
+}
+
+func (b BulkRefund) Validate() error {
+	v := &ValidationError{Entity: "bulk_refund"}
+	f := b.Filter
+	switch {
+	case len(f.Callers) == 0 && f.CallerPrefix == "":
+		v.add("callers", "callers o caller_prefix es obligatorio")
+	case len(f.Callers) > 0 && f.CallerPrefix != "":
+		v.add("callers", "callers y caller_prefix son excluyentes")
+	}
+	for _, c := range f.Callers {
+		if strings.TrimSpace(c) == "" {
+			v.add("callers", "no puede tener números vacíos")
+			break
+		}
+	}
+	if f.From.IsZero() {
+		v.add("from", "es obligatorio")
+	}
+	if f.To.IsZero() {
+		v.add("to", "es obligatorio")
+	} else if !f.From.IsZero() && !f.From.Before(f.To) {
+		v.add("to", "debe ser posterior a from")
+	}
+	for _, s := range f.Statuses {
+		if !contains(callStatuses, s) {
+			v.add("statuses", "estado desconocido: %q", s)
+		}
+	}
+	if strings.TrimSpace(b.Reason) == "" {
+		v.add("reason", "es obligatorio")
+	}
+	if p := b.Percent; p != nil && (*p <= 0 || *p > 100) {
+		v.add("percent", "debe estar entre 1 y 100: %d", *p)
+	}
+	return v.orNil()
+}
+
+// NormalizeCallers lleva callers a E.164 con phones, como se guardan las
+// llamadas con la normalización activa: "011 1234-5678" no encontraría
+// "+541112345678". Un prefijo no se puede normalizar, así que caller_prefix
+// tiene que venir en E.164 ("+54911").
+func (f BulkRefundFilter) NormalizeCallers(phones *PhoneNormalizer) (BulkRefundFilter, error) {
+	v := &ValidationError{Entity: "bulk_refund"}
+	var callers []string
+	for _, c := range f.Callers {
+		n, err := phones.Normalize(c)
+		if err != nil {
+			v.add("callers", "%v", err)
+			continue
+		}
+		callers = append(callers, n.E164)
+	}
+	if p := f.CallerPrefix; p != "" && !isE164Prefix(p) {
+		v.add("caller_prefix", "debe estar en E.164 (p. ej. +54911): %q", p)
+	}
+	if err := v.orNil(); err != nil {
+		return f, err
+	}
+	f.Callers = callers
+	return f, nil
+}
+
+func isE164Prefix(p string) bool {
+	if len(p) < 2 || p[0] != '+' {
+		return false
+	}
+	for _, r := range p[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches indica si la llamada entra en el filtro. Es la misma condición que
+// aplican los repositorios al buscar candidatos.
+func (f BulkRefundFilter) Matches(caller string, start time.Time, status string) bool {
+	if start.Before(f.From) || !start.Before(f.To) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !contains(f.Statuses, status) {
+		return false
+	}
+	if f.CallerPrefix != "" {
+		return strings.HasPrefix(caller, f.CallerPrefix)
+	}
+	return contains(f.Callers, caller)
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
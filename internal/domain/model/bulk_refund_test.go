@@ -0,0 +1,75 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func outage() BulkRefund {
+	return BulkRefund{
+		Filter: BulkRefundFilter{
+			Callers: []string{"+5491100000002", "+5491100000001"},
+			From:    time.Date(2024, 8, 29, 10, 0, 0, 0, time.UTC),
+			To:      time.Date(2024, 8, 29, 12, 0, 0, 0, time.UTC),
+		},
+		Reason: "Corte de red",
+	}
+}
+
+func TestBulkRefund_ID(t *testing.T) {
+	b := outage()
+	reordered := outage()
+	reordered.Filter.Callers = []string{"+5491100000001", "+5491100000002"}
+	reordered.Filter.From = b.Filter.From.In(time.FixedZone("ART", -3*3600))
+	assert.Equal(t, b.ID(), reordered.ID(), "el orden de callers y la zona no cambian el id")
+
+	percent := 50
+	partial := outage()
+	partial.Percent = &percent
+	assert.NotEqual(t, b.ID(), partial.ID())
+
+	b.BulkID = "corte-0829"
+	assert.Equal(t, "corte-0829", b.ID())
+	assert.Equal(t, RefundCall{CallID: refundCallID, Reason: "Corte de red", RefundID: "bulk-corte-0829"}, b.RefundFor(refundCallID))
+}
+
+func TestBulkRefund_Validate(t *testing.T) {
+	assert.NoError(t, outage().Validate())
+	expired := outage()
+	expired.Filter.Statuses = []string{"REFUND_PARTIALLY", "EXPIRED"}
+	assert.NoError(t, expired.Validate())
+
+	percent := 0
+	var verr *ValidationError
+	err := BulkRefund{Filter: BulkRefundFilter{
+		Callers:      []string{"+5491100000001"},
+		CallerPrefix: "+54911",
+		From:         time.Date(2024, 8, 29, 12, 0, 0, 0, time.UTC),
+		To:           time.Date(2024, 8, 29, 10, 0, 0, 0, time.UTC),
+		Statuses:     []string{"OK", "BILLED"},
+	}, Percent: &percent}.Validate()
+	if assert.ErrorAs(t, err, &verr) {
+		var fields []string
+		for _, f := range verr.Fields {
+			fields = append(fields, f.Field)
+		}
+		assert.Equal(t, []string{"callers", "to", "statuses", "reason", "percent"}, fields)
+	}
+}
+
+func TestBulkRefundFilter_Matches(t *testing.T) {
+	f := outage().Filter
+	at := f.From.Add(time.Hour)
+	assert.True(t, f.Matches("+5491100000001", at, "OK"))
+	assert.True(t, f.Matches("+5491100000001", f.From, "OK"))
+	assert.False(t, f.Matches("+5491100000001", f.To, "OK"), "to es excluyente")
+	assert.False(t, f.Matches("+5491100000003", at, "OK"))
+
+	f.Callers, f.CallerPrefix = nil, "+54911"
+	f.Statuses = []string{"OK", "PENDING"}
+	assert.True(t, f.Matches("+5491100000003", at, "PENDING"))
+	assert.False(t, f.Matches("+5491100000003", at, "ERROR"))
+	assert.False(t, f.Matches("+5491200000003", at, "OK"))
+}
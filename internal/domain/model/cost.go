@@ -1,6 +1,126 @@
 package model
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCostResponse indica que el proveedor respondió un costo que no se
+// puede facturar. Es un error del proveedor, no de la llamada.
+var ErrInvalidCostResponse = errors.New("respuesta de costo inválida")
+
+// CostResponse es la respuesta de un proveedor de costo. Provider lo
+// completa quien sabe qué proveedor respondió (ver RoutingCostClient).
 type CostResponse struct {
-	Currency string  `json:"currency"`
-	Cost     float64 `json:"cost"`
+	Cost      Money
+	Breakdown CostBreakdown
+	Provider  string
+}
+
+// CostBreakdown es el detalle opcional que informa el proveedor junto al costo.
+type CostBreakdown struct {
+	// Taxes están incluidos en Cost y van en su misma moneda.
+	Taxes             []Tax
+	RateID            string
+	BilledDurationSec *int
+	ProviderReference string
+}
+
+type Tax struct {
+	Name   string `json:"name"`
+	Amount Money  `json:"amount"`
+}
+
+// UnmarshalJSON lee {"currency": "ARS", "cost": 8.5} sin pasar el costo por
+// float64: el número se toma literal y se redondea según la moneda. currency
+// y cost son obligatorios; taxes, rate_id, billed_duration_sec y
+// provider_reference son opcionales.
+func (c *CostResponse) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Currency string      `json:"currency"`
+		Cost     json.Number `json:"cost"`
+		Taxes    []struct {
+			Name   string      `json:"name"`
+			Amount json.Number `json:"amount"`
+		} `json:"taxes"`
+		RateID            string `json:"rate_id"`
+		BilledDurationSec *int   `json:"billed_duration_sec"`
+		ProviderReference string `json:"provider_reference"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCostResponse, err)
+	}
+	if aux.Currency == "" {
+		return fmt.Errorf("%w: falta currency", ErrInvalidCostResponse)
+	}
+	if aux.Cost == "" {
+		return fmt.Errorf("%w: falta cost", ErrInvalidCostResponse)
+	}
+	cost, err := ParseMoney(aux.Cost.String(), aux.Currency)
+	if err != nil {
+		return fmt.Errorf("%w: cost: %v", ErrInvalidCostResponse, err)
+	}
+
+	resp := CostResponse{Cost: cost, Breakdown: CostBreakdown{
+		RateID:            aux.RateID,
+		BilledDurationSec: aux.BilledDurationSec,
+		ProviderReference: aux.ProviderReference,
+	}}
+	for _, t := range aux.Taxes {
+		if t.Amount == "" {
+			return fmt.Errorf("%w: impuesto %q sin amount", ErrInvalidCostResponse, t.Name)
+		}
+		amount, err := ParseMoney(t.Amount.String(), aux.Currency)
+		if err != nil {
+			return fmt.Errorf("%w: impuesto %q: %v", ErrInvalidCostResponse, t.Name, err)
+		}
+		resp.Breakdown.Taxes = append(resp.Breakdown.Taxes, Tax{Name: t.Name, Amount: amount})
+	}
+	if err := resp.Validate(); err != nil {
+		return err
+	}
+	*c = resp
+	return nil
+}
+
+// Validate rechaza costos que no se pueden facturar: moneda fuera de
+// ISO-4217, montos negativos o impuestos que no cierran con el costo.
+func (c CostResponse) Validate() error {
+	if !IsISOCurrency(c.Cost.Currency) {
+		return fmt.Errorf("%w: currency %q no es ISO-4217", ErrInvalidCostResponse, c.Cost.Currency)
+	}
+	if c.Cost.IsNegative() {
+		return fmt.Errorf("%w: cost negativo %s", ErrInvalidCostResponse, c.Cost.String())
+	}
+	var taxes int64
+	for _, t := range c.Breakdown.Taxes {
+		switch {
+		case t.Name == "":
+			return fmt.Errorf("%w: impuesto sin name", ErrInvalidCostResponse)
+		case t.Amount.Currency != c.Cost.Currency:
+			return fmt.Errorf("%w: impuesto %q en %s y cost en %s", ErrInvalidCostResponse, t.Name, t.Amount.Currency, c.Cost.Currency)
+		case t.Amount.IsNegative():
+			return fmt.Errorf("%w: impuesto %q negativo", ErrInvalidCostResponse, t.Name)
+		}
+		taxes += t.Amount.Amount
+	}
+	if taxes > c.Cost.Amount {
+		return fmt.Errorf("%w: los impuestos superan el cost", ErrInvalidCostResponse)
+	}
+	if d := c.Breakdown.BilledDurationSec; d != nil && *d < 0 {
+		return fmt.Errorf("%w: billed_duration_sec negativo", ErrInvalidCostResponse)
+	}
+	return nil
+}
+
+// CostResult es lo que se persiste al obtener el costo de una llamada.
+// Provider es el proveedor que tarifó la llamada (vacío si no se conoce).
+// BaseCost es el costo normalizado a la moneda base; nil si no hay
+// cotización para la fecha de la llamada.
+type CostResult struct {
+	Cost      Money
+	BaseCost  *Money
+	Provider  string
+	Breakdown CostBreakdown
 }
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostResponse_UnmarshalJSON_ReadsBreakdown(t *testing.T) {
+	var resp CostResponse
+	err := json.Unmarshal([]byte(`{
+		"currency": "ARS", "cost": 10.00,
+		"taxes": [{"name": "IVA", "amount": 1.74}],
+		"rate_id": "AR-MOBILE-2024", "billed_duration_sec": 120, "provider_reference": "ref-42"
+	}`), &resp)
+
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(1000, "ARS"), resp.Cost)
+	assert.Equal(t, []Tax{{Name: "IVA", Amount: NewMoney(174, "ARS")}}, resp.Breakdown.Taxes)
+	assert.Equal(t, "AR-MOBILE-2024", resp.Breakdown.RateID)
+	assert.Equal(t, 120, *resp.Breakdown.BilledDurationSec)
+	assert.Equal(t, "ref-42", resp.Breakdown.ProviderReference)
+}
+
+func TestCostResponse_UnmarshalJSON_RejectsInvalidResponses(t *testing.T) {
+	tests := map[string]string{
+		"sin currency":         `{"cost": 1}`,
+		"sin cost":             `{"currency": "USD"}`,
+		"cost null":            `{"currency": "USD", "cost": null}`,
+		"currency inexistente": `{"currency": "XYZ", "cost": 1}`,
+		"cost negativo":        `{"currency": "USD", "cost": -0.5}`,
+		"impuesto negativo":    `{"currency": "USD", "cost": 1, "taxes": [{"name": "IVA", "amount": -0.1}]}`,
+		"impuesto sin nombre":  `{"currency": "USD", "cost": 1, "taxes": [{"amount": 0.1}]}`,
+		"impuestos > cost":     `{"currency": "USD", "cost": 1, "taxes": [{"name": "IVA", "amount": 1.5}]}`,
+		"duración negativa":    `{"currency": "USD", "cost": 1, "billed_duration_sec": -1}`,
+		"cost no numérico":     `{"currency": "USD", "cost": "gratis"}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			var resp CostResponse
+			err := json.Unmarshal([]byte(body), &resp)
+			assert.ErrorIs(t, err, ErrInvalidCostResponse)
+		})
+	}
+}
+
+func TestParseMoney_UsesISOExponentForUnlistedCurrencies(t *testing.T) {
+	m, err := ParseMoney("1.2345", "KWD")
+
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(1235, "KWD"), m)
+	assert.Equal(t, "1.235", m.String())
+}
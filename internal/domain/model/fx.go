@@ -0,0 +1,40 @@
+package model
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// FXRate indica cuántas unidades de To vale una unidad de From en la fecha dada.
+type FXRate struct {
+	Date time.Time
+	From string
+	To   string
+	Rate *big.Rat
+}
+
+func ParseFXRate(date time.Time, from, to, rate string) (FXRate, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return FXRate{}, fmt.Errorf("cotización inválida %s/%s: %q", from, to, rate)
+	}
+	return FXRate{Date: DateOnly(date), From: currencyFor(from).Code, To: currencyFor(to).Code, Rate: r}, nil
+}
+
+// Inverse devuelve la cotización To -> From de la misma fecha.
+func (r FXRate) Inverse() FXRate {
+	return FXRate{Date: r.Date, From: r.To, To: r.From, Rate: new(big.Rat).Inv(r.Rate)}
+}
+
+// Convert aplica rate (unidades de `to` por unidad de m.Currency) y redondea
+// según la regla de la moneda destino.
+func (m Money) Convert(rate *big.Rat, to string) (Money, error) {
+	return moneyFromRat(new(big.Rat).Mul(m.major(), rate), to)
+}
+
+// DateOnly trunca a la fecha UTC: las cotizaciones son diarias.
+func DateOnly(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
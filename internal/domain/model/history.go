@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// Tipos de eventos del historial de una llamada.
+const (
+	EventRefund                = "refund"
+	EventRefundReversed        = "refund_reversed"
+	EventRefundReversalPending = "refund_reversal_pending"
+	EventRefundRejected        = "refund_rejected"
+	EventCostAdjusted          = "cost_adjusted"
+	EventPlaceholderExpired    = "placeholder_expired"
+)
+
+// CallEvent es una entrada del historial de una llamada: qué le pasó y
+// cuándo, para auditar cambios posteriores a la tarifación.
+type CallEvent struct {
+	CallID string
+	Type   string
+	Detail string
+	At     time.Time
+}
+
+// RefundEvent registra un refund guardado; reversed indica que una anulación
+// que llegó antes lo dejó sin efecto.
+func RefundEvent(refund RefundCall, reversed bool) CallEvent {
+	detail := refund.String()
+	if refund.Reason != "" {
+		detail += ": " + refund.Reason
+	}
+	if reversed {
+		detail += " (anulado al llegar)"
+	}
+	return CallEvent{CallID: refund.CallID, Type: EventRefund, Detail: detail}
+}
+
+// RejectedRefundEvent registra un refund que no entró en el costo con que se
+// tarifó la llamada (ver RecheckRefunds).
+func RejectedRefundEvent(refund RefundCall) CallEvent {
+	return CallEvent{CallID: refund.CallID, Type: EventRefundRejected, Detail: refund.String() + ": " + refund.Rejected}
+}
+
+// ReversalEvent registra una anulación: aplicada sobre refund con el cambio
+// de estado from -> to, o pendiente si refund es nil.
+func ReversalEvent(v RefundReversal, refund *RefundCall, from, to string) CallEvent {
+	e := CallEvent{CallID: v.CallID, Type: EventRefundReversalPending, Detail: v.String()}
+	if refund != nil {
+		e.Type = EventRefundReversed
+		e.Detail = "anula " + refund.String()
+		if from != to {
+			e.Detail += "; estado " + from + " -> " + to
+		}
+	}
+	if v.Reason != "" {
+		e.Detail += ": " + v.Reason
+	}
+	return e
+}
+
+// AdjustmentEvent registra un ajuste manual del costo.
+func AdjustmentEvent(a CostAdjustment) CallEvent {
+	return CallEvent{
+		CallID: a.CallID,
+		Type:   EventCostAdjusted,
+		Detail: a.Cost.String() + " " + a.Cost.Currency + " por " + a.Actor + ": " + a.Reason,
+	}
+}
+
+// ExpiryEvent registra la expiración de un placeholder cuya llamada no llegó.
+func ExpiryEvent(p Placeholder) CallEvent {
+	return CallEvent{
+		CallID: p.CallID,
+		Type:   EventPlaceholderExpired,
+		Detail: "REFUND_PARTIALLY -> EXPIRED: sin new_incoming_call desde " + p.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
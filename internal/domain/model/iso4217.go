@@ -0,0 +1,33 @@
+package model
+
+import "strings"
+
+// isoCurrencies son los códigos ISO-4217 vigentes con su exponente. La tabla
+// currencies define además la regla de redondeo de las monedas que usamos.
+var isoCurrencies = func() map[string]int {
+	exponents := map[int]string{
+		0: "BIF CLP DJF GNF ISK JPY KMF KRW PYG RWF UGX UYI VND VUV XAF XOF XPF",
+		2: "AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BMD BND BOB BOV BRL BSD " +
+			"BTN BWP BYN BZD CAD CDF CHE CHF CHW CNY COP COU CRC CUC CUP CVE CZK DKK DOP DZD " +
+			"EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GTQ GYD HKD HNL HTG HUF IDR ILS INR " +
+			"IRR JMD KES KGS KHR KPW KYD KZT LAK LBP LKR LRD LSL MAD MDL MGA MKD MMK MNT MOP " +
+			"MRU MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO NOK NPR NZD PAB PEN PGK PHP PKR PLN " +
+			"QAR RON RSD RUB SAR SBD SCR SDG SEK SGD SHP SLE SLL SOS SRD SSP STN SVC SYP SZL " +
+			"THB TJS TMT TOP TRY TTD TWD TZS UAH USD USN UYU UZS VED VES WST XCD YER ZAR ZMW ZWL",
+		3: "BHD IQD JOD KWD LYD OMR TND",
+		4: "CLF UYW",
+	}
+	codes := map[string]int{}
+	for exp, list := range exponents {
+		for _, code := range strings.Fields(list) {
+			codes[code] = exp
+		}
+	}
+	return codes
+}()
+
+// IsISOCurrency indica si code es un código ISO-4217 vigente.
+func IsISOCurrency(code string) bool {
+	_, ok := isoCurrencies[strings.ToUpper(code)]
+	return ok
+}
@@ -1,22 +1,45 @@
 package model
 
+import "time"
+
 type Message struct {
 	Type string      `json:"type"`
 	Body interface{} `json:"body"`
 }
 
 type NewIncomingCall struct {
-	CallID           string   `json:"call_id"`
-	Caller           string   `json:"caller"`
-	Receiver         string   `json:"receiver"`
-	DurationInSec    int      `json:"duration_in_seconds"`
-	StartTimestamp   string   `json:"start_timestamp"`
-	Cost             *float64 `json:"cost,omitempty"`
-	Currency         string   `json:"currency,omitempty"`
-	
+	CallID         string    `json:"call_id"`
+	Caller         string    `json:"caller"`
+	Receiver       string    `json:"receiver"`
+	DurationInSec  int       `json:"duration_in_seconds"`
+	StartTimestamp time.Time `json:"start_timestamp"`
+	Cost           *Money    `json:"cost,omitempty"`
+	// Numbering lo completa PhoneNormalizer; no viaja en el mensaje.
+	Numbering CallNumbering `json:"-"`
 }
 
 type RefundCall struct {
 	CallID string `json:"call_id"`
 	Reason string `json:"reason"`
+	// RefundID identifica el refund para descartar reentregas; si no viene
+	// se deriva del contenido (ver ID).
+	RefundID string `json:"refund_id,omitempty"`
+	// Amount o Percent hacen el refund parcial; sin ninguno es total.
+	Amount  *Money `json:"amount,omitempty"`
+	Percent *int   `json:"percent,omitempty"`
+	// Reversed lo completa el repositorio: el refund fue anulado por un
+	// refund_reversed y no cuenta.
+	Reversed bool `json:"-"`
+	// Rejected lo completa el repositorio: por qué un refund aceptado antes
+	// de tarifar la llamada no entra en su costo (ver RecheckRefunds). No
+	// cuenta.
+	Rejected string `json:"-"`
+}
+
+// RefundReversal anula un refund emitido por error. Sin RefundID anula el
+// refund total de la llamada.
+type RefundReversal struct {
+	CallID   string `json:"call_id"`
+	RefundID string `json:"refund_id,omitempty"`
+	Reason   string `json:"reason"`
 }
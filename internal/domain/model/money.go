@@ -0,0 +1,223 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+type RoundingMode int
+
+const (
+	// RoundHalfUp redondea los empates alejándose de cero (0.125 -> 0.13).
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven redondea los empates al par más cercano (0.125 -> 0.12).
+	RoundHalfEven
+)
+
+// Currency define cuántos decimales tiene la moneda (exponente ISO-4217) y
+// cómo se redondean los montos que llegan con más precisión.
+type Currency struct {
+	Code     string
+	Exponent int
+	Rounding RoundingMode
+}
+
+var currencies = map[string]Currency{
+	"ARS": {Code: "ARS", Exponent: 2, Rounding: RoundHalfUp},
+	"USD": {Code: "USD", Exponent: 2, Rounding: RoundHalfEven},
+	"EUR": {Code: "EUR", Exponent: 2, Rounding: RoundHalfEven},
+	"BRL": {Code: "BRL", Exponent: 2, Rounding: RoundHalfUp},
+	"UYU": {Code: "UYU", Exponent: 2, Rounding: RoundHalfUp},
+	"MXN": {Code: "MXN", Exponent: 2, Rounding: RoundHalfUp},
+	"GBP": {Code: "GBP", Exponent: 2, Rounding: RoundHalfEven},
+	"CLP": {Code: "CLP", Exponent: 0, Rounding: RoundHalfUp},
+	"JPY": {Code: "JPY", Exponent: 0, Rounding: RoundHalfUp},
+}
+
+// defaultCurrency se usa para códigos que no están en la tabla; el exponente
+// se toma de ISO-4217 si el código existe.
+var defaultCurrency = Currency{Exponent: 2, Rounding: RoundHalfUp}
+
+func LookupCurrency(code string) (Currency, bool) {
+	c, ok := currencies[strings.ToUpper(code)]
+	return c, ok
+}
+
+func currencyFor(code string) Currency {
+	if c, ok := LookupCurrency(code); ok {
+		return c
+	}
+	c := defaultCurrency
+	c.Code = strings.ToUpper(code)
+	if exp, ok := isoCurrencies[c.Code]; ok {
+		c.Exponent = exp
+	}
+	return c
+}
+
+// Money es un monto en unidades menores de la moneda (p. ej. centavos).
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func NewMoney(minorUnits int64, currency string) Money {
+	return Money{Amount: minorUnits, Currency: strings.ToUpper(currency)}
+}
+
+// plainDecimal es el único formato de monto aceptado: big.Rat también
+// acepta fracciones ("1/3") y exponentes ("1e3").
+var plainDecimal = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// ParseMoney convierte un decimal exacto (sin pasar por float64) a Money,
+// redondeando a los decimales de la moneda según su regla.
+func ParseMoney(amount, currency string) (Money, error) {
+	trimmed := strings.TrimSpace(amount)
+	if !plainDecimal.MatchString(trimmed) {
+		return Money{}, fmt.Errorf("monto inválido: %q", amount)
+	}
+	r, ok := new(big.Rat).SetString(trimmed)
+	if !ok {
+		return Money{}, fmt.Errorf("monto inválido: %q", amount)
+	}
+	return moneyFromRat(r, currency)
+}
+
+// moneyFromRat redondea r (en unidades de la moneda) a sus unidades menores
+// una sola vez, según la regla de la moneda.
+func moneyFromRat(r *big.Rat, currency string) (Money, error) {
+	c := currencyFor(currency)
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow10(c.Exponent)))
+	minor := roundRat(scaled, c.Rounding)
+	if !minor.IsInt64() {
+		return Money{}, fmt.Errorf("monto fuera de rango: %s %s", r.FloatString(c.Exponent), c.Code)
+	}
+	return Money{Amount: minor.Int64(), Currency: c.Code}, nil
+}
+
+func MustParseMoney(amount, currency string) Money {
+	m, err := ParseMoney(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m Money) Exponent() int {
+	return currencyFor(m.Currency).Exponent
+}
+
+// String devuelve el monto como decimal con los decimales de la moneda ("8.50").
+func (m Money) String() string {
+	exp := m.Exponent()
+	sign := ""
+	abs := new(big.Int).SetInt64(m.Amount)
+	if abs.Sign() < 0 {
+		sign = "-"
+		abs.Neg(abs)
+	}
+	digits := abs.String()
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Precisión de las columnas de montos (NUMERIC(10, 2)). Vive en el modelo para
+// que la validación de los mensajes rechace montos que el repositorio no
+// podría guardar.
+const (
+	StoredPrecision = 10
+	StoredScale     = 2
+)
+
+// CheckStorable verifica que el monto entre en las columnas de montos.
+func (m Money) CheckStorable() error {
+	return m.CheckPrecision(StoredPrecision, StoredScale)
+}
+
+// CheckPrecision verifica que el monto entre en una columna NUMERIC(precision, scale)
+// sin que la base lo redondee ni desborde. El error es permanente: reintentar
+// no cambia el monto.
+func (m Money) CheckPrecision(precision, scale int) error {
+	exp := m.Exponent()
+	if exp > scale {
+		return Permanent(fmt.Errorf("la moneda %s usa %d decimales y la columna admite %d", m.Currency, exp, scale))
+	}
+	limit := pow10(precision - scale + exp)
+	abs := new(big.Int).Abs(big.NewInt(m.Amount))
+	if abs.Cmp(limit) >= 0 {
+		return Permanent(fmt.Errorf("monto %s %s excede NUMERIC(%d, %d)", m.String(), m.Currency, precision, scale))
+	}
+	return nil
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.String(), m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Amount   json.Number `json:"amount"`
+		Currency string      `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	parsed, err := ParseMoney(aux.Amount.String(), aux.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Prorate devuelve m * num / den redondeado según la regla de la moneda.
+func (m Money) Prorate(num, den int64) Money {
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num)), big.NewInt(den))
+	return Money{Amount: roundRat(r, currencyFor(m.Currency).Rounding).Int64(), Currency: m.Currency}
+}
+
+func roundRat(r *big.Rat, mode RoundingMode) *big.Int {
+	num, den := r.Num(), r.Denom()
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return q
+	}
+
+	twiceRem := new(big.Int).Abs(rem)
+	twiceRem.Lsh(twiceRem, 1)
+	away := false
+	switch cmp := twiceRem.Cmp(den); {
+	case cmp > 0:
+		away = true
+	case cmp == 0:
+		away = mode == RoundHalfUp || q.Bit(0) == 1
+	}
+
+	if away {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
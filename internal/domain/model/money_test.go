@@ -0,0 +1,89 @@
+package model
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney_RoundingPerCurrency(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     Money
+	}{
+		{"8.5", "ARS", Money{850, "ARS"}},
+		{"0.125", "ARS", Money{13, "ARS"}}, // half-up
+		{"0.125", "USD", Money{12, "USD"}}, // half-even
+		{"0.135", "USD", Money{14, "USD"}}, // half-even
+		{"-0.125", "ARS", Money{-13, "ARS"}},
+		{"1234.5", "JPY", Money{1235, "JPY"}},
+		{"12.3456789012345", "eur", Money{1235, "EUR"}},
+		{"3.14159", "XYZ", Money{314, "XYZ"}}, // moneda desconocida: 2 decimales
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.amount, tt.currency)
+		assert.NoError(t, err, tt.amount)
+		assert.Equal(t, tt.want, got, "%s %s", tt.amount, tt.currency)
+	}
+}
+
+func TestParseMoney_Invalid(t *testing.T) {
+	_, err := ParseMoney("abc", "USD")
+	assert.Error(t, err)
+
+	for _, amount := range []string{"1e30", "1e2", "1E2", "1/3", "-1/3", ".5", "8.", "+8.50", "0x10"} {
+		_, err = ParseMoney(amount, "USD")
+		assert.Error(t, err, amount)
+	}
+	_, err = ParseMoney("99999999999999999999", "USD")
+	assert.Error(t, err)
+}
+
+func TestMoney_Convert_RoundsOnce(t *testing.T) {
+	// 0.0050000000000004 USD está por encima de la mitad; redondeado antes a
+	// 12 decimales sería un empate y half-even lo bajaría a 0.00.
+	rate, _ := new(big.Rat).SetString("0.0050000000000004")
+	got, err := MustParseMoney("1", "EUR").Convert(rate, "USD")
+
+	assert.NoError(t, err)
+	assert.Equal(t, Money{1, "USD"}, got)
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "8.50", NewMoney(850, "ARS").String())
+	assert.Equal(t, "0.05", NewMoney(5, "USD").String())
+	assert.Equal(t, "-1.20", NewMoney(-120, "USD").String())
+	assert.Equal(t, "1500", NewMoney(1500, "JPY").String())
+	assert.Equal(t, "0.00", Money{}.String())
+}
+
+func TestMoney_CheckPrecision(t *testing.T) {
+	assert.NoError(t, MustParseMoney("99999999.99", "USD").CheckPrecision(10, 2))
+	assert.Error(t, MustParseMoney("100000000", "USD").CheckPrecision(10, 2))
+	assert.NoError(t, MustParseMoney("99999999", "JPY").CheckPrecision(10, 2))
+	assert.Error(t, MustParseMoney("100000000", "JPY").CheckPrecision(10, 2))
+	assert.True(t, IsPermanent(MustParseMoney("1.234", "KWD").CheckPrecision(10, 2)))
+}
+
+func TestMoney_JSONRoundTrip(t *testing.T) {
+	data, err := json.Marshal(NewMoney(1999, "USD"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount":"19.99","currency":"USD"}`, string(data))
+
+	var m Money
+	assert.NoError(t, json.Unmarshal(data, &m))
+	assert.Equal(t, NewMoney(1999, "USD"), m)
+}
+
+func TestCostResponse_UnmarshalJSON_DoesNotUseFloat(t *testing.T) {
+	var resp CostResponse
+	err := json.Unmarshal([]byte(`{"currency":"ARS","cost":10.005}`), &resp)
+
+	assert.NoError(t, err)
+	// 10.005 como float64 es 10.00499999..., que redondearía a 10.00
+	assert.Equal(t, NewMoney(1001, "ARS"), resp.Cost)
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// PendingEvent es un mensaje de una llamada cuyo new_incoming_call todavía
+// no llegó. Queda estacionado con su tipo y body originales y se reprocesa,
+// en orden de llegada, cuando llega la llamada.
+type PendingEvent struct {
+	CallID string
+	Type   string
+	Body   []byte
+	// Seq y ReceivedAt los completa el repositorio; Seq da el orden de llegada.
+	Seq        int64
+	ReceivedAt time.Time
+}
+
+// ID es un hash del tipo y el body: una reentrega del mismo mensaje se
+// estaciona una sola vez.
+func (e PendingEvent) ID() string {
+	h := sha256.New()
+	h.Write([]byte(e.Type))
+	h.Write([]byte{0})
+	h.Write(e.Body)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
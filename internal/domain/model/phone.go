@@ -0,0 +1,245 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidPhoneNumber indica un número que no puede existir en su plan de
+// numeración.
+var ErrInvalidPhoneNumber = errors.New("número de teléfono inválido")
+
+type NumberType string
+
+const (
+	NumberTypeMobile   NumberType = "mobile"
+	NumberTypeFixed    NumberType = "fixed"
+	NumberTypeTollFree NumberType = "toll_free"
+	NumberTypePremium  NumberType = "premium"
+	// NumberTypeFixedOrMobile: planes donde el número no distingue (NANP, MX).
+	NumberTypeFixedOrMobile NumberType = "fixed_or_mobile"
+	// NumberTypeUnknown: país sin plan cargado; solo se valida el largo E.164.
+	NumberTypeUnknown NumberType = "unknown"
+)
+
+// PhoneNumber es un número normalizado a E.164 con los datos de su plan.
+type PhoneNumber struct {
+	E164    string
+	Country string
+	Type    NumberType
+}
+
+// CallNumbering son los metadatos de numeración que se guardan con la llamada.
+type CallNumbering struct {
+	CallerCountry   string
+	CallerType      NumberType
+	ReceiverCountry string
+	ReceiverType    NumberType
+}
+
+// numberingPlan describe un país: código de país, prefijo troncal nacional y
+// los patrones del número nacional significativo (sin código ni troncal) por
+// tipo, en orden de prioridad. Un número que no matchea ninguno es imposible.
+type numberingPlan struct {
+	country     string
+	callingCode string
+	trunk       string
+	types       []numberTypePattern
+	// nationalMobile reescribe el formato nacional de celulares cuando difiere
+	// del internacional (en AR, 011 15 xxxx-xxxx es +54 9 11 xxxx-xxxx).
+	nationalMobile func(national string) (string, bool)
+}
+
+type numberTypePattern struct {
+	pattern *regexp.Regexp
+	typ     NumberType
+}
+
+func withNationalMobile(p numberingPlan, rewrite func(string) (string, bool)) numberingPlan {
+	p.nationalMobile = rewrite
+	return p
+}
+
+// argentineMobile convierte área + "15" + abonado (12 dígitos, el área tiene
+// de 2 a 4) en 9 + área + abonado.
+func argentineMobile(national string) (string, bool) {
+	if len(national) != 12 {
+		return "", false
+	}
+	for area := 2; area <= 4; area++ {
+		if national[area:area+2] == "15" && national[0] >= '1' && national[0] <= '3' {
+			return "9" + national[:area] + national[area+2:], true
+		}
+	}
+	return "", false
+}
+
+func plan(country, callingCode, trunk string, types ...interface{}) numberingPlan {
+	p := numberingPlan{country: country, callingCode: callingCode, trunk: trunk}
+	for i := 0; i < len(types); i += 2 {
+		p.types = append(p.types, numberTypePattern{
+			pattern: regexp.MustCompile("^(?:" + types[i].(string) + ")$"),
+			typ:     types[i+1].(NumberType),
+		})
+	}
+	return p
+}
+
+var numberingPlans = []numberingPlan{
+	withNationalMobile(plan("AR", "54", "0",
+		`800\d{7}`, NumberTypeTollFree,
+		`600\d{7}`, NumberTypePremium,
+		`9[1-3]\d{9}`, NumberTypeMobile,
+		`[1-3]\d{9}`, NumberTypeFixed), argentineMobile),
+	plan("US", "1", "1",
+		`8(?:00|33|44|55|66|77|88)\d{7}`, NumberTypeTollFree,
+		`900\d{7}`, NumberTypePremium,
+		`[2-9]\d{2}[2-9]\d{6}`, NumberTypeFixedOrMobile),
+	plan("BR", "55", "0",
+		`800\d{6,7}`, NumberTypeTollFree,
+		`900\d{6,7}`, NumberTypePremium,
+		`[1-9]{2}9\d{8}`, NumberTypeMobile,
+		`[1-9]{2}[2-5]\d{7}`, NumberTypeFixed),
+	plan("UY", "598", "0",
+		`80[05]\d{4}`, NumberTypeTollFree,
+		`90[0-9]\d{4}`, NumberTypePremium,
+		`9[1-9]\d{6}`, NumberTypeMobile,
+		`[24]\d{7}`, NumberTypeFixed),
+	plan("MX", "52", "",
+		`800\d{7}`, NumberTypeTollFree,
+		`900\d{7}`, NumberTypePremium,
+		`[1-9]\d{9}`, NumberTypeFixedOrMobile),
+	plan("ES", "34", "",
+		`900\d{6}`, NumberTypeTollFree,
+		`80[36]\d{6}`, NumberTypePremium,
+		`[67]\d{8}`, NumberTypeMobile,
+		`[89]\d{8}`, NumberTypeFixed),
+	plan("GB", "44", "0",
+		`80[08]\d{6,7}`, NumberTypeTollFree,
+		`9\d{9}`, NumberTypePremium,
+		`7\d{9}`, NumberTypeMobile,
+		`[123]\d{8,9}`, NumberTypeFixed),
+}
+
+// PhoneNormalizer convierte números en texto libre a E.164. Los números sin
+// código de país se interpretan en el país por defecto.
+type PhoneNormalizer struct {
+	defaultPlan numberingPlan
+}
+
+func NewPhoneNormalizer(defaultCountry string) (*PhoneNormalizer, error) {
+	for _, p := range numberingPlans {
+		if p.country == strings.ToUpper(defaultCountry) {
+			return &PhoneNormalizer{defaultPlan: p}, nil
+		}
+	}
+	return nil, fmt.Errorf("país por defecto sin plan de numeración: %q", defaultCountry)
+}
+
+// Normalize acepta "+54 9 11 1234-5678", "00541112345678", "011 1234-5678"
+// (nacional con troncal), "011 15 1234-5678" y variantes con espacios,
+// guiones, puntos o paréntesis.
+func (n *PhoneNormalizer) Normalize(raw string) (PhoneNumber, error) {
+	digits, international, err := splitDialString(raw)
+	if err != nil {
+		return PhoneNumber{}, err
+	}
+
+	if !international {
+		p := n.defaultPlan
+		national := digits
+		if p.trunk != "" && strings.HasPrefix(national, p.trunk) {
+			national = national[len(p.trunk):]
+		}
+		if typ, ok := p.classify(national); ok {
+			return PhoneNumber{E164: "+" + p.callingCode + national, Country: p.country, Type: typ}, nil
+		}
+		if p.nationalMobile != nil {
+			if mobile, ok := p.nationalMobile(national); ok {
+				if typ, ok := p.classify(mobile); ok {
+					return PhoneNumber{E164: "+" + p.callingCode + mobile, Country: p.country, Type: typ}, nil
+				}
+			}
+		}
+		// Número internacional escrito sin "+" (p. ej. 5491112345678)
+		if !strings.HasPrefix(digits, p.callingCode) {
+			return PhoneNumber{}, fmt.Errorf("%w: %q no es un número válido de %s", ErrInvalidPhoneNumber, raw, p.country)
+		}
+	}
+	return normalizeInternational(raw, digits)
+}
+
+func normalizeInternational(raw, digits string) (PhoneNumber, error) {
+	if len(digits) < 8 || len(digits) > 15 {
+		return PhoneNumber{}, fmt.Errorf("%w: %q no tiene el largo de un número E.164", ErrInvalidPhoneNumber, raw)
+	}
+	for _, p := range numberingPlans {
+		if !strings.HasPrefix(digits, p.callingCode) {
+			continue
+		}
+		national := digits[len(p.callingCode):]
+		typ, ok := p.classify(national)
+		if !ok {
+			return PhoneNumber{}, fmt.Errorf("%w: %q no es un número válido de %s", ErrInvalidPhoneNumber, raw, p.country)
+		}
+		return PhoneNumber{E164: "+" + digits, Country: p.country, Type: typ}, nil
+	}
+	return PhoneNumber{E164: "+" + digits, Type: NumberTypeUnknown}, nil
+}
+
+// splitDialString deja solo los dígitos e indica si el número trae código de
+// país ("+" o prefijo internacional "00").
+func splitDialString(raw string) (string, bool, error) {
+	s := strings.TrimSpace(raw)
+	international := strings.HasPrefix(s, "+")
+	s = strings.TrimPrefix(s, "+")
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case strings.ContainsRune(" -().", r):
+		default:
+			return "", false, fmt.Errorf("%w: %q tiene caracteres no permitidos", ErrInvalidPhoneNumber, raw)
+		}
+	}
+	digits := b.String()
+	if digits == "" {
+		return "", false, fmt.Errorf("%w: %q no tiene dígitos", ErrInvalidPhoneNumber, raw)
+	}
+	if !international && strings.HasPrefix(digits, "00") {
+		return digits[2:], true, nil
+	}
+	return digits, international, nil
+}
+
+func (p numberingPlan) classify(national string) (NumberType, bool) {
+	for _, t := range p.types {
+		if t.pattern.MatchString(national) {
+			return t.typ, true
+		}
+	}
+	return "", false
+}
+
+// NormalizeCall normaliza caller y receiver y completa Numbering. Los números
+// imposibles se informan como errores de validación de cada campo.
+func (n *PhoneNormalizer) NormalizeCall(call NewIncomingCall) (NewIncomingCall, error) {
+	v := &ValidationError{Entity: "new_incoming_call"}
+	if caller, err := n.Normalize(call.Caller); err != nil {
+		v.add("caller", "%v", err)
+	} else {
+		call.Caller = caller.E164
+		call.Numbering.CallerCountry, call.Numbering.CallerType = caller.Country, caller.Type
+	}
+	if receiver, err := n.Normalize(call.Receiver); err != nil {
+		v.add("receiver", "%v", err)
+	} else {
+		call.Receiver = receiver.E164
+		call.Numbering.ReceiverCountry, call.Numbering.ReceiverType = receiver.Country, receiver.Type
+	}
+	return call, v.orNil()
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhoneNormalizer_Normalize(t *testing.T) {
+	ar, err := NewPhoneNormalizer("AR")
+	assert.NoError(t, err)
+	us, err := NewPhoneNormalizer("us")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		normalizer *PhoneNormalizer
+		raw        string
+		want       PhoneNumber
+	}{
+		{"E.164", ar, "+5491112345678", PhoneNumber{"+5491112345678", "AR", NumberTypeMobile}},
+		{"con separadores", ar, "+54 9 (11) 1234-5678", PhoneNumber{"+5491112345678", "AR", NumberTypeMobile}},
+		{"prefijo internacional 00", ar, "00 54 11 1234 5678", PhoneNumber{"+541112345678", "AR", NumberTypeFixed}},
+		{"nacional con troncal", ar, "011 1234-5678", PhoneNumber{"+541112345678", "AR", NumberTypeFixed}},
+		{"celular nacional con 15", ar, "011 15 1234-5678", PhoneNumber{"+5491112345678", "AR", NumberTypeMobile}},
+		{"internacional sin +", ar, "5491112345678", PhoneNumber{"+5491112345678", "AR", NumberTypeMobile}},
+		{"0800", ar, "0800 333 4444", PhoneNumber{"+548003334444", "AR", NumberTypeTollFree}},
+		{"0600", ar, "0600 555 1234", PhoneNumber{"+546005551234", "AR", NumberTypePremium}},
+		{"otro país desde AR", ar, "+1 (202) 555-0100", PhoneNumber{"+12025550100", "US", NumberTypeFixedOrMobile}},
+		{"NANP nacional", us, "202.555.0100", PhoneNumber{"+12025550100", "US", NumberTypeFixedOrMobile}},
+		{"NANP con troncal", us, "1 800 555 0100", PhoneNumber{"+18005550100", "US", NumberTypeTollFree}},
+		{"NANP premium", us, "+1 900 555 0100", PhoneNumber{"+19005550100", "US", NumberTypePremium}},
+		{"España móvil", ar, "+34 612 345 678", PhoneNumber{"+34612345678", "ES", NumberTypeMobile}},
+		{"país sin plan", ar, "+33 1 23 45 67 89", PhoneNumber{"+33123456789", "", NumberTypeUnknown}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.normalizer.Normalize(tc.raw)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestPhoneNormalizer_RejectsImpossibleNumbers(t *testing.T) {
+	ar, _ := NewPhoneNormalizer("AR")
+
+	for _, raw := range []string{
+		"",
+		"+",
+		"abc",
+		"+54 11 1234",            // corto para AR
+		"+54 5 1111 1111",        // área inexistente
+		"+1 (234) 567-890",       // NANP tiene 10 dígitos
+		"+1 023 555 0100",        // área NANP no empieza con 0
+		"1234",                   // nacional corto
+		"+123",                   // corto para E.164
+		"+3312345678901234567",   // largo para E.164
+		"+54 9 11 1234-5678 x12", // extensión
+	} {
+		_, err := ar.Normalize(raw)
+		assert.ErrorIs(t, err, ErrInvalidPhoneNumber, raw)
+	}
+}
+
+func TestNewPhoneNormalizer_UnknownCountry(t *testing.T) {
+	_, err := NewPhoneNormalizer("ZZ")
+	assert.Error(t, err)
+}
+
+func TestPhoneNormalizer_NormalizeCall(t *testing.T) {
+	ar, _ := NewPhoneNormalizer("AR")
+
+	call, err := ar.NormalizeCall(NewIncomingCall{Caller: "011 15 1234-5678", Receiver: "0800 333 4444"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "+5491112345678", call.Caller)
+	assert.Equal(t, "+548003334444", call.Receiver)
+	assert.Equal(t, CallNumbering{CallerCountry: "AR", CallerType: NumberTypeMobile, ReceiverCountry: "AR", ReceiverType: NumberTypeTollFree}, call.Numbering)
+
+	_, err = ar.NormalizeCall(NewIncomingCall{Caller: "+123", Receiver: "+5491112345678"})
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Len(t, verr.Fields, 1)
+		assert.Equal(t, "caller", verr.Fields[0].Field)
+	}
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Placeholder es una fila REFUND_PARTIALLY creada por un refund que llegó
+// antes que su new_incoming_call. Si la llamada nunca llega se expira: pasa
+// al estado terminal EXPIRED.
+type Placeholder struct {
+	CallID    string
+	CreatedAt time.Time
+}
+
+// AgingBucket cuenta los placeholders con antigüedad en [From, To); To 0 es
+// sin tope.
+type AgingBucket struct {
+	From   time.Duration
+	To     time.Duration
+	Count  int
+	Oldest *time.Time
+}
+
+// DefaultAgingBounds son los cortes del reporte de antigüedad: 1 hora,
+// 1 día, 1 semana y 30 días.
+var DefaultAgingBounds = []time.Duration{time.Hour, 24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour}
+
+// PlaceholderAging agrupa placeholders por antigüedad a now. bounds deben ser
+// crecientes; el resultado tiene len(bounds)+1 buckets, vacíos incluidos.
+func PlaceholderAging(placeholders []Placeholder, now time.Time, bounds []time.Duration) []AgingBucket {
+	buckets := make([]AgingBucket, len(bounds)+1)
+	for i := range buckets {
+		if i > 0 {
+			buckets[i].From = bounds[i-1]
+		}
+		if i < len(bounds) {
+			buckets[i].To = bounds[i]
+		}
+	}
+	for _, p := range placeholders {
+		age := now.Sub(p.CreatedAt)
+		i := 0
+		for i < len(bounds) && age >= bounds[i] {
+			i++
+		}
+		b := &buckets[i]
+		b.Count++
+		if b.Oldest == nil || p.CreatedAt.Before(*b.Oldest) {
+			created := p.CreatedAt
+			b.Oldest = &created
+		}
+	}
+	return buckets
+}
+
+// Label describe el bucket: "<1h", "1h-1d", ">=30d".
+func (b AgingBucket) Label() string {
+	switch {
+	case b.From == 0:
+		return "<" + formatAge(b.To)
+	case b.To == 0:
+		return ">=" + formatAge(b.From)
+	default:
+		return formatAge(b.From) + "-" + formatAge(b.To)
+	}
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return d.String()
+	}
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type QualitySeverity string
+
+const (
+	SeverityLow      QualitySeverity = "low"
+	SeverityMedium   QualitySeverity = "medium"
+	SeverityHigh     QualitySeverity = "high"
+	SeverityCritical QualitySeverity = "critical"
+)
+
+var qualitySeverities = []QualitySeverity{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// CallQualityIssue informa un problema de calidad de una llamada (cortes,
+// audio en un solo sentido, ...). Puede llegar antes que la llamada.
+type CallQualityIssue struct {
+	CallID    string          `json:"call_id"`
+	Severity  QualitySeverity `json:"severity"`
+	IssueType string          `json:"issue_type"`
+}
+
+func (q CallQualityIssue) Validate() error {
+	v := &ValidationError{Entity: "call_quality_issue"}
+	validateCallID(v, q.CallID)
+	if !q.Severity.valid() {
+		v.add("severity", "debe ser low, medium, high o critical: %q", q.Severity)
+	}
+	if strings.TrimSpace(q.IssueType) == "" {
+		v.add("issue_type", "es obligatorio")
+	}
+	return v.orNil()
+}
+
+func (s QualitySeverity) valid() bool {
+	for _, known := range qualitySeverities {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// CreditPolicy es el porcentaje del costo que se acredita por severidad.
+type CreditPolicy map[QualitySeverity]int
+
+var DefaultCreditPolicy = CreditPolicy{
+	SeverityLow:      0,
+	SeverityMedium:   10,
+	SeverityHigh:     25,
+	SeverityCritical: 50,
+}
+
+// ParseCreditPolicy aplica overrides "severidad=porcentaje,..." sobre
+// DefaultCreditPolicy.
+func ParseCreditPolicy(spec string) (CreditPolicy, error) {
+	p := CreditPolicy{}
+	for s, pct := range DefaultCreditPolicy {
+		p[s] = pct
+	}
+	if strings.TrimSpace(spec) == "" {
+		return p, nil
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("crédito inválido %q, se espera severidad=porcentaje", pair)
+		}
+		s := QualitySeverity(strings.TrimSpace(kv[0]))
+		if !s.valid() {
+			return nil, fmt.Errorf("severidad desconocida: %s", kv[0])
+		}
+		pct, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil || pct < 0 || pct > 100 {
+			return nil, fmt.Errorf("porcentaje inválido para %s: %q", s, kv[1])
+		}
+		p[s] = pct
+	}
+	return p, nil
+}
+
+// Credit arma el crédito que corresponde al problema según la política.
+func (p CreditPolicy) Credit(issue CallQualityIssue) QualityCredit {
+	return QualityCredit{
+		CallID:    issue.CallID,
+		Severity:  issue.Severity,
+		IssueType: issue.IssueType,
+		Percent:   p[issue.Severity],
+	}
+}
+
+// QualityCredit es un crédito parcial por un problema de calidad. Se guarda
+// aparte del costo de la llamada como porcentaje; el monto se calcula sobre
+// el costo al leerlo, así un crédito que llega antes que la llamada se aplica
+// cuando ésta se tarifa. Cada llamada tiene a lo sumo un crédito: el mayor;
+// uno de mayor porcentaje reemplaza severidad y tipo del anterior.
+type QualityCredit struct {
+	CallID    string
+	Severity  QualitySeverity
+	IssueType string
+	Percent   int
+}
+
+// Amount es el crédito sobre cost, redondeado según la regla de la moneda.
+func (c QualityCredit) Amount(cost Money) Money {
+	return cost.Prorate(int64(c.Percent), 100)
+}
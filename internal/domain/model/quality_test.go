@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallQualityIssue_Validate(t *testing.T) {
+	valid := CallQualityIssue{CallID: "550e8400-e29b-41d4-a716-446655440000", Severity: SeverityHigh, IssueType: "dropped"}
+	assert.NoError(t, valid.Validate())
+
+	err := CallQualityIssue{CallID: "abc", Severity: "grave"}.Validate()
+
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, "call_quality_issue", verr.Entity)
+		var fields []string
+		for _, f := range verr.Fields {
+			fields = append(fields, f.Field)
+		}
+		assert.Equal(t, []string{"call_id", "severity", "issue_type"}, fields)
+	}
+}
+
+func TestParseCreditPolicy(t *testing.T) {
+	p, err := ParseCreditPolicy("high=40, critical=100")
+	assert.NoError(t, err)
+	assert.Equal(t, CreditPolicy{SeverityLow: 0, SeverityMedium: 10, SeverityHigh: 40, SeverityCritical: 100}, p)
+	assert.Equal(t, 10, DefaultCreditPolicy[SeverityMedium], "los overrides no modifican la política por defecto")
+
+	for _, spec := range []string{"high", "grave=10", "high=120", "high=-1", "high=diez"} {
+		_, err := ParseCreditPolicy(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestQualityCredit_Amount(t *testing.T) {
+	credit := QualityCredit{Percent: 25}
+
+	assert.Equal(t, MustParseMoney("2.50", "USD"), credit.Amount(MustParseMoney("10.00", "USD")))
+	// 25% de 0.10 = 0.025: half-up en ARS, half-even en USD
+	assert.Equal(t, MustParseMoney("0.03", "ARS"), credit.Amount(MustParseMoney("0.10", "ARS")))
+	assert.Equal(t, MustParseMoney("0.02", "USD"), credit.Amount(MustParseMoney("0.10", "USD")))
+	assert.Equal(t, MustParseMoney("0.04", "USD"), credit.Amount(MustParseMoney("0.14", "USD")))
+	assert.Equal(t, MustParseMoney("25", "JPY"), credit.Amount(MustParseMoney("99", "JPY")))
+}
@@ -0,0 +1,227 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ErrNoRate indica que ninguna tarifa del tarifario aplica a la llamada.
+var ErrNoRate = errors.New("sin tarifa para el destino")
+
+// RateCard es una versión del tarifario local. Rige desde EffectiveFrom hasta
+// que otra versión con fecha posterior la reemplaza.
+type RateCard struct {
+	Version       string    `json:"version"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	Currency      string    `json:"currency"`
+	// Timezone en la que se evalúan las franjas horarias (por defecto UTC).
+	Timezone string `json:"timezone,omitempty"`
+	Rates    []Rate `json:"rates"`
+}
+
+// Rate tarifa las llamadas cuyo Receiver empieza con Prefix. Days y From/To
+// restringen la tarifa a una franja; vacíos significa siempre. NumberType
+// restringe al tipo de número del receiver (ver PhoneNormalizer).
+type Rate struct {
+	Prefix     string     `json:"prefix"`
+	NumberType NumberType `json:"number_type,omitempty"`
+	// Days: "" (todos), "weekday" o "weekend".
+	Days string `json:"days,omitempty"`
+	// From/To en formato 15:04, To exclusivo. Si From > To la franja cruza la medianoche.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// PerMinute es el precio del minuto; se prorratea según IncrementSeconds
+	// (1 = facturación por segundo, 60 = por minuto).
+	PerMinute        string `json:"per_minute"`
+	IncrementSeconds int    `json:"increment_seconds"`
+	MinimumSeconds   int    `json:"minimum_seconds,omitempty"`
+	ConnectionFee    string `json:"connection_fee,omitempty"`
+}
+
+// Validate revisa el tarifario completo para fallar al cargarlo y no al
+// tarifar la primera llamada.
+func (c RateCard) Validate() error {
+	if c.Version == "" {
+		return errors.New("tarifario sin version")
+	}
+	if c.Currency == "" {
+		return fmt.Errorf("tarifario %s sin currency", c.Version)
+	}
+	if !IsISOCurrency(c.Currency) {
+		return fmt.Errorf("tarifario %s: currency %q no es ISO-4217", c.Version, c.Currency)
+	}
+	if _, err := c.location(); err != nil {
+		return fmt.Errorf("tarifario %s: %w", c.Version, err)
+	}
+	for i, r := range c.Rates {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("tarifario %s, tarifa %d (%s): %w", c.Version, i, r.Prefix, err)
+		}
+	}
+	return nil
+}
+
+// Price tarifa la llamada con la tarifa de prefijo más largo que aplique en
+// el horario de inicio; a igual prefijo gana la primera del tarifario, salvo
+// que otra sea específica del tipo de número.
+func (c RateCard) Price(call NewIncomingCall, start time.Time) (Money, error) {
+	loc, err := c.location()
+	if err != nil {
+		return Money{}, err
+	}
+	rate, ok := c.match(call, start.In(loc))
+	if !ok {
+		return Money{}, fmt.Errorf("%w %s (tarifario %s)", ErrNoRate, call.Receiver, c.Version)
+	}
+	return rate.price(call.DurationInSec, c.Currency)
+}
+
+func (c RateCard) match(call NewIncomingCall, local time.Time) (Rate, bool) {
+	number := digits(call.Receiver)
+	var best Rate
+	found := false
+	for _, r := range c.Rates {
+		prefix := digits(r.Prefix)
+		if !strings.HasPrefix(number, prefix) || !r.appliesAt(local) {
+			continue
+		}
+		if r.NumberType != "" && r.NumberType != call.Numbering.ReceiverType {
+			continue
+		}
+		// A igual prefijo, la tarifa por tipo de número gana a la genérica
+		if !found || len(prefix) > len(digits(best.Prefix)) ||
+			(len(prefix) == len(digits(best.Prefix)) && best.NumberType == "" && r.NumberType != "") {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+func (c RateCard) location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
+func (r Rate) validate() error {
+	if digits(r.Prefix) == "" {
+		return errors.New("prefix vacío")
+	}
+	if r.IncrementSeconds <= 0 {
+		return errors.New("increment_seconds debe ser mayor a 0")
+	}
+	if r.MinimumSeconds < 0 {
+		return errors.New("minimum_seconds negativo")
+	}
+	switch r.NumberType {
+	case "", NumberTypeMobile, NumberTypeFixed, NumberTypeTollFree, NumberTypePremium, NumberTypeFixedOrMobile:
+	default:
+		return fmt.Errorf("number_type inválido: %q", r.NumberType)
+	}
+	if err := validateBand(r.Days, r.From, r.To); err != nil {
+		return err
+	}
+	if _, err := parseRat(r.PerMinute); err != nil {
+		return fmt.Errorf("per_minute: %w", err)
+	}
+	if _, err := parseRat(r.ConnectionFee); err != nil {
+		return fmt.Errorf("connection_fee: %w", err)
+	}
+	return nil
+}
+
+func (r Rate) appliesAt(local time.Time) bool {
+	return inBand(r.Days, r.From, r.To, local)
+}
+
+func validateBand(days, from, to string) error {
+	switch days {
+	case "", "weekday", "weekend":
+	default:
+		return fmt.Errorf("days inválido: %q", days)
+	}
+	if (from == "") != (to == "") {
+		return errors.New("from y to van juntos")
+	}
+	if from != "" {
+		if _, err := minuteOfDay(from); err != nil {
+			return err
+		}
+		if _, err := minuteOfDay(to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inBand indica si local cae en la franja days/from/to (ver Rate).
+func inBand(days, from, to string, local time.Time) bool {
+	weekend := local.Weekday() == time.Saturday || local.Weekday() == time.Sunday
+	if (days == "weekday" && weekend) || (days == "weekend" && !weekend) {
+		return false
+	}
+	if from == "" {
+		return true
+	}
+	f, _ := minuteOfDay(from)
+	t, _ := minuteOfDay(to)
+	now := local.Hour()*60 + local.Minute()
+	if f <= t {
+		return now >= f && now < t
+	}
+	return now >= f || now < t
+}
+
+// price = connection_fee + per_minute * segundos_facturados / 60, donde los
+// segundos se redondean hacia arriba al incremento y respetan el mínimo.
+func (r Rate) price(durationInSec int, currency string) (Money, error) {
+	billed := (durationInSec + r.IncrementSeconds - 1) / r.IncrementSeconds * r.IncrementSeconds
+	if billed < r.MinimumSeconds {
+		billed = r.MinimumSeconds
+	}
+
+	perMinute, err := parseRat(r.PerMinute)
+	if err != nil {
+		return Money{}, err
+	}
+	fee, err := parseRat(r.ConnectionFee)
+	if err != nil {
+		return Money{}, err
+	}
+	total := new(big.Rat).Mul(perMinute, big.NewRat(int64(billed), 60))
+	total.Add(total, fee)
+	return ParseMoney(total.FloatString(12), currency)
+}
+
+func minuteOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("horario inválido %q: se espera HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func parseRat(s string) (*big.Rat, error) {
+	if s == "" {
+		return new(big.Rat), nil
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() < 0 {
+		return nil, fmt.Errorf("monto inválido %q", s)
+	}
+	return r, nil
+}
+
+func digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
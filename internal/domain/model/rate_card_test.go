@@ -0,0 +1,107 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRateCard() RateCard {
+	return RateCard{
+		Version:  "2024-09",
+		Currency: "ARS",
+		Timezone: "America/Argentina/Buenos_Aires",
+		Rates: []Rate{
+			{Prefix: "+54", PerMinute: "10", IncrementSeconds: 60},
+			{Prefix: "+54911", Days: "weekday", From: "08:00", To: "20:00", PerMinute: "12", IncrementSeconds: 1, MinimumSeconds: 30, ConnectionFee: "0.50"},
+			{Prefix: "+54911", PerMinute: "6", IncrementSeconds: 1},
+			{Prefix: "+1", From: "22:00", To: "06:00", PerMinute: "3", IncrementSeconds: 60},
+		},
+	}
+}
+
+// 2024-09-02 es lunes; las horas son de Buenos Aires (UTC-3).
+func at(day, hour int) time.Time {
+	return time.Date(2024, 9, day, hour+3, 0, 0, 0, time.UTC)
+}
+
+func TestRateCard_Price(t *testing.T) {
+	tests := []struct {
+		name     string
+		receiver string
+		duration int
+		start    time.Time
+		want     string
+	}{
+		{"prefijo más largo en horario pico, por segundo con cargo de conexión", "+5491122223333", 90, at(2, 10), "18.50"},
+		{"mínimo facturable", "+5491122223333", 5, at(2, 10), "6.50"},
+		{"fuera de franja usa la siguiente tarifa del mismo prefijo", "+5491122223333", 90, at(2, 21), "9.00"},
+		{"fin de semana", "+5491122223333", 60, at(7, 10), "6.00"},
+		{"por minuto redondea hacia arriba", "+54 351 444-5555", 61, at(2, 10), "20.00"},
+		{"franja que cruza la medianoche", "+12025550100", 30, at(2, 23), "3.00"},
+	}
+
+	card := testRateCard()
+	assert.NoError(t, card.Validate())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := card.Price(NewIncomingCall{Receiver: tt.receiver, DurationInSec: tt.duration}, tt.start)
+			assert.NoError(t, err)
+			assert.Equal(t, MustParseMoney(tt.want, "ARS"), got)
+		})
+	}
+}
+
+func TestRateCard_PriceWithoutMatchingRate(t *testing.T) {
+	_, err := testRateCard().Price(NewIncomingCall{Receiver: "+12025550100", DurationInSec: 30}, at(2, 12))
+	assert.ErrorIs(t, err, ErrNoRate)
+
+	_, err = testRateCard().Price(NewIncomingCall{Receiver: "+33123456789", DurationInSec: 30}, at(2, 12))
+	assert.ErrorIs(t, err, ErrNoRate)
+}
+
+func TestRateCard_Validate(t *testing.T) {
+	invalid := []Rate{
+		{Prefix: "", PerMinute: "1", IncrementSeconds: 1},
+		{Prefix: "+54", PerMinute: "1", IncrementSeconds: 0},
+		{Prefix: "+54", PerMinute: "abc", IncrementSeconds: 1},
+		{Prefix: "+54", PerMinute: "1", IncrementSeconds: 1, Days: "monday"},
+		{Prefix: "+54", PerMinute: "1", IncrementSeconds: 1, From: "08:00"},
+		{Prefix: "+54", PerMinute: "1", IncrementSeconds: 1, From: "8am", To: "20:00"},
+	}
+	for _, r := range invalid {
+		card := RateCard{Version: "v", Currency: "ARS", Rates: []Rate{r}}
+		assert.Error(t, card.Validate(), "%+v", r)
+	}
+
+	assert.Error(t, RateCard{Currency: "ARS"}.Validate())
+	assert.Error(t, RateCard{Version: "v", Currency: "ARS", Timezone: "Mars/Olympus"}.Validate())
+}
+
+func TestRateCard_PriceByReceiverNumberType(t *testing.T) {
+	card := RateCard{
+		Version:  "2024-10",
+		Currency: "ARS",
+		Rates: []Rate{
+			{Prefix: "+54", PerMinute: "10", IncrementSeconds: 60},
+			{Prefix: "+54", NumberType: NumberTypeTollFree, PerMinute: "0", IncrementSeconds: 60},
+			{Prefix: "+54", NumberType: NumberTypePremium, PerMinute: "50", IncrementSeconds: 60},
+		},
+	}
+	assert.NoError(t, card.Validate())
+
+	price := func(typ NumberType) Money {
+		call := NewIncomingCall{Receiver: "+548003334444", DurationInSec: 60, Numbering: CallNumbering{ReceiverType: typ}}
+		got, err := card.Price(call, at(2, 10))
+		assert.NoError(t, err)
+		return got
+	}
+	assert.Equal(t, MustParseMoney("0", "ARS"), price(NumberTypeTollFree))
+	assert.Equal(t, MustParseMoney("50", "ARS"), price(NumberTypePremium))
+	assert.Equal(t, MustParseMoney("10", "ARS"), price(NumberTypeMobile))
+	assert.Equal(t, MustParseMoney("10", "ARS"), price(""), "sin metadatos usa la tarifa genérica")
+
+	card.Rates[1].NumberType = "satellite"
+	assert.Error(t, card.Validate())
+}
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
+// Full indica un refund total: la llamada queda REFUNDED y no se factura.
+func (r RefundCall) Full() bool {
+	return r.Amount == nil && r.Percent == nil
+}
+
+// ID es RefundID o, si no vino, un hash de call_id, motivo y monto: una
+// reentrega del mismo mensaje no se aplica dos veces.
+func (r RefundCall) ID() string {
+	if r.RefundID != "" {
+		return r.RefundID
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s", r.CallID, r.Reason)
+	if r.Amount != nil {
+		fmt.Fprintf(h, "|%s %s", r.Amount.String(), r.Amount.Currency)
+	}
+	if r.Percent != nil {
+		fmt.Fprintf(h, "|%d%%", *r.Percent)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// Counts indica si el refund se descuenta: no fue anulado ni rechazado.
+func (r RefundCall) Counts() bool {
+	return !r.Reversed && r.Rejected == ""
+}
+
+// AmountOf es lo que devuelve el refund sobre cost. Un monto en otra moneda
+// que la del costo es un error.
+func (r RefundCall) AmountOf(cost Money) (Money, error) {
+	switch {
+	case r.Amount != nil:
+		if r.Amount.Currency != cost.Currency {
+			return Money{}, fmt.Errorf("refund en %s sobre un costo en %s", r.Amount.Currency, cost.Currency)
+		}
+		return *r.Amount, nil
+	case r.Percent != nil:
+		return cost.Prorate(int64(*r.Percent), 100), nil
+	default:
+		return cost, nil
+	}
+}
+
+// CheckRefund decide si se guarda refund dados el costo de la llamada (nil
+// si todavía no se tarifó) y los refunds ya guardados. Devuelve false si el
+// refund ya estaba aplicado (aunque se haya anulado) o la llamada ya tiene
+// un refund total vigente, y un ValidationError si los refunds parciales
+// superarían el costo. Sin costo se aceptan: el neto facturado nunca baja
+// de cero y RecheckRefunds los revisa al tarifar.
+func CheckRefund(refund RefundCall, cost *Money, existing []RefundCall) (bool, error) {
+	v := &ValidationError{Entity: "refund_call"}
+	id := refund.ID()
+	for _, e := range existing {
+		if e.ID() == id {
+			return false, nil
+		}
+	}
+	for _, e := range existing {
+		if e.Full() && e.Counts() {
+			if refund.Full() {
+				return false, nil
+			}
+			v.add(refundAmountField(refund), "la llamada ya tiene un refund total")
+			return false, v
+		}
+	}
+	if refund.Full() || cost == nil {
+		return true, nil
+	}
+
+	total, err := refund.AmountOf(*cost)
+	if err != nil {
+		v.add("amount", "%v", err)
+		return false, v
+	}
+	for _, e := range existing {
+		if !e.Counts() {
+			continue
+		}
+		amount, err := e.AmountOf(*cost)
+		if err != nil {
+			continue
+		}
+		total.Amount += amount.Amount
+	}
+	if total.Amount > cost.Amount {
+		v.add(refundAmountField(refund), "los refunds suman %s y el costo es %s %s", total.String(), cost.String(), cost.Currency)
+		return false, v
+	}
+	return true, nil
+}
+
+// RecheckRefunds vuelve a pasar por CheckRefund, en orden y contra el costo
+// con que se tarifó la llamada, los refunds aceptados cuando todavía no
+// tenía costo. Devuelve los que no entran (otra moneda, o parciales que
+// superan el costo) con Rejected completo.
+func RecheckRefunds(cost Money, refunds []RefundCall) []RefundCall {
+	var accepted, rejected []RefundCall
+	for _, r := range refunds {
+		if r.Counts() {
+			if _, err := CheckRefund(r, &cost, accepted); err != nil {
+				r.Rejected = err.Error()
+				rejected = append(rejected, r)
+				continue
+			}
+		}
+		accepted = append(accepted, r)
+	}
+	return rejected
+}
+
+func refundAmountField(r RefundCall) string {
+	if r.Percent != nil {
+		return "percent"
+	}
+	return "amount"
+}
+
+// RefundStatus es el estado de la llamada después de guardar refund: los
+// refunds totales la dejan REFUNDED; los parciales no cambian el estado. Un
+// placeholder (vigente o EXPIRED) conserva su estado.
+func RefundStatus(refund RefundCall, status string) string {
+	if refund.Full() && status != "REFUND_PARTIALLY" && status != "EXPIRED" {
+		return "REFUNDED"
+	}
+	return status
+}
+
+// FilledStatus es el estado de un placeholder REFUND_PARTIALLY cuando llega
+// la llamada: REFUNDED si tenía un refund total vigente, PENDING (a tarifar)
+// si no.
+func FilledStatus(refunds []RefundCall) string {
+	if hasFullRefund(refunds) {
+		return "REFUNDED"
+	}
+	return "PENDING"
+}
+
+func hasFullRefund(refunds []RefundCall) bool {
+	for _, r := range refunds {
+		if r.Full() && r.Counts() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r RefundCall) String() string {
+	switch {
+	case r.Amount != nil:
+		return fmt.Sprintf("%s %s %s", r.CallID, r.Amount.String(), r.Amount.Currency)
+	case r.Percent != nil:
+		return r.CallID + " " + strconv.Itoa(*r.Percent) + "%"
+	default:
+		return r.CallID + " total"
+	}
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// ID es un hash de call_id y refund_id. El motivo no forma parte de la
+// clave: una reentrega con otro motivo es la misma anulación y no debe
+// quedar pendiente para anular un refund posterior.
+func (v RefundReversal) ID() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s", v.CallID, v.RefundID)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// Matches indica si v anula refund: el de su RefundID o, sin RefundID, el
+// refund total. Un refund ya anulado no se vuelve a anular.
+func (v RefundReversal) Matches(refund RefundCall) bool {
+	return !refund.Reversed && v.refers(refund)
+}
+
+// AlreadyReversed indica si el refund al que apunta v existe y ya fue
+// anulado: la anulación es una reentrega y no debe quedar pendiente.
+func (v RefundReversal) AlreadyReversed(refunds []RefundCall) bool {
+	for _, r := range refunds {
+		if r.Reversed && v.refers(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v RefundReversal) refers(refund RefundCall) bool {
+	if v.RefundID == "" {
+		return refund.Full()
+	}
+	return refund.ID() == v.RefundID
+}
+
+// Target devuelve el índice en refunds del refund que anula v, o -1 si no
+// hay uno vigente. Si además no llegó (ver AlreadyReversed) v queda pendiente y anula al refund cuando
+// llegue (ver PendingReversal).
+func (v RefundReversal) Target(refunds []RefundCall) int {
+	for i, r := range refunds {
+		if v.Matches(r) {
+			return i
+		}
+	}
+	return -1
+}
+
+// PendingReversal devuelve el índice de la anulación pendiente que anula a
+// refund apenas llega, o -1 si no hay ninguna.
+func PendingReversal(refund RefundCall, pending []RefundReversal) int {
+	for i, v := range pending {
+		if v.Matches(refund) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ReversedStatus es el estado de la llamada después de anular uno de sus
+// refunds (refunds ya lo tiene marcado). Si no le queda un refund total
+// vigente, una llamada REFUNDED vuelve a prior: el estado que tenía o habría
+// tenido sin el refund (PENDING si no se conoce). Los demás estados no
+// cambian: un placeholder REFUND_PARTIALLY se resuelve al llegar la llamada.
+func ReversedStatus(status, prior string, refunds []RefundCall) string {
+	if status != "REFUNDED" || hasFullRefund(refunds) {
+		return status
+	}
+	if prior == "" {
+		return "PENDING"
+	}
+	return prior
+}
+
+// StatusBeforeRefund es el estado a recordar al pasar de status a next por
+// un refund total, para restaurarlo si se anula. Vacío si no cambia.
+func StatusBeforeRefund(status, next string) string {
+	if next == "REFUNDED" && status != "REFUNDED" {
+		return status
+	}
+	return ""
+}
+
+func (v RefundReversal) String() string {
+	if v.RefundID == "" {
+		return v.CallID + " refund total"
+	}
+	return v.CallID + " refund " + v.RefundID
+}
@@ -0,0 +1,223 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const refundCallID = "550e8400-e29b-41d4-a716-446655440000"
+
+func partialAmount(id, amount string) RefundCall {
+	m := MustParseMoney(amount, "ARS")
+	return RefundCall{CallID: refundCallID, RefundID: id, Amount: &m}
+}
+
+func partialPercent(id string, p int) RefundCall {
+	return RefundCall{CallID: refundCallID, RefundID: id, Percent: &p}
+}
+
+func TestRefundCall_ID(t *testing.T) {
+	full := RefundCall{CallID: refundCallID, Reason: "reclamo"}
+	assert.Equal(t, full.ID(), RefundCall{CallID: refundCallID, Reason: "reclamo"}.ID(), "la reentrega tiene el mismo id")
+	assert.NotEqual(t, full.ID(), partialAmount("", "2.50").ID())
+	assert.NotEqual(t, partialAmount("", "2.50").ID(), partialAmount("", "2.51").ID())
+	assert.Equal(t, "r1", partialAmount("r1", "2.50").ID())
+}
+
+func TestRefundCall_AmountOf(t *testing.T) {
+	cost := MustParseMoney("8.50", "ARS")
+
+	amount, err := partialPercent("", 33).AmountOf(cost)
+	assert.NoError(t, err)
+	assert.Equal(t, MustParseMoney("2.81", "ARS"), amount)
+
+	amount, err = RefundCall{CallID: refundCallID}.AmountOf(cost)
+	assert.NoError(t, err)
+	assert.Equal(t, cost, amount)
+
+	_, err = partialAmount("", "1.00").AmountOf(MustParseMoney("8.50", "USD"))
+	assert.Error(t, err)
+}
+
+func TestCheckRefund(t *testing.T) {
+	cost := MustParseMoney("5.00", "ARS")
+	existing := []RefundCall{partialAmount("r1", "2.50"), partialPercent("r2", 40)}
+
+	ok, err := CheckRefund(partialAmount("r3", "0.50"), &cost, existing)
+	assert.NoError(t, err)
+	assert.True(t, ok, "2.50 + 2.00 + 0.50 llega justo al costo")
+
+	ok, err = CheckRefund(partialAmount("r1", "2.50"), &cost, existing)
+	assert.NoError(t, err)
+	assert.False(t, ok, "un refund repetido no se aplica dos veces")
+
+	_, err = CheckRefund(partialAmount("r3", "0.51"), &cost, existing)
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, "amount", verr.Fields[0].Field)
+	}
+
+	_, err = CheckRefund(partialPercent("r3", 11), &cost, existing)
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, "percent", verr.Fields[0].Field)
+	}
+
+	ok, err = CheckRefund(partialAmount("r3", "9.00"), nil, existing)
+	assert.NoError(t, err)
+	assert.True(t, ok, "sin costo todavía no se puede validar el tope")
+}
+
+func TestCheckRefund_AfterFullRefund(t *testing.T) {
+	cost := MustParseMoney("5.00", "ARS")
+	existing := []RefundCall{{CallID: refundCallID, Reason: "reclamo"}}
+
+	ok, err := CheckRefund(RefundCall{CallID: refundCallID, Reason: "otro"}, &cost, existing)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = CheckRefund(partialAmount("r1", "1.00"), &cost, existing)
+	assert.True(t, IsPermanent(err))
+}
+
+func TestRefundStatus(t *testing.T) {
+	full := RefundCall{CallID: refundCallID}
+	assert.Equal(t, "REFUNDED", RefundStatus(full, "OK"))
+	assert.Equal(t, "REFUND_PARTIALLY", RefundStatus(full, "REFUND_PARTIALLY"))
+	assert.Equal(t, "OK", RefundStatus(partialPercent("r1", 10), "OK"))
+	assert.Equal(t, "EXPIRED", RefundStatus(full, "EXPIRED"))
+
+	assert.Equal(t, "PENDING", FilledStatus([]RefundCall{partialPercent("r1", 10)}))
+	assert.Equal(t, "REFUNDED", FilledStatus([]RefundCall{partialPercent("r1", 10), full}))
+}
+
+func TestBilledCall_NetCost(t *testing.T) {
+	call := BilledCall{
+		Status:  "OK",
+		Cost:    MustParseMoney("10.00", "ARS"),
+		Refunds: []RefundCall{partialAmount("r1", "2.00"), partialPercent("r2", 10)},
+		Credit:  &QualityCredit{Percent: 25},
+	}
+	assertNet(t, MustParseMoney("4.50", "ARS"), call)
+
+	call.Refunds = append(call.Refunds, partialAmount("r3", "5.00"))
+	assertNet(t, MustParseMoney("0.00", "ARS"), call, "el neto no baja de cero")
+
+	call.Refunds[2].Rejected = "no entra en el costo"
+	assertNet(t, MustParseMoney("4.50", "ARS"), call, "un refund rechazado no cuenta")
+
+	usd := MustParseMoney("1.00", "USD")
+	call.Refunds = append(call.Refunds, RefundCall{CallID: refundCallID, RefundID: "r4", Amount: &usd})
+	_, err := call.NetCost()
+	assert.Error(t, err, "un refund vigente en otra moneda no se ignora")
+
+	call.Status = "REFUNDED"
+	call.Refunds, call.Credit = nil, nil
+	assertNet(t, MustParseMoney("0.00", "ARS"), call)
+}
+
+func assertNet(t *testing.T, want Money, call BilledCall, msgAndArgs ...interface{}) {
+	t.Helper()
+	net, err := call.NetCost()
+	assert.NoError(t, err, msgAndArgs...)
+	assert.Equal(t, want, net, msgAndArgs...)
+}
+
+func TestRecheckRefunds(t *testing.T) {
+	usd := MustParseMoney("1.00", "USD")
+	reversed := partialAmount("r4", "9.00")
+	reversed.Reversed = true
+	refunds := []RefundCall{
+		{CallID: refundCallID, RefundID: "r1", Amount: &usd},
+		partialAmount("r2", "3.00"),
+		reversed,
+		partialPercent("r3", 50),
+		partialAmount("r5", "0.50"),
+	}
+
+	rejected := RecheckRefunds(MustParseMoney("5.00", "ARS"), refunds)
+
+	var ids []string
+	for _, r := range rejected {
+		assert.NotEmpty(t, r.Rejected, r.RefundID)
+		ids = append(ids, r.RefundID)
+	}
+	assert.Equal(t, []string{"r1", "r3"}, ids, "otra moneda y el parcial que supera el costo")
+}
+
+func TestMoney_Prorate(t *testing.T) {
+	assert.Equal(t, MustParseMoney("4.25", "ARS"), MustParseMoney("8.50", "ARS").Prorate(1, 2))
+	assert.Equal(t, MustParseMoney("0.33", "USD"), MustParseMoney("1.00", "USD").Prorate(1, 3))
+	assert.Equal(t, MustParseMoney("333", "JPY"), MustParseMoney("1000", "JPY").Prorate(1, 3))
+}
+
+func TestRefundReversal_Target(t *testing.T) {
+	full := RefundCall{CallID: refundCallID, Reason: "reclamo"}
+	refunds := []RefundCall{partialAmount("r1", "1.00"), full}
+
+	assert.Equal(t, 1, RefundReversal{CallID: refundCallID}.Target(refunds), "sin refund_id anula el total")
+	assert.Equal(t, 0, RefundReversal{CallID: refundCallID, RefundID: "r1"}.Target(refunds))
+	assert.Equal(t, -1, RefundReversal{CallID: refundCallID, RefundID: "r2"}.Target(refunds))
+
+	refunds[1].Reversed = true
+	assert.Equal(t, -1, RefundReversal{CallID: refundCallID}.Target(refunds), "un refund anulado no se vuelve a anular")
+	assert.Equal(t, 1, PendingReversal(partialAmount("r1", "1.00"), []RefundReversal{{RefundID: "r2"}, {RefundID: "r1"}}))
+
+	assert.True(t, RefundReversal{CallID: refundCallID}.AlreadyReversed(refunds))
+	assert.False(t, RefundReversal{CallID: refundCallID, RefundID: "r1"}.AlreadyReversed(refunds))
+	assert.False(t, RefundReversal{CallID: refundCallID, RefundID: "r2"}.AlreadyReversed(refunds), "sin refund queda pendiente")
+}
+
+func TestRefundReversal_ID(t *testing.T) {
+	first := RefundReversal{CallID: refundCallID, RefundID: "r1", Reason: "refund por error"}
+	resend := RefundReversal{CallID: refundCallID, RefundID: "r1", Reason: "refund duplicado"}
+	assert.Equal(t, first.ID(), resend.ID(), "el motivo no forma parte de la clave")
+	assert.NotEqual(t, first.ID(), RefundReversal{CallID: refundCallID, RefundID: "r2"}.ID())
+}
+
+func TestReversedStatus(t *testing.T) {
+	reversed := []RefundCall{{CallID: refundCallID, Reversed: true}}
+	assert.Equal(t, "OK", ReversedStatus("REFUNDED", "OK", reversed))
+	assert.Equal(t, "ERROR", ReversedStatus("REFUNDED", "ERROR", reversed))
+	assert.Equal(t, "PENDING", ReversedStatus("REFUNDED", "", reversed))
+	assert.Equal(t, "REFUND_PARTIALLY", ReversedStatus("REFUND_PARTIALLY", "", reversed))
+
+	stillRefunded := append(reversed, RefundCall{CallID: refundCallID, Reason: "otro"})
+	assert.Equal(t, "REFUNDED", ReversedStatus("REFUNDED", "OK", stillRefunded))
+
+	assert.Equal(t, "OK", StatusBeforeRefund("OK", "REFUNDED"))
+	assert.Equal(t, "", StatusBeforeRefund("REFUND_PARTIALLY", "REFUND_PARTIALLY"))
+}
+
+func TestCostAdjustment(t *testing.T) {
+	adj := CostAdjustment{CallID: refundCallID, Cost: MustParseMoney("7.00", "USD"), Reason: "descuento contractual", Actor: "finanzas@telco"}
+	assert.NoError(t, adj.Validate())
+	assert.True(t, adj.Same(CostAdjustment{CallID: refundCallID, Cost: MustParseMoney("7", "USD"), Reason: "descuento contractual", Actor: "finanzas@telco"}))
+	assert.False(t, adj.Same(CostAdjustment{CallID: refundCallID, Cost: MustParseMoney("7.00", "ARS"), Reason: "descuento contractual", Actor: "finanzas@telco"}))
+
+	var verr *ValidationError
+	if assert.ErrorAs(t, CostAdjustment{CallID: refundCallID, Cost: MustParseMoney("-1", "XXY")}.Validate(), &verr) {
+		fields := make([]string, len(verr.Fields))
+		for i, f := range verr.Fields {
+			fields[i] = f.Field
+		}
+		assert.Equal(t, []string{"currency", "cost", "reason", "actor"}, fields)
+	}
+	kwd := adj
+	kwd.Cost = MustParseMoney("1.234", "KWD")
+	assert.EqualError(t, kwd.Validate(), "call_cost_adjusted inválido: cost: la moneda KWD usa 3 decimales y la columna admite 2")
+
+	refunds := []RefundCall{partialAmount("r1", "1.00"), partialPercent("r2", 10)}
+	assert.EqualError(t, adj.CheckRefunds(refunds), "call_cost_adjusted inválido: currency: la llamada tiene el refund r1 en ARS")
+	refunds[0].Reversed = true
+	assert.NoError(t, adj.CheckRefunds(refunds), "los refunds anulados y porcentuales no tienen moneda")
+
+	call := BilledCall{
+		Status:     "OK",
+		Cost:       MustParseMoney("10.00", "ARS"),
+		Refunds:    []RefundCall{partialPercent("r1", 10)},
+		Adjustment: &CostAdjustment{Cost: MustParseMoney("6.00", "ARS")},
+	}
+	assert.Equal(t, MustParseMoney("6.00", "ARS"), call.BilledCost())
+	assertNet(t, MustParseMoney("5.40", "ARS"), call, "el refund porcentual se calcula sobre el ajustado")
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// BilledCall es una llamada facturada tal como la leen los reportes.
+type BilledCall struct {
+	CallID         string
+	Caller         string
+	StartTimestamp time.Time
+	Status         string
+	Cost           Money
+	BaseCost       *Money
+	Provider       string
+	Breakdown      CostBreakdown
+	Numbering      CallNumbering
+	// Credit es el crédito por calidad, si lo hay; se descuenta de Cost.
+	Credit *QualityCredit
+	// Refunds son los refunds guardados de la llamada, incluidos los
+	// anulados (Reversed) y rechazados (Rejected); Cost es siempre el costo
+	// original.
+	Refunds []RefundCall
+	// Adjustment es el ajuste manual del costo, si lo hay. Cost y BaseCost
+	// siguen siendo los del proveedor.
+	Adjustment *CostAdjustment
+}
+
+// BilledCost es el costo a facturar antes de refunds y créditos: el ajustado
+// si lo hay, si no el del proveedor.
+func (b BilledCall) BilledCost() Money {
+	if b.Adjustment != nil {
+		return b.Adjustment.Cost
+	}
+	return b.Cost
+}
+
+// NetCost es lo facturado: BilledCost menos refunds vigentes y crédito por
+// calidad, sin bajar de cero. Una llamada REFUNDED no se factura. Un refund
+// vigente que no se puede descontar (p. ej. en otra moneda) es un error: no
+// se factura como si no existiera.
+func (b BilledCall) NetCost() (Money, error) {
+	cost := b.BilledCost()
+	net := NewMoney(0, cost.Currency)
+	if b.Status == "REFUNDED" {
+		return net, nil
+	}
+	net.Amount = cost.Amount
+	for _, r := range b.Refunds {
+		if !r.Counts() {
+			continue
+		}
+		amount, err := r.AmountOf(cost)
+		if err != nil {
+			return Money{}, fmt.Errorf("refund %s: %w", r.ID(), err)
+		}
+		net.Amount -= amount.Amount
+	}
+	if b.Credit != nil {
+		net.Amount -= b.Credit.Amount(cost).Amount
+	}
+	if net.Amount < 0 {
+		net.Amount = 0
+	}
+	return net, nil
+}
+
+// CallerTotal es el total facturado a un caller en la moneda del reporte.
+// ProviderTotal es lo que cobraron los proveedores por las mismas llamadas,
+// sin ajustes manuales, refunds ni créditos.
+type CallerTotal struct {
+	Caller        string
+	Calls         int
+	Adjusted      int
+	Total         Money
+	ProviderTotal Money
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RoutingTable elige qué proveedores de costo consultar para una llamada.
+// Gana la primera regla que coincide; si ninguna coincide se usa Default.
+// Los proveedores de cada lista se prueban en orden (failover).
+type RoutingTable struct {
+	// Timezone en la que se evalúan las franjas horarias (por defecto UTC).
+	Timezone string        `json:"timezone,omitempty"`
+	Rules    []RoutingRule `json:"rules"`
+	Default  []string      `json:"default"`
+}
+
+// RoutingRule coincide si se cumplen todos los criterios informados: prefijo
+// del Receiver, prefijo del Caller (cuenta) y franja horaria del inicio.
+type RoutingRule struct {
+	Name             string   `json:"name"`
+	ReceiverPrefixes []string `json:"receiver_prefixes,omitempty"`
+	CallerPrefixes   []string `json:"caller_prefixes,omitempty"`
+	Days             string   `json:"days,omitempty"`
+	From             string   `json:"from,omitempty"`
+	To               string   `json:"to,omitempty"`
+	Providers        []string `json:"providers"`
+}
+
+func (t RoutingTable) Validate() error {
+	if _, err := t.location(); err != nil {
+		return err
+	}
+	if len(t.Default) == 0 {
+		return errors.New("ruteo sin proveedores por defecto")
+	}
+	for i, r := range t.Rules {
+		if len(r.Providers) == 0 {
+			return fmt.Errorf("regla %d (%s) sin proveedores", i, r.Name)
+		}
+		if err := validateBand(r.Days, r.From, r.To); err != nil {
+			return fmt.Errorf("regla %d (%s): %w", i, r.Name, err)
+		}
+	}
+	return nil
+}
+
+// ProviderNames devuelve los proveedores configurados, sin repetir.
+func (t RoutingTable) ProviderNames() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, list := range append([][]string{t.Default}, t.providerLists()...) {
+		for _, n := range list {
+			if !seen[n] {
+				seen[n] = true
+				names = append(names, n)
+			}
+		}
+	}
+	return names
+}
+
+// Route devuelve el nombre de la regla aplicada ("default" si ninguna) y los
+// proveedores en orden de prioridad.
+func (t RoutingTable) Route(call NewIncomingCall, start time.Time) (string, []string) {
+	loc, err := t.location()
+	if err != nil {
+		loc = time.UTC
+	}
+	local := start.In(loc)
+	for _, r := range t.Rules {
+		if r.matches(call, local) {
+			return r.Name, r.Providers
+		}
+	}
+	return "default", t.Default
+}
+
+func (t RoutingTable) providerLists() [][]string {
+	lists := make([][]string, 0, len(t.Rules))
+	for _, r := range t.Rules {
+		lists = append(lists, r.Providers)
+	}
+	return lists
+}
+
+func (t RoutingTable) location() (*time.Location, error) {
+	if t.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(t.Timezone)
+}
+
+func (r RoutingRule) matches(call NewIncomingCall, local time.Time) bool {
+	return hasAnyPrefix(call.Receiver, r.ReceiverPrefixes) &&
+		hasAnyPrefix(call.Caller, r.CallerPrefixes) &&
+		inBand(r.Days, r.From, r.To, local)
+}
+
+// Una lista vacía no restringe.
+func hasAnyPrefix(number string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	n := digits(number)
+	for _, p := range prefixes {
+		if strings.HasPrefix(n, digits(p)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutingTable_Route(t *testing.T) {
+	table := RoutingTable{
+		Timezone: "America/Argentina/Buenos_Aires",
+		Rules: []RoutingRule{
+			{Name: "cuenta-mayorista", CallerPrefixes: []string{"+5411000"}, Providers: []string{"carrier_c"}},
+			{Name: "movil-nocturno", ReceiverPrefixes: []string{"+54911"}, From: "22:00", To: "06:00", Providers: []string{"carrier_b", "carrier_a"}},
+			{Name: "internacional", ReceiverPrefixes: []string{"+1", "+44"}, Providers: []string{"carrier_b"}},
+		},
+		Default: []string{"carrier_a", "local"},
+	}
+	assert.NoError(t, table.Validate())
+
+	night := time.Date(2024, 9, 3, 2, 0, 0, 0, time.UTC) // 23:00 en Buenos Aires
+	day := time.Date(2024, 9, 3, 15, 0, 0, 0, time.UTC)  // 12:00 en Buenos Aires
+
+	tests := []struct {
+		caller, receiver string
+		start            time.Time
+		wantRule         string
+		wantProviders    []string
+	}{
+		{"+541100012345", "+12025550100", day, "cuenta-mayorista", []string{"carrier_c"}},
+		{"+5491155555555", "+5491122223333", night, "movil-nocturno", []string{"carrier_b", "carrier_a"}},
+		{"+5491155555555", "+5491122223333", day, "default", []string{"carrier_a", "local"}},
+		{"+5491155555555", "+44 20 7946 0000", day, "internacional", []string{"carrier_b"}},
+	}
+	for _, tt := range tests {
+		rule, providers := table.Route(NewIncomingCall{Caller: tt.caller, Receiver: tt.receiver}, tt.start)
+		assert.Equal(t, tt.wantRule, rule, tt.receiver)
+		assert.Equal(t, tt.wantProviders, providers, tt.receiver)
+	}
+
+	assert.Equal(t, []string{"carrier_a", "local", "carrier_c", "carrier_b"}, table.ProviderNames())
+}
+
+func TestRoutingTable_Validate(t *testing.T) {
+	assert.Error(t, RoutingTable{}.Validate())
+	assert.Error(t, RoutingTable{Default: []string{"a"}, Rules: []RoutingRule{{Name: "x"}}}.Validate())
+	assert.Error(t, RoutingTable{Default: []string{"a"}, Rules: []RoutingRule{{Name: "x", Providers: []string{"a"}, From: "22:00"}}}.Validate())
+	assert.Error(t, RoutingTable{Default: []string{"a"}, Timezone: "Mars/Olympus"}.Validate())
+}
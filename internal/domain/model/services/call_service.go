@@ -3,6 +3,8 @@ package services
 import (
 	"errors"
 	"log"
+	"sync"
+	"time"
 
 	"phonecall-cost-processor-service/internal/domain/model"
 	"phonecall-cost-processor-service/internal/domain/port/client"
@@ -13,23 +15,84 @@ type ICallService interface {
 	Process(call model.NewIncomingCall) error
 }
 
+// CallProcessingRepository son los puertos que usa el procesamiento de una
+// llamada entrante: lectura de estado, alta y resultado del costo.
+type CallProcessingRepository interface {
+	repository.CallReader
+	repository.CallWriter
+	repository.CostResultWriter
+}
+
 type CallService struct {
-	repo       repository.CallRepository
+	repo       CallProcessingRepository
 	costClient client.CostClient
+	fx         *FXConverter
+	phones     *model.PhoneNormalizer
+	shadow     *shadowPricing
+}
+
+type shadowPricing struct {
+	name   string
+	client client.CostClient
+	store  repository.ShadowCostWriter
+}
+
+type CallServiceOption func(*CallService)
+
+// WithFXNormalization guarda junto a cada costo su equivalente en la moneda
+// base, con la cotización vigente en el start_timestamp de la llamada.
+func WithFXNormalization(fx *FXConverter) CallServiceOption {
+	return func(s *CallService) { s.fx = fx }
+}
+
+// WithPhoneNormalization guarda caller y receiver en E.164 junto con su país y
+// tipo de número. Un número imposible rechaza la llamada.
+func WithPhoneNormalization(phones *model.PhoneNormalizer) CallServiceOption {
+	return func(s *CallService) { s.phones = phones }
+}
+
+// WithShadowPricing consulta además a costClient en paralelo con el proveedor
+// real y guarda su resultado en store, sin afectar la facturación.
+func WithShadowPricing(name string, costClient client.CostClient, store repository.ShadowCostWriter) CallServiceOption {
+	return func(s *CallService) {
+		s.shadow = &shadowPricing{name: name, client: costClient, store: store}
+	}
 }
 
-func NewCallService(repo repository.CallRepository, costClient client.CostClient) ICallService {
-	return &CallService{repo: repo, costClient: costClient}
+func NewCallService(repo CallProcessingRepository, costClient client.CostClient, opts ...CallServiceOption) ICallService {
+	s := &CallService{repo: repo, costClient: costClient}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *CallService) Process(call model.NewIncomingCall) error {
+	if s.phones != nil {
+		normalized, err := s.phones.NormalizeCall(call)
+		if err != nil {
+			return err
+		}
+		call = normalized
+	}
+
 	status, err := s.repo.GetCallStatus(call.CallID)
 	if err != nil {
 		return err
 	}
 	if status == "REFUND_PARTIALLY" {
 		log.Printf("🔄 Completando datos de llamada previamente refund call_id=%s", call.CallID)
-		return s.repo.FillMissingCallData(call)
+		if err := s.repo.FillMissingCallData(call); err != nil {
+			return err
+		}
+		// Se tarifa igual si quedó REFUNDED: si el refund se anula, la
+		// llamada recupera su costo
+		if status, err = s.repo.GetCallStatus(call.CallID); err != nil || (status != "PENDING" && status != "REFUNDED") {
+			return err
+		}
+	} else if status == "EXPIRED" {
+		log.Printf("⚠️ Llamada descartada call_id=%s: su placeholder de refund ya había expirado", call.CallID)
+		return nil
 	} else if status != "" {
 		log.Printf("ℹ️ Llamada duplicada descartada call_id=%s con estado=%s", call.CallID, status)
 		return nil
@@ -39,6 +102,16 @@ func (s *CallService) Process(call model.NewIncomingCall) error {
 		return err
 	}
 
+	if s.shadow != nil {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.shadow.price(call.CallID)
+		}()
+		defer wg.Wait()
+	}
+
 		costResp, err := s.costClient.GetCallCost(call.CallID)
 	if err != nil {
 		var apiErr *client.CostAPIError
@@ -50,7 +123,57 @@ func (s *CallService) Process(call model.NewIncomingCall) error {
 		log.Printf("⚠️ Error obteniendo costo para call_id=%s: %v", call.CallID, err)
 		return s.repo.MarkCostAsFailed(call.CallID)
 	}
+	// Una respuesta inválida es un error del proveedor: la llamada queda en ERROR.
+	// También un costo que no entra en la columna (p. ej. una moneda de 3
+	// decimales): reintentar no lo cambia.
+	if err := costResp.Validate(); err != nil {
+		log.Printf("⚠️ Respuesta inválida del proveedor de costo para call_id=%s: %v", call.CallID, err)
+		return s.repo.MarkCostAsFailed(call.CallID)
+	}
+	if err := costResp.Cost.CheckPrecision(repository.CostPrecision, repository.CostScale); err != nil {
+		log.Printf("⚠️ Costo no almacenable para call_id=%s: %v", call.CallID, err)
+		return s.repo.MarkCostAsFailed(call.CallID)
+	}
 
 
-	return s.repo.UpdateCallCost(call.CallID, costResp.Cost, costResp.Currency)
+	return s.repo.UpdateCallCost(call.CallID, s.costResult(call, costResp))
+}
+
+func (s *CallService) costResult(call model.NewIncomingCall, resp *model.CostResponse) model.CostResult {
+	cost := resp.Cost
+	result := model.CostResult{Cost: cost, Provider: resp.Provider, Breakdown: resp.Breakdown}
+	if s.fx == nil {
+		return result
+	}
+
+	start := call.StartTimestamp
+	if start.IsZero() {
+		start = time.Now()
+	}
+	base, err := s.fx.ToBase(cost, start)
+	if err != nil {
+		log.Printf("⚠️ Sin cotización %s/%s para call_id=%s: %v", cost.Currency, s.fx.BaseCurrency(), call.CallID, err)
+		return result
+	}
+	if err := base.CheckPrecision(repository.CostPrecision, repository.CostScale); err != nil {
+		log.Printf("⚠️ Costo base no almacenable para call_id=%s: %v", call.CallID, err)
+		return result
+	}
+	result.BaseCost = &base
+	return result
+}
+
+// Los errores del proveedor en sombra se guardan y se loguean, nunca se propagan.
+func (p *shadowPricing) price(callID string) {
+	shadow := model.ShadowCost{Provider: p.name}
+	resp, err := p.client.GetCallCost(callID)
+	if err != nil {
+		shadow.Error = err.Error()
+	} else {
+		shadow.Cost = &resp.Cost
+	}
+
+	if err := p.store.SaveShadowCost(callID, shadow); err != nil {
+		log.Printf("⚠️ Error guardando costo en sombra (%s) call_id=%s: %v", p.name, callID, err)
+	}
 }
@@ -2,14 +2,16 @@ package services
 
 import (
 	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"phonecall-cost-processor-service/internal/domain/model"
 	"phonecall-cost-processor-service/internal/domain/port/client"
 )
 
 // Mocks
-// mockRepo implementa repository.CallRepository
+// mockRepo implementa CallProcessingRepository
 type mockRepo struct {
 	SaveErr    error
 	SaveCalled bool
@@ -22,8 +24,7 @@ type mockRepo struct {
 	UpdateErr           error
 	UpdateCalled        bool
 	UpdateInputID       string
-	UpdateInputCost     float64
-	UpdateInputCur      string
+	UpdateInputCost     model.CostResult
 	GetCallStatusOutput string
 	GetCallStatusErr    error
 	FillCalled          bool
@@ -69,18 +70,13 @@ func (m *mockRepo) MarkCostAsFailed(callID string) error {
 	return m.MarkFailedErr
 }
 
-func (m *mockRepo) UpdateCallCost(callID string, cost float64, currency string) error {
+func (m *mockRepo) UpdateCallCost(callID string, result model.CostResult) error {
 	m.UpdateCalled = true
 	m.UpdateInputID = callID
-	m.UpdateInputCost = cost
-	m.UpdateInputCur = currency
+	m.UpdateInputCost = result
 	return m.UpdateErr
 }
 
-func (m *mockRepo) ApplyRefund(refund model.RefundCall) error {
-	return nil
-}
-
 type mockClient struct {
 	GetErr      error
 	Called      bool
@@ -94,6 +90,10 @@ func (m *mockClient) GetCallCost(callID string) (*model.CostResponse, error) {
 	return m.Resp, m.GetErr
 }
 
+func (m *mockClient) GetCallCosts(callIDs []string) (map[string]client.BatchItem, error) {
+	return client.GetEach(m.GetCallCost, callIDs), nil
+}
+
 func TestProcess_SaveError(t *testing.T) {
 	repo := &mockRepo{SaveErr: errors.New("save failed")}
 	client := &mockClient{}
@@ -150,7 +150,7 @@ func TestProcess_CostError_MarkFailedError(t *testing.T) {
 
 func TestProcess_Success(t *testing.T) {
 	repo := &mockRepo{}
-	client := &mockClient{Resp: &model.CostResponse{Cost: 9.99, Currency: "USD"}}
+	client := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("9.99", "USD")}}
 	svc := NewCallService(repo, client)
 	call := model.NewIncomingCall{CallID: "id4"}
 
@@ -167,14 +167,14 @@ func TestProcess_Success(t *testing.T) {
 	if !repo.UpdateCalled {
 		t.Error("UpdateCallCost should be called")
 	}
-	if repo.UpdateInputID != "id4" || repo.UpdateInputCost != 9.99 || repo.UpdateInputCur != "USD" {
-		t.Errorf("UpdateCallCost called with wrong args: %v, %v, %v", repo.UpdateInputID, repo.UpdateInputCost, repo.UpdateInputCur)
+	if repo.UpdateInputID != "id4" || repo.UpdateInputCost.Cost != model.NewMoney(999, "USD") {
+		t.Errorf("UpdateCallCost called with wrong args: %v, %v", repo.UpdateInputID, repo.UpdateInputCost)
 	}
 }
 
 func TestProcess_UpdateError(t *testing.T) {
 	repo := &mockRepo{UpdateErr: errors.New("update error")}
-	client := &mockClient{Resp: &model.CostResponse{Cost: 1.23, Currency: "EUR"}}
+	client := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("1.23", "EUR")}}
 	svc := NewCallService(repo, client)
 	call := model.NewIncomingCall{CallID: "id5"}
 
@@ -205,6 +205,55 @@ func TestProcess_RefundedCall_SkipsProcessing(t *testing.T) {
 	}
 }
 
+func TestProcess_RefundPartially_OnlyPartialRefunds_PricesCall(t *testing.T) {
+	repo := &mockRepo{
+		GetCallStatusOutput: "REFUND_PARTIALLY",
+	}
+	repo.FillFunc = func(call model.NewIncomingCall) error {
+		repo.GetCallStatusOutput = "PENDING"
+		return nil
+	}
+	client := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("8.50", "ARS")}}
+	svc := NewCallService(repo, client)
+
+	call := model.NewIncomingCall{CallID: "id_partial"}
+	err := svc.Process(call)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !repo.FillCalled {
+		t.Error("FillMissingCallData should be called for refunded call")
+	}
+	if !client.Called {
+		t.Error("GetCallCost should be called when only partial refunds exist")
+	}
+	if !repo.UpdateCalled || repo.UpdateInputCost.Cost != model.MustParseMoney("8.50", "ARS") {
+		t.Errorf("expected cost 8.50 ARS, got %+v", repo.UpdateInputCost)
+	}
+}
+
+func TestProcess_RefundPartially_FullRefund_PricesCallForReversal(t *testing.T) {
+	repo := &mockRepo{
+		GetCallStatusOutput: "REFUND_PARTIALLY",
+	}
+	repo.FillFunc = func(call model.NewIncomingCall) error {
+		repo.GetCallStatusOutput = "REFUNDED"
+		return nil
+	}
+	client := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("8.50", "ARS")}}
+	svc := NewCallService(repo, client)
+
+	err := svc.Process(model.NewIncomingCall{CallID: "id_refunded"})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !client.Called || !repo.UpdateCalled {
+		t.Error("a refunded call should still be priced so the refund can be reversed")
+	}
+}
+
 func TestProcess_DuplicatedCall_Discarded(t *testing.T) {
 	repo := &mockRepo{
 		GetCallStatusOutput: "PROCESSED",
@@ -305,3 +354,186 @@ func TestProcess_RefundPartially_CompletesData(t *testing.T) {
 		t.Error("GetCallCost should not be called for REFUND_PARTIALLY call")
 	}
 }
+
+func TestProcess_WithFXNormalization_StoresBaseCost(t *testing.T) {
+	repo := &mockRepo{}
+	client := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("1850", "ARS")}}
+	fx := NewFXConverter(mockRates{"USDARS": rate("USD", "ARS", "925")}, "USD")
+	svc := NewCallService(repo, client, WithFXNormalization(fx))
+
+	err := svc.Process(model.NewIncomingCall{CallID: "id_fx", StartTimestamp: time.Date(2024, 8, 29, 12, 0, 0, 0, time.UTC)})
+
+	if err != nil {
+		t.Fatalf("did not expect error, got %v", err)
+	}
+	if repo.UpdateInputCost.Cost != model.MustParseMoney("1850", "ARS") {
+		t.Errorf("expected original cost to be kept, got %v", repo.UpdateInputCost.Cost)
+	}
+	if repo.UpdateInputCost.BaseCost == nil || *repo.UpdateInputCost.BaseCost != model.MustParseMoney("2", "USD") {
+		t.Errorf("expected base cost 2 USD, got %v", repo.UpdateInputCost.BaseCost)
+	}
+}
+
+func TestProcess_WithFXNormalization_MissingRateStillUpdates(t *testing.T) {
+	repo := &mockRepo{}
+	client := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("3", "EUR")}}
+	svc := NewCallService(repo, client, WithFXNormalization(NewFXConverter(mockRates{}, "USD")))
+
+	if err := svc.Process(model.NewIncomingCall{CallID: "id_fx", StartTimestamp: time.Date(2024, 8, 29, 12, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatalf("did not expect error, got %v", err)
+	}
+	if !repo.UpdateCalled || repo.UpdateInputCost.BaseCost != nil {
+		t.Errorf("expected update without base cost, got %+v", repo.UpdateInputCost)
+	}
+}
+
+type mockShadowStore struct {
+	saved map[string]model.ShadowCost
+}
+
+func (m *mockShadowStore) SaveShadowCost(callID string, shadow model.ShadowCost) error {
+	if m.saved == nil {
+		m.saved = map[string]model.ShadowCost{}
+	}
+	m.saved[callID] = shadow
+	return nil
+}
+
+func TestProcess_ShadowPricingDoesNotAffectBilling(t *testing.T) {
+	repo := &mockRepo{}
+	primary := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("9.99", "USD")}}
+	shadow := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("10.50", "USD")}}
+	store := &mockShadowStore{}
+	svc := NewCallService(repo, primary, WithShadowPricing("local", shadow, store))
+
+	if err := svc.Process(model.NewIncomingCall{CallID: "id_shadow"}); err != nil {
+		t.Fatalf("did not expect error, got %v", err)
+	}
+	if repo.UpdateInputCost.Cost != model.MustParseMoney("9.99", "USD") {
+		t.Errorf("billing should use the primary cost, got %v", repo.UpdateInputCost.Cost)
+	}
+	got := store.saved["id_shadow"]
+	if got.Provider != "local" || got.Cost == nil || *got.Cost != model.MustParseMoney("10.50", "USD") {
+		t.Errorf("unexpected shadow cost: %+v", got)
+	}
+}
+
+func TestProcess_ShadowPricingErrorIsStored(t *testing.T) {
+	repo := &mockRepo{}
+	primary := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("1", "USD")}}
+	shadow := &mockClient{GetErr: errors.New("sin tarifa")}
+	store := &mockShadowStore{}
+	svc := NewCallService(repo, primary, WithShadowPricing("local", shadow, store))
+
+	if err := svc.Process(model.NewIncomingCall{CallID: "id_shadow_err"}); err != nil {
+		t.Fatalf("shadow errors must not fail processing, got %v", err)
+	}
+	if !repo.UpdateCalled {
+		t.Error("UpdateCallCost should be called")
+	}
+	if got := store.saved["id_shadow_err"]; got.Cost != nil || got.Error != "sin tarifa" {
+		t.Errorf("unexpected shadow cost: %+v", got)
+	}
+}
+
+func TestProcess_RecordsProvider(t *testing.T) {
+	repo := &mockRepo{}
+	client := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("1", "USD"), Provider: "carrier_b"}}
+	svc := NewCallService(repo, client)
+
+	if err := svc.Process(model.NewIncomingCall{CallID: "id_provider"}); err != nil {
+		t.Fatalf("did not expect error, got %v", err)
+	}
+	if repo.UpdateInputCost.Provider != "carrier_b" {
+		t.Errorf("expected provider carrier_b, got %q", repo.UpdateInputCost.Provider)
+	}
+}
+
+func TestProcess_InvalidCostResponse_MarkFailed(t *testing.T) {
+	repo := &mockRepo{}
+	client := &mockClient{Resp: &model.CostResponse{Cost: model.NewMoney(-100, "USD")}}
+	svc := NewCallService(repo, client)
+
+	if err := svc.Process(model.NewIncomingCall{CallID: "id_negative"}); err != nil {
+		t.Fatalf("did not expect error, got %v", err)
+	}
+	if repo.UpdateCalled {
+		t.Error("UpdateCallCost should not be called with an invalid response")
+	}
+	if !repo.MarkFailedCalled || repo.InvalidCalled {
+		t.Error("an invalid provider response should mark the call as ERROR, not INVALID")
+	}
+}
+
+func TestProcess_CostWiderThanColumn_MarkFailed(t *testing.T) {
+	for name, cost := range map[string]model.Money{
+		"3 decimales": model.MustParseMoney("1.234", "KWD"),
+		"desborde":    model.MustParseMoney("100000000", "USD"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := &mockRepo{}
+			svc := NewCallService(repo, &mockClient{Resp: &model.CostResponse{Cost: cost}})
+
+			if err := svc.Process(model.NewIncomingCall{CallID: "id_kwd"}); err != nil {
+				t.Fatalf("did not expect error (the message would be requeued forever), got %v", err)
+			}
+			if repo.UpdateCalled || !repo.MarkFailedCalled {
+				t.Error("a cost that does not fit the column should mark the call as ERROR")
+			}
+		})
+	}
+}
+
+func TestProcess_StoresCostBreakdown(t *testing.T) {
+	repo := &mockRepo{}
+	billed := 60
+	breakdown := model.CostBreakdown{
+		Taxes:             []model.Tax{{Name: "IVA", Amount: model.MustParseMoney("0.17", "USD")}},
+		RateID:            "US-1",
+		BilledDurationSec: &billed,
+		ProviderReference: "ref-1",
+	}
+	client := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("1", "USD"), Breakdown: breakdown}}
+	svc := NewCallService(repo, client)
+
+	if err := svc.Process(model.NewIncomingCall{CallID: "id_breakdown"}); err != nil {
+		t.Fatalf("did not expect error, got %v", err)
+	}
+	if !reflect.DeepEqual(repo.UpdateInputCost.Breakdown, breakdown) {
+		t.Errorf("expected breakdown %+v, got %+v", breakdown, repo.UpdateInputCost.Breakdown)
+	}
+}
+
+func TestProcess_WithPhoneNormalization_StoresE164AndNumbering(t *testing.T) {
+	repo := &mockRepo{}
+	client := &mockClient{Resp: &model.CostResponse{Cost: model.MustParseMoney("1", "ARS")}}
+	phones, _ := model.NewPhoneNormalizer("AR")
+	svc := NewCallService(repo, client, WithPhoneNormalization(phones))
+
+	err := svc.Process(model.NewIncomingCall{CallID: "id_phone", Caller: "011 15 1234-5678", Receiver: "+1 800 555 0100"})
+	if err != nil {
+		t.Fatalf("did not expect error, got %v", err)
+	}
+	if repo.SaveInput.Caller != "+5491112345678" || repo.SaveInput.Receiver != "+18005550100" {
+		t.Errorf("expected E.164 numbers, got %q and %q", repo.SaveInput.Caller, repo.SaveInput.Receiver)
+	}
+	want := model.CallNumbering{CallerCountry: "AR", CallerType: model.NumberTypeMobile, ReceiverCountry: "US", ReceiverType: model.NumberTypeTollFree}
+	if repo.SaveInput.Numbering != want {
+		t.Errorf("expected numbering %+v, got %+v", want, repo.SaveInput.Numbering)
+	}
+}
+
+func TestProcess_WithPhoneNormalization_RejectsImpossibleNumber(t *testing.T) {
+	repo := &mockRepo{}
+	client := &mockClient{}
+	phones, _ := model.NewPhoneNormalizer("AR")
+	svc := NewCallService(repo, client, WithPhoneNormalization(phones))
+
+	err := svc.Process(model.NewIncomingCall{CallID: "id_bad_phone", Caller: "+123", Receiver: "+5491112345678"})
+	if !model.IsPermanent(err) {
+		t.Fatalf("expected a permanent validation error, got %v", err)
+	}
+	if repo.SaveCalled || client.Called {
+		t.Error("an impossible number should not be saved nor priced")
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+// FXConverter convierte montos con la cotización vigente en una fecha. Si
+// no existe el par directo usa la inversa; si tampoco, pasa por la moneda base.
+type FXConverter struct {
+	rates        repository.FXRateReader
+	baseCurrency string
+}
+
+func NewFXConverter(rates repository.FXRateReader, baseCurrency string) *FXConverter {
+	return &FXConverter{rates: rates, baseCurrency: baseCurrency}
+}
+
+func (c *FXConverter) BaseCurrency() string {
+	return c.baseCurrency
+}
+
+func (c *FXConverter) ToBase(m model.Money, on time.Time) (model.Money, error) {
+	return c.Convert(m, c.baseCurrency, on)
+}
+
+func (c *FXConverter) Convert(m model.Money, to string, on time.Time) (model.Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	rate, err := c.rate(m.Currency, to, on)
+	if err == nil {
+		return m.Convert(rate.Rate, to)
+	}
+	if !errors.Is(err, repository.ErrRateNotFound) || m.Currency == c.baseCurrency || to == c.baseCurrency {
+		return model.Money{}, err
+	}
+
+	base, err := c.Convert(m, c.baseCurrency, on)
+	if err != nil {
+		return model.Money{}, err
+	}
+	return c.Convert(base, to, on)
+}
+
+func (c *FXConverter) rate(from, to string, on time.Time) (model.FXRate, error) {
+	rate, err := c.rates.GetRate(from, to, on)
+	if !errors.Is(err, repository.ErrRateNotFound) {
+		return rate, err
+	}
+	inverse, err := c.rates.GetRate(to, from, on)
+	if err != nil {
+		return model.FXRate{}, err
+	}
+	return inverse.Inverse(), nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type mockRates map[string]model.FXRate
+
+func (m mockRates) GetRate(from, to string, on time.Time) (model.FXRate, error) {
+	r, ok := m[from+to]
+	if !ok || r.Date.After(on) {
+		return model.FXRate{}, repository.ErrRateNotFound
+	}
+	return r, nil
+}
+
+func rate(from, to, value string) model.FXRate {
+	r, err := model.ParseFXRate(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), from, to, value)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+var onDate = time.Date(2024, 8, 29, 12, 0, 0, 0, time.UTC)
+
+func TestFXConverter_DirectRate(t *testing.T) {
+	fx := NewFXConverter(mockRates{"ARSUSD": rate("ARS", "USD", "0.001")}, "USD")
+
+	got, err := fx.ToBase(model.MustParseMoney("8.50", "ARS"), onDate)
+	if err != nil || got != model.MustParseMoney("0.01", "USD") {
+		t.Fatalf("expected 0.01 USD, got %v %v (err: %v)", got, got.Currency, err)
+	}
+}
+
+func TestFXConverter_InverseRate(t *testing.T) {
+	fx := NewFXConverter(mockRates{"USDARS": rate("USD", "ARS", "925")}, "USD")
+
+	got, err := fx.ToBase(model.MustParseMoney("1850", "ARS"), onDate)
+	if err != nil || got != model.MustParseMoney("2", "USD") {
+		t.Fatalf("expected 2 USD, got %v (err: %v)", got, err)
+	}
+}
+
+func TestFXConverter_CrossThroughBase(t *testing.T) {
+	fx := NewFXConverter(mockRates{
+		"EURUSD": rate("EUR", "USD", "1.10"),
+		"USDARS": rate("USD", "ARS", "1000"),
+	}, "USD")
+
+	got, err := fx.Convert(model.MustParseMoney("2", "EUR"), "ARS", onDate)
+	if err != nil || got != model.MustParseMoney("2200", "ARS") {
+		t.Fatalf("expected 2200 ARS, got %v (err: %v)", got, err)
+	}
+}
+
+func TestFXConverter_NotFound(t *testing.T) {
+	fx := NewFXConverter(mockRates{}, "USD")
+
+	if _, err := fx.ToBase(model.MustParseMoney("1", "EUR"), onDate); err != repository.ErrRateNotFound {
+		t.Fatalf("expected ErrRateNotFound, got %v", err)
+	}
+}
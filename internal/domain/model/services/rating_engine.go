@@ -0,0 +1,51 @@
+package services
+
+import (
+	"fmt"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/client"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+// RatingEngine tarifa llamadas localmente con el tarifario vigente en su
+// start_timestamp. Implementa client.CostClient para usarse como proveedor
+// principal o como fallback de la API de costos.
+type RatingEngine struct {
+	calls repository.CallDetailsReader
+	cards repository.RateCardReader
+}
+
+var _ client.CostClient = (*RatingEngine)(nil)
+
+func NewRatingEngine(calls repository.CallDetailsReader, cards repository.RateCardReader) *RatingEngine {
+	return &RatingEngine{calls: calls, cards: cards}
+}
+
+func (e *RatingEngine) GetCallCosts(callIDs []string) (map[string]client.BatchItem, error) {
+	return client.GetEach(e.GetCallCost, callIDs), nil
+}
+
+func (e *RatingEngine) GetCallCost(callID string) (*model.CostResponse, error) {
+	call, err := e.calls.GetCall(callID)
+	if err != nil {
+		return nil, err
+	}
+	if call == nil {
+		return nil, fmt.Errorf("llamada %s no encontrada para tarifar", callID)
+	}
+
+	start := call.StartTimestamp
+	if start.IsZero() {
+		return nil, fmt.Errorf("llamada %s sin start_timestamp para tarifar", callID)
+	}
+	card, err := e.cards.ActiveRateCard(start)
+	if err != nil {
+		return nil, err
+	}
+	cost, err := card.Price(*call, start)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CostResponse{Cost: cost, Provider: "local"}, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubCallDetails map[string]model.NewIncomingCall
+
+func (s stubCallDetails) GetCall(callID string) (*model.NewIncomingCall, error) {
+	call, ok := s[callID]
+	if !ok {
+		return nil, nil
+	}
+	return &call, nil
+}
+
+type stubRateCards []model.RateCard
+
+func (s stubRateCards) ActiveRateCard(at time.Time) (model.RateCard, error) {
+	for i := len(s) - 1; i >= 0; i-- {
+		if !s[i].EffectiveFrom.After(at) {
+			return s[i], nil
+		}
+	}
+	return model.RateCard{}, repository.ErrRateCardNotFound
+}
+
+func TestRatingEngine_UsesRateCardActiveAtCallStart(t *testing.T) {
+	calls := stubCallDetails{
+		"agosto":     {CallID: "agosto", Receiver: "+5491122223333", DurationInSec: 120, StartTimestamp: time.Date(2024, 8, 31, 23, 59, 0, 0, time.UTC)},
+		"septiembre": {CallID: "septiembre", Receiver: "+5491122223333", DurationInSec: 120, StartTimestamp: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	cards := stubRateCards{
+		{Version: "2024-08", EffectiveFrom: time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), Currency: "ARS",
+			Rates: []model.Rate{{Prefix: "+54", PerMinute: "10", IncrementSeconds: 60}}},
+		{Version: "2024-09", EffectiveFrom: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), Currency: "ARS",
+			Rates: []model.Rate{{Prefix: "+54", PerMinute: "12", IncrementSeconds: 60}}},
+	}
+	engine := NewRatingEngine(calls, cards)
+
+	resp, err := engine.GetCallCost("agosto")
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("20", "ARS"), resp.Cost)
+
+	resp, err = engine.GetCallCost("septiembre")
+	assert.NoError(t, err)
+	assert.Equal(t, model.MustParseMoney("24", "ARS"), resp.Cost)
+}
+
+func TestRatingEngine_Errors(t *testing.T) {
+	calls := stubCallDetails{
+		"viejo": {CallID: "viejo", Receiver: "+54", DurationInSec: 10, StartTimestamp: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	engine := NewRatingEngine(calls, stubRateCards{})
+
+	_, err := engine.GetCallCost("inexistente")
+	assert.Error(t, err)
+
+	_, err = engine.GetCallCost("viejo")
+	assert.ErrorIs(t, err, repository.ErrRateCardNotFound)
+}
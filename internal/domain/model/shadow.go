@@ -0,0 +1,108 @@
+package model
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ShadowCost es el costo que devolvió el proveedor en sombra. No afecta la
+// facturación: solo se guarda para compararlo con el costo real.
+type ShadowCost struct {
+	Provider string
+	Cost     *Money // nil si el proveedor falló
+	Error    string
+}
+
+// ShadowComparison junta el costo facturado y el de sombra de una llamada.
+type ShadowComparison struct {
+	CallID         string
+	Receiver       string
+	StartTimestamp time.Time
+	Status         string
+	Cost           *Money // nil si la llamada no tiene costo (PENDING, ERROR, INVALID)
+	Shadow         ShadowCost
+}
+
+// CostDiscrepancy es una llamada cuyo costo en sombra difiere del facturado
+// más allá de la tolerancia. Difference = Shadow - Cost, en la moneda de Cost.
+type CostDiscrepancy struct {
+	CallID         string
+	Receiver       string
+	Prefix         string
+	StartTimestamp time.Time
+	Cost           Money
+	Shadow         Money
+	Difference     Money
+}
+
+// DiscrepancySummary agrega las comparaciones de un prefijo y/o una moneda.
+// Difference es la suma neta de Shadow - Cost de todas las llamadas comparadas.
+type DiscrepancySummary struct {
+	Prefix        string
+	Currency      string
+	Compared      int
+	Discrepancies int
+	Difference    Money
+}
+
+// DiscrepancyReport: Skipped cuenta las llamadas sin costo facturado o cuyo
+// costo en sombra no se pudo convertir a la moneda facturada.
+type DiscrepancyReport struct {
+	Compared     int
+	ShadowErrors int
+	Skipped      int
+	Calls        []CostDiscrepancy
+	ByPrefix     []DiscrepancySummary
+	ByCurrency   []DiscrepancySummary
+}
+
+// Tolerance define cuánto pueden diferir dos costos: la diferencia absoluta
+// debe superar Absolute (en unidades de la moneda) y Percent del costo facturado.
+type Tolerance struct {
+	Absolute *big.Rat
+	Percent  *big.Rat
+}
+
+func ParseTolerance(absolute, percent string) (Tolerance, error) {
+	abs, err := parseRat(absolute)
+	if err != nil {
+		return Tolerance{}, fmt.Errorf("tolerancia absoluta: %w", err)
+	}
+	pct, err := parseRat(percent)
+	if err != nil {
+		return Tolerance{}, fmt.Errorf("tolerancia porcentual: %w", err)
+	}
+	return Tolerance{Absolute: abs, Percent: pct}, nil
+}
+
+// Exceeded indica si diff (en la moneda de cost) supera la tolerancia.
+func (t Tolerance) Exceeded(cost, diff Money) bool {
+	d := new(big.Rat).Abs(diff.major())
+	if t.Absolute != nil && d.Cmp(t.Absolute) <= 0 {
+		return false
+	}
+	if t.Percent != nil {
+		limit := new(big.Rat).Abs(cost.major())
+		limit.Mul(limit, t.Percent)
+		limit.Quo(limit, big.NewRat(100, 1))
+		if d.Cmp(limit) <= 0 {
+			return false
+		}
+	}
+	return d.Sign() != 0
+}
+
+// DialPrefix devuelve los primeros n dígitos del número con "+" adelante,
+// para agrupar destinos en los reportes.
+func DialPrefix(number string, n int) string {
+	d := digits(number)
+	if len(d) > n {
+		d = d[:n]
+	}
+	return "+" + d
+}
+
+func (m Money) major() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(m.Exponent()))
+}
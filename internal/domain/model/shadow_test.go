@@ -0,0 +1,34 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTolerance_Exceeded(t *testing.T) {
+	cost := MustParseMoney("100", "USD")
+
+	onlyAbs, err := ParseTolerance("0.50", "")
+	assert.NoError(t, err)
+	assert.False(t, onlyAbs.Exceeded(cost, MustParseMoney("0.50", "USD")))
+	assert.True(t, onlyAbs.Exceeded(cost, MustParseMoney("-0.51", "USD")))
+
+	both, err := ParseTolerance("0.50", "2")
+	assert.NoError(t, err)
+	assert.False(t, both.Exceeded(cost, MustParseMoney("2", "USD")))
+	assert.True(t, both.Exceeded(cost, MustParseMoney("2.01", "USD")))
+
+	exact, err := ParseTolerance("", "")
+	assert.NoError(t, err)
+	assert.False(t, exact.Exceeded(cost, NewMoney(0, "USD")))
+	assert.True(t, exact.Exceeded(cost, NewMoney(1, "USD")))
+
+	_, err = ParseTolerance("-1", "")
+	assert.Error(t, err)
+}
+
+func TestDialPrefix(t *testing.T) {
+	assert.Equal(t, "+5491", DialPrefix("+54 9 11 2222-3333", 4))
+	assert.Equal(t, "+1", DialPrefix("1", 4))
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formatos con nombre que acepta TimestampParser. Cualquier otro formato se
+// interpreta como un layout de Go (p. ej. "02/01/2006 15:04").
+const (
+	// TimestampRFC3339 acepta también fracciones de segundo (RFC3339Nano).
+	TimestampRFC3339 = "rfc3339"
+	// TimestampEpochMillis y TimestampEpochSeconds son enteros desde 1970 UTC.
+	TimestampEpochMillis  = "epoch_ms"
+	TimestampEpochSeconds = "epoch_s"
+	// TimestampLocal es fecha y hora sin offset, con "T" o espacio.
+	TimestampLocal = "local"
+)
+
+var DefaultTimestampFormats = []string{TimestampRFC3339, TimestampEpochMillis, TimestampLocal}
+
+// epochMillisDigits: desde 1973 un epoch en ms tiene 12 dígitos o más y uno en
+// segundos, 11 o menos.
+const epochMillisDigits = 12
+
+var localLayouts = []string{"2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999"}
+
+// TimestampParser interpreta start_timestamp en los formatos que envía cada
+// productor. Los formatos sin offset se leen en la zona horaria de la fuente.
+type TimestampParser struct {
+	formats  []string
+	location *time.Location
+}
+
+// NewTimestampParser prueba los formatos en orden; sin formatos usa
+// DefaultTimestampFormats y sin location, UTC.
+func NewTimestampParser(formats []string, location *time.Location) (*TimestampParser, error) {
+	if len(formats) == 0 {
+		formats = DefaultTimestampFormats
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	for _, f := range formats {
+		switch f {
+		case TimestampRFC3339, TimestampEpochMillis, TimestampEpochSeconds, TimestampLocal:
+		default:
+			if !strings.Contains(f, "2006") {
+				return nil, fmt.Errorf("formato de timestamp desconocido: %q", f)
+			}
+		}
+	}
+	return &TimestampParser{formats: formats, location: location}, nil
+}
+
+// DefaultTimestampParser acepta DefaultTimestampFormats en UTC.
+func DefaultTimestampParser() *TimestampParser {
+	return &TimestampParser{formats: DefaultTimestampFormats, location: time.UTC}
+}
+
+// Parse devuelve el instante en UTC.
+func (p *TimestampParser) Parse(raw string) (time.Time, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("timestamp vacío")
+	}
+	for _, f := range p.formats {
+		if t, ok := p.parseAs(f, s); ok {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("timestamp %q no coincide con ningún formato (%s)", raw, strings.Join(p.formats, ", "))
+}
+
+func (p *TimestampParser) parseAs(format, s string) (time.Time, bool) {
+	switch format {
+	case TimestampRFC3339:
+		t, err := time.Parse(time.RFC3339Nano, s)
+		return t, err == nil
+	case TimestampEpochMillis, TimestampEpochSeconds:
+		// Por la cantidad de dígitos se distingue ms de s: así un epoch en
+		// segundos no se lee como una fecha de 1970 (ni al revés).
+		millis := len(s) >= epochMillisDigits
+		if millis != (format == TimestampEpochMillis) {
+			return time.Time{}, false
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 {
+			return time.Time{}, false
+		}
+		if millis {
+			return time.UnixMilli(n), true
+		}
+		return time.Unix(n, 0), true
+	case TimestampLocal:
+		for _, layout := range localLayouts {
+			if t, err := time.ParseInLocation(layout, s, p.location); err == nil {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	default:
+		t, err := time.ParseInLocation(format, s, p.location)
+		return t, err == nil
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestampParser_Parse(t *testing.T) {
+	madrid := time.FixedZone("CEST", 2*3600)
+	p, err := NewTimestampParser([]string{TimestampRFC3339, TimestampEpochMillis, TimestampLocal, "02/01/2006 15:04"}, madrid)
+	assert.NoError(t, err)
+
+	want := time.Date(2024, 8, 29, 10, 30, 0, 0, time.UTC)
+	for _, raw := range []string{
+		"2024-08-29T10:30:00Z",
+		"2024-08-29T07:30:00-03:00",
+		"2024-08-29T10:30:00.000Z",
+		"1724927400000",
+		"2024-08-29T12:30:00",
+		"2024-08-29 12:30:00",
+		" 29/08/2024 12:30 ",
+	} {
+		got, err := p.Parse(raw)
+		if assert.NoError(t, err, raw) {
+			assert.Equal(t, want, got, raw)
+		}
+	}
+
+	nano, err := p.Parse("2024-08-29T10:30:00.123456789Z")
+	assert.NoError(t, err)
+	assert.Equal(t, 123456789, nano.Nanosecond())
+
+	for _, raw := range []string{"", "ayer", "1724927400", "-1", "2024-08-29"} {
+		_, err := p.Parse(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestTimestampParser_EpochSeconds(t *testing.T) {
+	p, err := NewTimestampParser([]string{TimestampEpochSeconds}, nil)
+	assert.NoError(t, err)
+
+	got, err := p.Parse("1724927400")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 8, 29, 10, 30, 0, 0, time.UTC), got)
+
+	_, err = p.Parse("1724927400000")
+	assert.Error(t, err, "un epoch en ms no se lee como segundos")
+}
+
+func TestNewTimestampParser_UnknownFormat(t *testing.T) {
+	_, err := NewTimestampParser([]string{"rfc1123"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxClockSkew es cuánto puede adelantar start_timestamp respecto del reloj
+// local sin que la llamada se considere en el futuro.
+const MaxClockSkew = 5 * time.Minute
+
+// FieldError describe por qué un campo del payload es inválido.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError agrupa los errores de campo de un mensaje. Es permanente:
+// reintentar el mismo mensaje no lo corrige.
+type ValidationError struct {
+	Entity string
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return fmt.Sprintf("%s inválido: %s", e.Entity, strings.Join(parts, "; "))
+}
+
+func (e *ValidationError) Permanent() bool { return true }
+
+func (e *ValidationError) add(field, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *ValidationError) orNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string   { return e.err.Error() }
+func (e *permanentError) Unwrap() error   { return e.err }
+func (e *permanentError) Permanent() bool { return true }
+
+// Permanent marca err como permanente (p. ej. un payload que no es JSON).
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent indica si err no se resuelve reintentando el mensaje.
+func IsPermanent(err error) bool {
+	var p interface{ Permanent() bool }
+	return errors.As(err, &p) && p.Permanent()
+}
+
+// Validate revisa la llamada antes de persistirla; now es la referencia para
+// rechazar llamadas con inicio en el futuro.
+func (c NewIncomingCall) Validate(now time.Time) error {
+	v := &ValidationError{Entity: "new_incoming_call"}
+	validateCallID(v, c.CallID)
+	if strings.TrimSpace(c.Caller) == "" {
+		v.add("caller", "es obligatorio")
+	}
+	if strings.TrimSpace(c.Receiver) == "" {
+		v.add("receiver", "es obligatorio")
+	}
+	if c.DurationInSec <= 0 {
+		v.add("duration_in_seconds", "debe ser positivo: %d", c.DurationInSec)
+	}
+	if c.StartTimestamp.IsZero() {
+		v.add("start_timestamp", "es obligatorio")
+	} else if c.StartTimestamp.After(now.Add(MaxClockSkew)) {
+		v.add("start_timestamp", "está en el futuro: %s", c.StartTimestamp.Format(time.RFC3339))
+	}
+	return v.orNil()
+}
+
+func (r RefundCall) Validate() error {
+	v := &ValidationError{Entity: "refund_call"}
+	validateCallID(v, r.CallID)
+	if r.Amount != nil && r.Percent != nil {
+		v.add("amount", "amount y percent son excluyentes")
+	}
+	if a := r.Amount; a != nil {
+		if !IsISOCurrency(a.Currency) {
+			v.add("currency", "no es ISO-4217: %q", a.Currency)
+		}
+		if a.Amount <= 0 {
+			v.add("amount", "debe ser positivo: %s", a.String())
+		} else if IsISOCurrency(a.Currency) {
+			if err := a.CheckStorable(); err != nil {
+				v.add("amount", "%v", err)
+			}
+		}
+	}
+	if p := r.Percent; p != nil && (*p <= 0 || *p > 100) {
+		v.add("percent", "debe estar entre 1 y 100: %d", *p)
+	}
+	return v.orNil()
+}
+
+func (v RefundReversal) Validate() error {
+	e := &ValidationError{Entity: "refund_reversed"}
+	validateCallID(e, v.CallID)
+	return e.orNil()
+}
+
+func validateCallID(v *ValidationError, callID string) {
+	if callID == "" {
+		v.add("call_id", "es obligatorio")
+	} else if _, err := uuid.Parse(callID); err != nil {
+		v.add("call_id", "debe ser un UUID: %q", callID)
+	}
+}
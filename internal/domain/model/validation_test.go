@@ -0,0 +1,82 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIncomingCall_Validate(t *testing.T) {
+	now := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+	valid := NewIncomingCall{
+		CallID:         "550e8400-e29b-41d4-a716-446655440000",
+		Caller:         "+5491111111111",
+		Receiver:       "+5491122222222",
+		DurationInSec:  60,
+		StartTimestamp: time.Date(2024, 8, 1, 11, 59, 0, 0, time.UTC),
+	}
+	assert.NoError(t, valid.Validate(now))
+
+	skewed := valid
+	skewed.StartTimestamp = now.Add(4 * time.Minute)
+	assert.NoError(t, skewed.Validate(now), "se tolera un desfase de reloj chico")
+
+	tests := map[string]struct {
+		mutate func(c *NewIncomingCall)
+		field  string
+	}{
+		"call_id vacío":       {func(c *NewIncomingCall) { c.CallID = "" }, "call_id"},
+		"call_id no UUID":     {func(c *NewIncomingCall) { c.CallID = "123" }, "call_id"},
+		"caller vacío":        {func(c *NewIncomingCall) { c.Caller = " " }, "caller"},
+		"receiver vacío":      {func(c *NewIncomingCall) { c.Receiver = "" }, "receiver"},
+		"duración cero":       {func(c *NewIncomingCall) { c.DurationInSec = 0 }, "duration_in_seconds"},
+		"duración negativa":   {func(c *NewIncomingCall) { c.DurationInSec = -5 }, "duration_in_seconds"},
+		"timestamp vacío":     {func(c *NewIncomingCall) { c.StartTimestamp = time.Time{} }, "start_timestamp"},
+		"timestamp en futuro": {func(c *NewIncomingCall) { c.StartTimestamp = now.Add(time.Hour) }, "start_timestamp"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			call := valid
+			tc.mutate(&call)
+
+			err := call.Validate(now)
+
+			var verr *ValidationError
+			if assert.ErrorAs(t, err, &verr) {
+				assert.Equal(t, "new_incoming_call", verr.Entity)
+				assert.Len(t, verr.Fields, 1)
+				assert.Equal(t, tc.field, verr.Fields[0].Field)
+			}
+			assert.True(t, IsPermanent(err))
+		})
+	}
+}
+
+func TestRefundCall_Validate(t *testing.T) {
+	assert.NoError(t, RefundCall{CallID: "550e8400-e29b-41d4-a716-446655440000"}.Validate())
+
+	err := RefundCall{CallID: "abc"}.Validate()
+	assert.EqualError(t, err, `refund_call inválido: call_id: debe ser un UUID: "abc"`)
+
+	assert.NoError(t, partialAmount("r1", "2.50").Validate())
+	assert.NoError(t, partialPercent("r1", 100).Validate())
+
+	both := partialAmount("r1", "2.50")
+	both.Percent = partialPercent("", 10).Percent
+	assert.EqualError(t, both.Validate(), "refund_call inválido: amount: amount y percent son excluyentes")
+	assert.EqualError(t, partialAmount("r1", "0").Validate(), "refund_call inválido: amount: debe ser positivo: 0.00")
+	assert.EqualError(t, partialPercent("r1", 0).Validate(), "refund_call inválido: percent: debe estar entre 1 y 100: 0")
+
+	kwd := partialAmount("r1", "2.50")
+	*kwd.Amount = MustParseMoney("1.234", "KWD")
+	assert.EqualError(t, kwd.Validate(), "refund_call inválido: amount: la moneda KWD usa 3 decimales y la columna admite 2")
+	assert.EqualError(t, partialAmount("r1", "100000000").Validate(), "refund_call inválido: amount: monto 100000000.00 ARS excede NUMERIC(10, 2)")
+}
+
+func TestIsPermanent(t *testing.T) {
+	assert.True(t, IsPermanent(Permanent(assert.AnError)))
+	assert.ErrorIs(t, Permanent(assert.AnError), assert.AnError)
+	assert.False(t, IsPermanent(assert.AnError))
+	assert.NoError(t, Permanent(nil))
+}
@@ -7,6 +7,27 @@ import (
 
 type CostClient interface {
 	GetCallCost(callID string) (*model.CostResponse, error)
+	// GetCallCosts tarifa varias llamadas en una sola consulta. El error se
+	// devuelve solo si falló el lote completo; los errores de cada llamada
+	// (p. ej. un 404) van en su BatchItem.
+	GetCallCosts(callIDs []string) (map[string]BatchItem, error)
+}
+
+// BatchItem es el resultado de una llamada dentro de una consulta en lote.
+type BatchItem struct {
+	Response *model.CostResponse
+	Err      error
+}
+
+// GetEach resuelve un lote con una consulta por llamada, para proveedores
+// sin endpoint de lote.
+func GetEach(get func(callID string) (*model.CostResponse, error), callIDs []string) map[string]BatchItem {
+	items := make(map[string]BatchItem, len(callIDs))
+	for _, id := range callIDs {
+		resp, err := get(id)
+		items[id] = BatchItem{Response: resp, Err: err}
+	}
+	return items
 }
 
 type CostAPIError struct {
@@ -0,0 +1,32 @@
+package messaging
+
+import "time"
+
+// Delivery es un mensaje recibido desde cualquier transporte. El consumidor
+// debe confirmarlo con Ack o rechazarlo con Nack una vez procesado.
+type Delivery interface {
+	Body() []byte
+	ContentType() string
+	Headers() map[string]interface{}
+	Ack() error
+	Nack(requeue bool) error
+}
+
+// RetryableDelivery la implementan los transportes que pueden reentregar un
+// mensaje más tarde y apartarlo en una cola de dead letter. Con los demás el
+// dispatcher reencola con Nack.
+type RetryableDelivery interface {
+	Delivery
+	// Attempt es el número de entrega del mensaje, desde 1.
+	Attempt() int
+	// Retry reentrega el mensaje después de delay y confirma esta entrega.
+	Retry(delay time.Duration) error
+	// DeadLetter aparta el mensaje con el motivo y confirma esta entrega.
+	DeadLetter(reason string) error
+}
+
+// MessageSource entrega mensajes hasta que se cierra el canal devuelto.
+type MessageSource interface {
+	Deliveries() (<-chan Delivery, error)
+	Close() error
+}
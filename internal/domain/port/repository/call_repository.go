@@ -1,15 +1,122 @@
 package repository
 
-import "phonecall-cost-processor-service/internal/domain/model"
+import (
+	"time"
 
-type CallRepository interface {
+	"phonecall-cost-processor-service/internal/domain/model"
+)
+
+// CallWriter registra llamadas entrantes.
+type CallWriter interface {
 	SaveIncomingCall(model.NewIncomingCall) error
-	UpdateCallCost(callID string, cost float64, currency string) error
-	MarkCostAsFailed(callID string) error
-	ApplyRefund(model.RefundCall) error
-	GetCallStatus(callID string) (string, error)
 	FillMissingCallData(model.NewIncomingCall) error
+}
+
+// Precisión de la columna cost (NUMERIC(10, 2)). Los adapters deben rechazar
+// montos que no entren en vez de dejar que la base los redondee.
+const (
+	CostPrecision = model.StoredPrecision
+	CostScale     = model.StoredScale
+)
+
+// CostResultWriter guarda el resultado de la consulta de costo.
+type CostResultWriter interface {
+	UpdateCallCost(callID string, result model.CostResult) error
+	MarkCostAsFailed(callID string) error
 	MarkCallAsInvalid(callID string) error
 }
 
+// RefundRepository guarda refunds. ApplyRefund devuelve false si no guardó
+// nada: el mismo refund ya estaba (reentrega) o es un refund total sobre una
+// llamada que ya tiene uno vigente.
+type RefundRepository interface {
+	ApplyRefund(model.RefundCall) (bool, error)
+}
+
+// RefundReversalRepository anula refunds. Una anulación que llega antes que
+// su refund queda pendiente y lo anula cuando llega.
+type RefundReversalRepository interface {
+	ReverseRefund(model.RefundReversal) error
+}
+
+// BulkRefundRepository busca las llamadas de un refund masivo en lotes
+// ordenados por call_id, a partir de afterCallID (vacío = desde el
+// principio), y guarda el avance para retomarlo. BulkRefundProgress devuelve
+// el avance vacío si el refund masivo no empezó.
+type BulkRefundRepository interface {
+	RefundCandidates(f model.BulkRefundFilter, afterCallID string, limit int) ([]string, error)
+	BulkRefundProgress(bulkID string) (model.BulkRefundProgress, error)
+	SaveBulkRefundProgress(model.BulkRefundProgress) error
+}
+
+// PendingEventRepository estaciona eventos de llamadas que todavía no
+// llegaron. ParkEvent guarda el evento y devuelve true si la llamada no
+// existe (o es un placeholder REFUND_PARTIALLY vigente) o si ya tiene eventos
+// estacionados, para no adelantarse a ellos; si no, devuelve false y el
+// evento se procesa normalmente. Una reentrega ya estacionada no se duplica.
+// ExpirePendingEvents descarta los estacionados antes de receivedBefore, de
+// llamadas que nunca llegaron, y los devuelve.
+type PendingEventRepository interface {
+	ParkEvent(model.PendingEvent) (bool, error)
+	PendingEvents(callID string) ([]model.PendingEvent, error)
+	DeletePendingEvent(callID string, seq int64) error
+	ExpirePendingEvents(receivedBefore time.Time) ([]model.PendingEvent, error)
+}
+
+// PlaceholderRepository maneja los placeholders REFUND_PARTIALLY cuyo
+// new_incoming_call no llegó. ExpirePlaceholders pasa a EXPIRED los creados
+// antes de createdBefore, registra placeholder_expired en su historial,
+// descarta sus eventos estacionados y devuelve los expirados.
+type PlaceholderRepository interface {
+	Placeholders() ([]model.Placeholder, error)
+	ExpirePlaceholders(createdBefore time.Time) ([]model.Placeholder, error)
+}
+
+// CostAdjustmentWriter guarda el ajuste manual del costo de una llamada; el
+// último reemplaza al anterior. Devuelve false si ya estaba guardado el
+// mismo ajuste (reentrega).
+type CostAdjustmentWriter interface {
+	AdjustCost(model.CostAdjustment) (bool, error)
+}
+
+// CallHistoryReader devuelve el historial de una llamada en orden.
+type CallHistoryReader interface {
+	CallHistory(callID string) ([]model.CallEvent, error)
+}
 
+type CallReader interface {
+	GetCallStatus(callID string) (string, error)
+}
+
+// CallDetailsReader devuelve los datos guardados de una llamada, o nil si
+// no existe. Lo usan los proveedores de costo que tarifan localmente.
+type CallDetailsReader interface {
+	GetCall(callID string) (*model.NewIncomingCall, error)
+}
+
+// BillingReader lee las llamadas facturadas (OK y REFUNDED) con
+// start_timestamp en [from, to) para los reportes.
+type BillingReader interface {
+	BilledCalls(from, to time.Time) ([]model.BilledCall, error)
+}
+
+// CallRepository agrupa todos los puertos; lo implementan los adapters
+// completos (Postgres, memoria). Los consumidores deben depender solo de
+// los puertos que usan.
+type CallRepository interface {
+	CallWriter
+	CostResultWriter
+	RefundRepository
+	RefundReversalRepository
+	BulkRefundRepository
+	PendingEventRepository
+	PlaceholderRepository
+	CostAdjustmentWriter
+	CallHistoryReader
+	CallReader
+	CallDetailsReader
+	BillingReader
+	ShadowCostWriter
+	ShadowCostReader
+	QualityCreditWriter
+}
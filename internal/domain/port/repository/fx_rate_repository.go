@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+)
+
+var ErrRateNotFound = errors.New("cotización no encontrada")
+
+// FXRateReader devuelve la cotización vigente en una fecha: la última
+// cargada con fecha menor o igual. Devuelve ErrRateNotFound si no hay.
+type FXRateReader interface {
+	GetRate(from, to string, on time.Time) (model.FXRate, error)
+}
+
+type FXRateWriter interface {
+	SaveRates([]model.FXRate) error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "phonecall-cost-processor-service/internal/domain/model"
+
+// QualityCreditWriter guarda los créditos por problemas de calidad en su
+// propia tabla, sin tocar el costo de la llamada. El crédito se guarda aunque
+// la llamada todavía no exista.
+type QualityCreditWriter interface {
+	// SaveQualityCredit conserva el crédito de mayor porcentaje de cada
+	// llamada, con su severidad y tipo; devuelve false si ya había uno igual
+	// o mayor.
+	SaveQualityCredit(model.QualityCredit) (bool, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+)
+
+var ErrRateCardNotFound = errors.New("no hay tarifario vigente")
+
+// RateCardReader devuelve la versión del tarifario vigente en un momento:
+// la de mayor effective_from menor o igual. Devuelve ErrRateCardNotFound si no hay.
+type RateCardReader interface {
+	ActiveRateCard(at time.Time) (model.RateCard, error)
+}
+
+type RateCardWriter interface {
+	SaveRateCard(model.RateCard) error
+}
@@ -0,0 +1,878 @@
+// Package repositorytest contiene la suite de conformidad que toda
+// implementación de repository.CallRepository debe pasar.
+package repositorytest
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+
+	"github.com/google/uuid"
+)
+
+// Run ejecuta la suite. newRepo debe devolver un repositorio vacío.
+func Run(t *testing.T, newRepo func(t *testing.T) repository.CallRepository) {
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepo(t))
+		})
+	}
+}
+
+var cases = []struct {
+	name string
+	run  func(t *testing.T, repo repository.CallRepository)
+}{
+	{"SaveIncomingCall deja la llamada PENDING", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		assertStatus(t, repo, id, "PENDING")
+	}},
+	{"SaveIncomingCall duplicada no pisa el estado", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("1.5", "USD")))
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		assertStatus(t, repo, id, "OK")
+	}},
+	{"SaveIncomingCall guarda start_timestamp en UTC", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		call := newCall(id)
+		call.StartTimestamp = time.Date(2024, 8, 29, 9, 30, 15, 250000000, time.FixedZone("ART", -3*3600))
+		mustNoErr(t, repo.SaveIncomingCall(call))
+
+		got, err := repo.GetCall(id)
+		mustNoErr(t, err)
+		want := time.Date(2024, 8, 29, 12, 30, 15, 250000000, time.UTC)
+		if got == nil || got.StartTimestamp != want {
+			t.Fatalf("expected start_timestamp %s, got %+v", want, got)
+		}
+	}},
+	{"call_id que no es UUID falla", func(t *testing.T, repo repository.CallRepository) {
+		if err := repo.SaveIncomingCall(newCall("not-a-uuid")); err == nil {
+			t.Fatal("expected error for non-UUID call_id")
+		}
+		if _, err := repo.GetCallStatus("not-a-uuid"); err == nil {
+			t.Fatal("expected error for non-UUID call_id in GetCallStatus")
+		}
+	}},
+	{"GetCallStatus de llamada inexistente es vacío", func(t *testing.T, repo repository.CallRepository) {
+		assertStatus(t, repo, uuid.NewString(), "")
+	}},
+	{"UpdateCallCost deja la llamada OK", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("19.99", "USD")))
+		assertStatus(t, repo, id, "OK")
+	}},
+	{"UpdateCallCost de llamada inexistente no la crea", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("1", "USD")))
+		assertStatus(t, repo, id, "")
+	}},
+	{"UpdateCallCost sobre REFUNDED guarda el costo sin cambiar el estado", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "reclamo"})
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("5", "ARS")))
+		assertStatus(t, repo, id, "REFUNDED")
+		if b := billed(t, repo, id); b == nil || b.Cost != model.MustParseMoney("5", "ARS") {
+			t.Fatalf("expected cost 5 ARS kept for a reversal, got %+v", b)
+		}
+	}},
+	{"UpdateCallCost rechaza montos que exceden NUMERIC(10, 2)", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		if err := repo.UpdateCallCost(id, costOf("100000000.00", "USD")); !model.IsPermanent(err) {
+			t.Fatalf("expected permanent overflow error, got %v", err)
+		}
+		if err := repo.UpdateCallCost(id, costOf("1.234", "KWD")); !model.IsPermanent(err) {
+			t.Fatalf("expected permanent precision error, got %v", err)
+		}
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("99999999.99", "USD")))
+		assertStatus(t, repo, id, "OK")
+	}},
+	{"UpdateCallCost completa una llamada en ERROR", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.MarkCostAsFailed(id))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("5", "ARS")))
+		assertStatus(t, repo, id, "OK")
+	}},
+	{"MarkCostAsFailed deja la llamada ERROR", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.MarkCostAsFailed(id))
+		assertStatus(t, repo, id, "ERROR")
+	}},
+	{"MarkCostAsFailed no modifica llamadas REFUNDED", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "reclamo"})
+		mustNoErr(t, repo.MarkCostAsFailed(id))
+		assertStatus(t, repo, id, "REFUNDED")
+	}},
+	{"ApplyRefund de llamada inexistente crea REFUND_PARTIALLY", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "anticipado"})
+		assertStatus(t, repo, id, "REFUND_PARTIALLY")
+	}},
+	{"ApplyRefund de llamada existente la deja REFUNDED", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("3", "EUR")))
+		if !applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "reclamo"}) {
+			t.Fatal("expected the first refund to be applied")
+		}
+		assertStatus(t, repo, id, "REFUNDED")
+		if applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "reclamo"}) {
+			t.Fatal("expected the redelivered refund to be a no-op")
+		}
+		if applyRefund(t, repo, model.RefundCall{CallID: id, RefundID: "otro", Reason: "otro reclamo"}) {
+			t.Fatal("expected a second full refund to be a no-op")
+		}
+	}},
+	{"ApplyRefund repetido sobre REFUND_PARTIALLY sigue esperando la llamada", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "uno"})
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "dos"})
+		assertStatus(t, repo, id, "REFUND_PARTIALLY")
+		mustNoErr(t, repo.FillMissingCallData(newCall(id)))
+		assertStatus(t, repo, id, "REFUNDED")
+	}},
+	{"ApplyRefund total conserva el costo original", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("3", "EUR")))
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "reclamo"})
+
+		b := billed(t, repo, id)
+		if b == nil || b.Status != "REFUNDED" || b.Cost != model.MustParseMoney("3", "EUR") {
+			t.Fatalf("expected REFUNDED with cost 3 EUR, got %+v", b)
+		}
+		if net := netCost(t, b); net.Amount != 0 {
+			t.Fatalf("expected net 0, got %s", net.String())
+		}
+	}},
+	{"ApplyRefund parciales hasta el costo original", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("10", "USD")))
+
+		amount := model.MustParseMoney("2.50", "USD")
+		half := 50
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "corte", RefundID: "r1", Amount: &amount})
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "corte", RefundID: "r1", Amount: &amount})
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "reclamo", Percent: &half})
+		over := model.MustParseMoney("2.51", "USD")
+		_, err := repo.ApplyRefund(model.RefundCall{CallID: id, Reason: "de más", RefundID: "r3", Amount: &over})
+		if !model.IsPermanent(err) {
+			t.Fatalf("expected a permanent error refunding over the cost, got %v", err)
+		}
+		assertStatus(t, repo, id, "OK")
+
+		b := billed(t, repo, id)
+		if b == nil || len(b.Refunds) != 2 || b.Cost != model.MustParseMoney("10", "USD") {
+			t.Fatalf("expected 2 refunds over cost 10 USD, got %+v", b)
+		}
+		if net := netCost(t, b); net != model.MustParseMoney("2.50", "USD") {
+			t.Fatalf("expected net 2.50, got %s", net.String())
+		}
+	}},
+	{"ApplyRefund parcial antes de la llamada la deja PENDING al completarla", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		half := 50
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "anticipado", Percent: &half})
+		assertStatus(t, repo, id, "REFUND_PARTIALLY")
+		mustNoErr(t, repo.FillMissingCallData(newCall(id)))
+		assertStatus(t, repo, id, "PENDING")
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("3", "EUR")))
+
+		b := billed(t, repo, id)
+		if b == nil || netCost(t, b) != model.MustParseMoney("1.50", "EUR") {
+			t.Fatalf("expected net 1.50 EUR, got %+v", b)
+		}
+	}},
+	{"UpdateCallCost rechaza los refunds anticipados que no entran en el costo", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		usd := model.MustParseMoney("1", "USD")
+		two := model.MustParseMoney("2", "ARS")
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "otra moneda", RefundID: "r1", Amount: &usd})
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "corte", RefundID: "r2", Amount: &two})
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "de más", RefundID: "r3", Amount: &two})
+		mustNoErr(t, repo.FillMissingCallData(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("3", "ARS")))
+
+		b := billed(t, repo, id)
+		if b == nil || len(b.Refunds) != 3 {
+			t.Fatalf("expected 3 refunds, got %+v", b)
+		}
+		for i, rejected := range []bool{true, false, true} {
+			if (b.Refunds[i].Rejected != "") != rejected {
+				t.Fatalf("refund %s: expected rejected=%v, got %q", b.Refunds[i].RefundID, rejected, b.Refunds[i].Rejected)
+			}
+		}
+		if net := netCost(t, b); net != model.MustParseMoney("1", "ARS") {
+			t.Fatalf("expected net 1 ARS, got %s", net.String())
+		}
+		history := []string{model.EventRefund, model.EventRefund, model.EventRefund, model.EventRefundRejected, model.EventRefundRejected}
+		assertHistory(t, repo, id, history...)
+
+		// Tarifar de nuevo no los vuelve a rechazar
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("3", "ARS")))
+		assertHistory(t, repo, id, history...)
+	}},
+	{"FillMissingCallData completa REFUND_PARTIALLY", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "anticipado"})
+		mustNoErr(t, repo.FillMissingCallData(newCall(id)))
+		assertStatus(t, repo, id, "REFUNDED")
+	}},
+	{"FillMissingCallData ignora otros estados", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("3", "EUR")))
+		mustNoErr(t, repo.FillMissingCallData(newCall(id)))
+		assertStatus(t, repo, id, "OK")
+	}},
+	{"UpdateCallCost guarda el costo normalizado", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		base := model.MustParseMoney("2.00", "USD")
+		mustNoErr(t, repo.UpdateCallCost(id, model.CostResult{Cost: model.MustParseMoney("2000", "ARS"), BaseCost: &base}))
+
+		calls, err := repo.BilledCalls(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		mustNoErr(t, err)
+		got := findBilled(calls, id)
+		if got == nil || got.Cost != model.MustParseMoney("2000", "ARS") || got.BaseCost == nil || *got.BaseCost != base {
+			t.Fatalf("unexpected billed call: %+v", got)
+		}
+	}},
+	{"UpdateCallCost guarda el proveedor", func(t *testing.T, repo repository.CallRepository) {
+		withProvider, withoutProvider := uuid.NewString(), uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(withProvider)))
+		mustNoErr(t, repo.SaveIncomingCall(newCall(withoutProvider)))
+		mustNoErr(t, repo.UpdateCallCost(withProvider, model.CostResult{Cost: model.MustParseMoney("1", "USD"), Provider: "carrier_b"}))
+		mustNoErr(t, repo.UpdateCallCost(withoutProvider, costOf("1", "USD")))
+
+		calls, err := repo.BilledCalls(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		mustNoErr(t, err)
+		if got := findBilled(calls, withProvider); got == nil || got.Provider != "carrier_b" {
+			t.Fatalf("expected provider carrier_b, got %+v", got)
+		}
+		if got := findBilled(calls, withoutProvider); got == nil || got.Provider != "" {
+			t.Fatalf("expected empty provider, got %+v", got)
+		}
+	}},
+	{"UpdateCallCost guarda el detalle del costo", func(t *testing.T, repo repository.CallRepository) {
+		detailed, plain := uuid.NewString(), uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(detailed)))
+		mustNoErr(t, repo.SaveIncomingCall(newCall(plain)))
+		billed := 120
+		breakdown := model.CostBreakdown{
+			Taxes:             []model.Tax{{Name: "IVA", Amount: model.MustParseMoney("1.74", "ARS")}},
+			RateID:            "AR-MOBILE-2024",
+			BilledDurationSec: &billed,
+			ProviderReference: "carrier-ref-42",
+		}
+		mustNoErr(t, repo.UpdateCallCost(detailed, model.CostResult{Cost: model.MustParseMoney("10", "ARS"), Breakdown: breakdown}))
+		mustNoErr(t, repo.UpdateCallCost(plain, costOf("1", "USD")))
+
+		calls, err := repo.BilledCalls(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		mustNoErr(t, err)
+		got := findBilled(calls, detailed)
+		if got == nil || !reflect.DeepEqual(got.Breakdown, breakdown) {
+			t.Fatalf("expected breakdown %+v, got %+v", breakdown, got)
+		}
+		if got := findBilled(calls, plain); got == nil || !reflect.DeepEqual(got.Breakdown, model.CostBreakdown{}) {
+			t.Fatalf("expected empty breakdown, got %+v", got)
+		}
+	}},
+	{"BilledCalls solo incluye OK y REFUNDED dentro del rango", func(t *testing.T, repo repository.CallRepository) {
+		ok, pending, refunded, old := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+		for _, id := range []string{ok, pending, refunded} {
+			mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		}
+		oldCall := newCall(old)
+		oldCall.StartTimestamp = time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+		mustNoErr(t, repo.SaveIncomingCall(oldCall))
+		mustNoErr(t, repo.UpdateCallCost(ok, costOf("1", "USD")))
+		mustNoErr(t, repo.UpdateCallCost(refunded, costOf("1", "USD")))
+		mustNoErr(t, repo.UpdateCallCost(old, costOf("1", "USD")))
+		applyRefund(t, repo, model.RefundCall{CallID: refunded, Reason: "reclamo"})
+
+		calls, err := repo.BilledCalls(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		mustNoErr(t, err)
+		if findBilled(calls, ok) == nil || findBilled(calls, refunded) == nil {
+			t.Fatalf("expected OK and REFUNDED calls, got %+v", calls)
+		}
+		if findBilled(calls, pending) != nil || findBilled(calls, old) != nil {
+			t.Fatalf("unexpected PENDING or out-of-range call in %+v", calls)
+		}
+	}},
+	{"GetCall devuelve los datos guardados", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		want := newCall(id)
+		mustNoErr(t, repo.SaveIncomingCall(want))
+
+		got, err := repo.GetCall(id)
+		mustNoErr(t, err)
+		if got == nil || *got != want {
+			t.Fatalf("expected %+v, got %+v", want, got)
+		}
+	}},
+	{"GetCall sin datos de numeración", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		want := newCall(id)
+		want.Numbering = model.CallNumbering{}
+		mustNoErr(t, repo.SaveIncomingCall(want))
+
+		got, err := repo.GetCall(id)
+		mustNoErr(t, err)
+		if got == nil || *got != want {
+			t.Fatalf("expected %+v, got %+v", want, got)
+		}
+	}},
+	{"FillMissingCallData y BilledCalls guardan la numeración", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "reclamo"})
+		call := newCall(id)
+		call.Numbering.ReceiverCountry, call.Numbering.ReceiverType = "US", model.NumberTypeTollFree
+		mustNoErr(t, repo.FillMissingCallData(call))
+
+		got, err := repo.GetCall(id)
+		mustNoErr(t, err)
+		if got == nil || got.Numbering != call.Numbering {
+			t.Fatalf("expected numbering %+v, got %+v", call.Numbering, got)
+		}
+		calls, err := repo.BilledCalls(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		mustNoErr(t, err)
+		if b := findBilled(calls, id); b == nil || b.Numbering != call.Numbering {
+			t.Fatalf("expected billed numbering %+v, got %+v", call.Numbering, b)
+		}
+	}},
+	{"SaveQualityCredit antes de la llamada conserva el crédito mayor", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		medium := model.QualityCredit{CallID: id, Severity: model.SeverityMedium, IssueType: "jitter", Percent: 10}
+		high := model.QualityCredit{CallID: id, Severity: model.SeverityHigh, IssueType: "dropped", Percent: 25}
+
+		for _, step := range []struct {
+			credit model.QualityCredit
+			saved  bool
+		}{{medium, true}, {medium, false}, {high, true}, {medium, false}} {
+			saved, err := repo.SaveQualityCredit(step.credit)
+			mustNoErr(t, err)
+			if saved != step.saved {
+				t.Fatalf("SaveQualityCredit(%s): expected saved=%v, got %v", step.credit.Severity, step.saved, saved)
+			}
+		}
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("10", "USD")))
+
+		calls, err := repo.BilledCalls(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		mustNoErr(t, err)
+		b := findBilled(calls, id)
+		if b == nil || b.Credit == nil || *b.Credit != high {
+			t.Fatalf("expected credit %+v, got %+v", high, b)
+		}
+		if b.Cost != model.MustParseMoney("10", "USD") {
+			t.Fatalf("credit must not change the stored cost, got %s", b.Cost.String())
+		}
+	}},
+	{"GetCall de llamada inexistente es nil", func(t *testing.T, repo repository.CallRepository) {
+		got, err := repo.GetCall(uuid.NewString())
+		mustNoErr(t, err)
+		if got != nil {
+			t.Fatalf("expected nil, got %+v", got)
+		}
+	}},
+	{"ShadowComparisons junta el costo facturado con el de sombra", func(t *testing.T, repo repository.CallRepository) {
+		billed, failed, pending := uuid.NewString(), uuid.NewString(), uuid.NewString()
+		for _, id := range []string{billed, failed, pending} {
+			mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		}
+		mustNoErr(t, repo.UpdateCallCost(billed, costOf("10", "ARS")))
+		shadow := model.MustParseMoney("11", "ARS")
+		mustNoErr(t, repo.SaveShadowCost(billed, model.ShadowCost{Provider: "viejo", Cost: &shadow}))
+		mustNoErr(t, repo.SaveShadowCost(billed, model.ShadowCost{Provider: "local", Cost: &shadow}))
+		mustNoErr(t, repo.SaveShadowCost(failed, model.ShadowCost{Provider: "local", Error: "sin tarifa"}))
+
+		got, err := repo.ShadowComparisons(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		mustNoErr(t, err)
+		if len(got) != 2 {
+			t.Fatalf("expected 2 comparisons, got %+v", got)
+		}
+		for _, c := range got {
+			switch c.CallID {
+			case billed:
+				if c.Cost == nil || *c.Cost != model.MustParseMoney("10", "ARS") || c.Shadow.Cost == nil || *c.Shadow.Cost != shadow || c.Shadow.Provider != "local" {
+					t.Fatalf("unexpected comparison: %+v", c)
+				}
+				if c.Receiver != newCall(billed).Receiver {
+					t.Fatalf("expected receiver, got %+v", c)
+				}
+			case failed:
+				if c.Cost != nil || c.Shadow.Cost != nil || c.Shadow.Error != "sin tarifa" {
+					t.Fatalf("unexpected comparison: %+v", c)
+				}
+			default:
+				t.Fatalf("unexpected call %s", c.CallID)
+			}
+		}
+	}},
+	{"ReverseRefund restaura el estado y el costo previos", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("3", "EUR")))
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "reclamo"})
+		reversal := model.RefundReversal{CallID: id, Reason: "refund por error"}
+		mustNoErr(t, repo.ReverseRefund(reversal))
+		mustNoErr(t, repo.ReverseRefund(reversal))
+		assertStatus(t, repo, id, "OK")
+
+		b := billed(t, repo, id)
+		if b == nil || netCost(t, b) != model.MustParseMoney("3", "EUR") {
+			t.Fatalf("expected net 3 EUR after the reversal, got %+v", b)
+		}
+		assertHistory(t, repo, id, model.EventRefund, model.EventRefundReversed)
+
+		// Anulado el refund, la llamada admite otro refund total
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "segundo reclamo"})
+		assertStatus(t, repo, id, "REFUNDED")
+	}},
+	{"ReverseRefund reenviado con otro motivo no anula un refund posterior", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("3", "EUR")))
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "reclamo"})
+		mustNoErr(t, repo.ReverseRefund(model.RefundReversal{CallID: id, Reason: "refund por error"}))
+		mustNoErr(t, repo.ReverseRefund(model.RefundReversal{CallID: id, Reason: "refund duplicado"}))
+		assertStatus(t, repo, id, "OK")
+
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "segundo reclamo"})
+		assertStatus(t, repo, id, "REFUNDED")
+		assertHistory(t, repo, id, model.EventRefund, model.EventRefundReversed, model.EventRefund)
+	}},
+	{"ReverseRefund de un refund ya anulado no queda pendiente", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("3", "EUR")))
+		applyRefund(t, repo, model.RefundCall{CallID: id, RefundID: "r1", Reason: "reclamo"})
+		mustNoErr(t, repo.ReverseRefund(model.RefundReversal{CallID: id, Reason: "refund por error"}))
+		mustNoErr(t, repo.ReverseRefund(model.RefundReversal{CallID: id, RefundID: "r1", Reason: "refund por error"}))
+		assertStatus(t, repo, id, "OK")
+		assertHistory(t, repo, id, model.EventRefund, model.EventRefundReversed)
+	}},
+	{"ReverseRefund restaura ERROR si el costo había fallado", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "reclamo"})
+		mustNoErr(t, repo.MarkCostAsFailed(id))
+		assertStatus(t, repo, id, "REFUNDED")
+		mustNoErr(t, repo.ReverseRefund(model.RefundReversal{CallID: id}))
+		assertStatus(t, repo, id, "ERROR")
+	}},
+	{"ReverseRefund antes del refund lo anula al llegar", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("10", "USD")))
+		mustNoErr(t, repo.ReverseRefund(model.RefundReversal{CallID: id, RefundID: "r1", Reason: "refund por error"}))
+		amount := model.MustParseMoney("4", "USD")
+		applyRefund(t, repo, model.RefundCall{CallID: id, RefundID: "r1", Amount: &amount})
+		applyRefund(t, repo, model.RefundCall{CallID: id, RefundID: "r1", Amount: &amount})
+		assertStatus(t, repo, id, "OK")
+
+		b := billed(t, repo, id)
+		if b == nil || len(b.Refunds) != 1 || !b.Refunds[0].Reversed || netCost(t, b) != model.MustParseMoney("10", "USD") {
+			t.Fatalf("expected the reversed refund not to count, got %+v", b)
+		}
+		assertHistory(t, repo, id, model.EventRefundReversalPending, model.EventRefund)
+	}},
+	{"ReverseRefund antes de la llamada la deja PENDING al completarla", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "anticipado"})
+		mustNoErr(t, repo.ReverseRefund(model.RefundReversal{CallID: id}))
+		assertStatus(t, repo, id, "REFUND_PARTIALLY")
+		mustNoErr(t, repo.FillMissingCallData(newCall(id)))
+		assertStatus(t, repo, id, "PENDING")
+	}},
+	{"ReverseRefund de un refund total tarifado después de completar la llamada", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "anticipado"})
+		mustNoErr(t, repo.FillMissingCallData(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("2", "USD")))
+		assertStatus(t, repo, id, "REFUNDED")
+		mustNoErr(t, repo.ReverseRefund(model.RefundReversal{CallID: id}))
+		assertStatus(t, repo, id, "OK")
+	}},
+	{"AdjustCost conserva el costo del proveedor y factura el ajustado", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("10", "USD")))
+		adj := model.CostAdjustment{CallID: id, Cost: model.MustParseMoney("7", "ARS"), Reason: "descuento contractual", Actor: "finanzas@telco"}
+		changed, err := repo.AdjustCost(adj)
+		mustNoErr(t, err)
+		if !changed {
+			t.Fatal("expected the first adjustment to be saved")
+		}
+		changed, err = repo.AdjustCost(adj)
+		mustNoErr(t, err)
+		if changed {
+			t.Fatal("expected the repeated adjustment to be a no-op")
+		}
+		assertStatus(t, repo, id, "OK")
+
+		b := billed(t, repo, id)
+		if b == nil || b.Adjustment == nil {
+			t.Fatalf("expected an adjusted call, got %+v", b)
+		}
+		if b.Cost != model.MustParseMoney("10", "USD") || b.BilledCost() != adj.Cost || !adj.Same(*b.Adjustment) {
+			t.Fatalf("expected provider 10 USD and billed 7 ARS, got %+v / %+v", b, *b.Adjustment)
+		}
+		assertHistory(t, repo, id, model.EventCostAdjusted)
+	}},
+	{"AdjustCost rechaza cambiar la moneda con refunds por monto vigentes", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("10", "USD")))
+		amount := model.MustParseMoney("2", "USD")
+		applyRefund(t, repo, model.RefundCall{CallID: id, RefundID: "r1", Amount: &amount})
+
+		adj := model.CostAdjustment{CallID: id, Cost: model.MustParseMoney("7", "ARS"), Reason: "corrección", Actor: "ops"}
+		var verr *model.ValidationError
+		if _, err := repo.AdjustCost(adj); !errors.As(err, &verr) {
+			t.Fatalf("expected a validation error changing the currency, got %v", err)
+		}
+		_, err := repo.AdjustCost(model.CostAdjustment{CallID: id, Cost: model.MustParseMoney("7", "USD"), Reason: "corrección", Actor: "ops"})
+		mustNoErr(t, err)
+
+		// Anulado el refund, el ajuste puede cambiar la moneda
+		mustNoErr(t, repo.ReverseRefund(model.RefundReversal{CallID: id, RefundID: "r1"}))
+		_, err = repo.AdjustCost(adj)
+		mustNoErr(t, err)
+		b := billed(t, repo, id)
+		if b == nil || netCost(t, b) != adj.Cost {
+			t.Fatalf("expected net 7 ARS, got %+v", b)
+		}
+	}},
+	{"ApplyRefund porcentual sobre un costo ajustado", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.UpdateCallCost(id, costOf("10", "USD")))
+		_, err := repo.AdjustCost(model.CostAdjustment{CallID: id, Cost: model.MustParseMoney("4", "USD"), Reason: "corrección", Actor: "ops"})
+		mustNoErr(t, err)
+		percent := 50
+		applyRefund(t, repo, model.RefundCall{CallID: id, RefundID: "r1", Percent: &percent})
+
+		b := billed(t, repo, id)
+		if b == nil || netCost(t, b) != model.MustParseMoney("2", "USD") {
+			t.Fatalf("expected net 2 USD, got %+v", b)
+		}
+		// El tope de los refunds parciales es el costo ajustado
+		amount := model.MustParseMoney("3", "USD")
+		if _, err := repo.ApplyRefund(model.RefundCall{CallID: id, RefundID: "r2", Amount: &amount}); !model.IsPermanent(err) {
+			t.Fatalf("expected a permanent error over the adjusted cost, got %v", err)
+		}
+	}},
+	{"RefundCandidates filtra por caller, ventana y estado en orden de call_id", func(t *testing.T, repo repository.CallRepository) {
+		start := time.Now().UTC().Truncate(time.Second)
+		save := func(id, caller string, offset time.Duration) {
+			call := newCall(id)
+			call.Caller = caller
+			call.StartTimestamp = start.Add(offset)
+			mustNoErr(t, repo.SaveIncomingCall(call))
+		}
+		ids := []string{uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()}
+		save(ids[0], "+5491100000001", 0)
+		save(ids[1], "+5491100000002", time.Minute)
+		save(ids[2], "+5491200000003", time.Minute) // otro prefijo
+		save(ids[3], "+5491100000004", 2*time.Hour) // fuera de la ventana
+		mustNoErr(t, repo.UpdateCallCost(ids[1], costOf("1", "USD")))
+
+		f := model.BulkRefundFilter{CallerPrefix: "+54911", From: start, To: start.Add(time.Hour)}
+		want := []string{ids[0], ids[1]}
+		sort.Strings(want)
+
+		got, err := repo.RefundCandidates(f, "", 10)
+		mustNoErr(t, err)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		got, err = repo.RefundCandidates(f, "", 1)
+		mustNoErr(t, err)
+		if !reflect.DeepEqual(got, want[:1]) {
+			t.Fatalf("expected the first page %v, got %v", want[:1], got)
+		}
+		got, err = repo.RefundCandidates(f, want[0], 10)
+		mustNoErr(t, err)
+		if !reflect.DeepEqual(got, want[1:]) {
+			t.Fatalf("expected the page after %s, got %v", want[0], got)
+		}
+
+		f.Statuses = []string{"OK"}
+		got, err = repo.RefundCandidates(f, "", 10)
+		mustNoErr(t, err)
+		if !reflect.DeepEqual(got, []string{ids[1]}) {
+			t.Fatalf("expected only the OK call, got %v", got)
+		}
+
+		f = model.BulkRefundFilter{Callers: []string{"+5491200000003", "+5491100000004"}, From: start, To: start.Add(3 * time.Hour)}
+		got, err = repo.RefundCandidates(f, "", 10)
+		mustNoErr(t, err)
+		want = []string{ids[2], ids[3]}
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}},
+	{"SaveBulkRefundProgress guarda el avance para retomarlo", func(t *testing.T, repo repository.CallRepository) {
+		bulkID := "bulk-" + uuid.NewString()
+		p, err := repo.BulkRefundProgress(bulkID)
+		mustNoErr(t, err)
+		if p != (model.BulkRefundProgress{BulkID: bulkID}) {
+			t.Fatalf("expected empty progress, got %+v", p)
+		}
+
+		saved := model.BulkRefundProgress{BulkID: bulkID, Cursor: uuid.NewString(), Matched: 3, Refunded: 2, Rejected: 1}
+		mustNoErr(t, repo.SaveBulkRefundProgress(saved))
+		saved.Done = true
+		mustNoErr(t, repo.SaveBulkRefundProgress(saved))
+		p, err = repo.BulkRefundProgress(bulkID)
+		mustNoErr(t, err)
+		if p != saved {
+			t.Fatalf("expected %+v, got %+v", saved, p)
+		}
+	}},
+	{"ParkEvent estaciona mientras la llamada no llegó y conserva el orden", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		park := func(typ, body string) bool {
+			t.Helper()
+			parked, err := repo.ParkEvent(model.PendingEvent{CallID: id, Type: typ, Body: []byte(body)})
+			mustNoErr(t, err)
+			return parked
+		}
+		applyRefund(t, repo, model.RefundCall{CallID: id, Reason: "anticipado"})
+		if !park("call_quality_issue", `{"severity":"high"}`) || !park("call_cost_adjusted", `{"cost":"1"}`) {
+			t.Fatal("expected events for a placeholder call to be parked")
+		}
+		if !park("call_quality_issue", `{"severity":"high"}`) {
+			t.Fatal("expected a redelivered event to report it is parked")
+		}
+
+		mustNoErr(t, repo.FillMissingCallData(newCall(id)))
+		if !park("call_quality_issue", `{"severity":"critical"}`) {
+			t.Fatal("expected events to keep parking while older ones are pending")
+		}
+
+		events, err := repo.PendingEvents(id)
+		mustNoErr(t, err)
+		var got []string
+		for _, e := range events {
+			got = append(got, string(e.Body))
+		}
+		want := []string{`{"severity":"high"}`, `{"cost":"1"}`, `{"severity":"critical"}`}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+
+		for _, e := range events {
+			mustNoErr(t, repo.DeletePendingEvent(id, e.Seq))
+		}
+		if park("call_quality_issue", `{"severity":"low"}`) {
+			t.Fatal("expected events for an existing call not to be parked")
+		}
+		events, err = repo.PendingEvents(id)
+		mustNoErr(t, err)
+		if len(events) != 0 {
+			t.Fatalf("expected no pending events, got %+v", events)
+		}
+	}},
+	{"ExpirePlaceholders expira placeholders viejos y los deja terminales", func(t *testing.T, repo repository.CallRepository) {
+		orphan, arrived := uuid.NewString(), uuid.NewString()
+		applyRefund(t, repo, model.RefundCall{CallID: orphan, Reason: "anticipado"})
+		applyRefund(t, repo, model.RefundCall{CallID: arrived, Reason: "anticipado"})
+		mustNoErr(t, repo.FillMissingCallData(newCall(arrived)))
+		_, err := repo.ParkEvent(model.PendingEvent{CallID: orphan, Type: "call_quality_issue", Body: []byte(`{}`)})
+		mustNoErr(t, err)
+
+		placeholders, err := repo.Placeholders()
+		mustNoErr(t, err)
+		if findPlaceholder(placeholders, orphan) == nil || findPlaceholder(placeholders, arrived) != nil {
+			t.Fatalf("expected only the orphan placeholder, got %+v", placeholders)
+		}
+
+		expired, err := repo.ExpirePlaceholders(time.Now().Add(-time.Hour))
+		mustNoErr(t, err)
+		if findPlaceholder(expired, orphan) != nil {
+			t.Fatalf("expected a recent placeholder not to expire, got %+v", expired)
+		}
+		expired, err = repo.ExpirePlaceholders(time.Now().Add(time.Hour))
+		mustNoErr(t, err)
+		if findPlaceholder(expired, orphan) == nil || findPlaceholder(expired, arrived) != nil {
+			t.Fatalf("expected the orphan placeholder to expire, got %+v", expired)
+		}
+		assertStatus(t, repo, orphan, "EXPIRED")
+		assertStatus(t, repo, arrived, "REFUNDED")
+		assertHistory(t, repo, orphan, model.EventRefund, model.EventPlaceholderExpired)
+
+		pending, err := repo.PendingEvents(orphan)
+		mustNoErr(t, err)
+		if len(pending) != 0 {
+			t.Fatalf("expected parked events to be dropped, got %+v", pending)
+		}
+		parked, err := repo.ParkEvent(model.PendingEvent{CallID: orphan, Type: "call_quality_issue", Body: []byte(`{}`)})
+		mustNoErr(t, err)
+		if parked {
+			t.Fatal("expected events for an expired call not to be parked")
+		}
+
+		// EXPIRED es terminal: un refund total posterior no lo cambia
+		applyRefund(t, repo, model.RefundCall{CallID: orphan, Reason: "otro"})
+		assertStatus(t, repo, orphan, "EXPIRED")
+		placeholders, err = repo.Placeholders()
+		mustNoErr(t, err)
+		if findPlaceholder(placeholders, orphan) != nil {
+			t.Fatalf("expected the expired call not to be a placeholder, got %+v", placeholders)
+		}
+	}},
+	{"ExpirePendingEvents descarta eventos estacionados viejos", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		parked, err := repo.ParkEvent(model.PendingEvent{CallID: id, Type: "call_quality_issue", Body: []byte(`{}`)})
+		mustNoErr(t, err)
+		if !parked {
+			t.Fatal("expected the event to be parked")
+		}
+
+		expired, err := repo.ExpirePendingEvents(time.Now().Add(-time.Hour))
+		mustNoErr(t, err)
+		if findPendingEvent(expired, id) != nil {
+			t.Fatalf("expected a recent event not to expire, got %+v", expired)
+		}
+		expired, err = repo.ExpirePendingEvents(time.Now().Add(time.Hour))
+		mustNoErr(t, err)
+		if e := findPendingEvent(expired, id); e == nil || e.Type != "call_quality_issue" {
+			t.Fatalf("expected the parked event to expire, got %+v", expired)
+		}
+		pending, err := repo.PendingEvents(id)
+		mustNoErr(t, err)
+		if len(pending) != 0 {
+			t.Fatalf("expected the expired event to be dropped, got %+v", pending)
+		}
+
+		// La llamada sigue sin llegar: un evento nuevo se vuelve a estacionar
+		parked, err = repo.ParkEvent(model.PendingEvent{CallID: id, Type: "call_quality_issue", Body: []byte(`{}`)})
+		mustNoErr(t, err)
+		if !parked {
+			t.Fatal("expected a new event to be parked again")
+		}
+	}},
+	{"MarkCallAsInvalid deja la llamada INVALID", func(t *testing.T, repo repository.CallRepository) {
+		id := uuid.NewString()
+		mustNoErr(t, repo.SaveIncomingCall(newCall(id)))
+		mustNoErr(t, repo.MarkCallAsInvalid(id))
+		assertStatus(t, repo, id, "INVALID")
+	}},
+}
+
+func newCall(id string) model.NewIncomingCall {
+	return model.NewIncomingCall{
+		CallID:         id,
+		Caller:         "+5491111111111",
+		Receiver:       "+5491122222222",
+		DurationInSec:  60,
+		StartTimestamp: time.Now().UTC().Truncate(time.Second),
+		Numbering: model.CallNumbering{
+			CallerCountry: "AR", CallerType: model.NumberTypeMobile,
+			ReceiverCountry: "AR", ReceiverType: model.NumberTypeMobile,
+		},
+	}
+}
+
+func costOf(amount, currency string) model.CostResult {
+	return model.CostResult{Cost: model.MustParseMoney(amount, currency)}
+}
+
+func billed(t *testing.T, repo repository.CallRepository, id string) *model.BilledCall {
+	t.Helper()
+	calls, err := repo.BilledCalls(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	mustNoErr(t, err)
+	return findBilled(calls, id)
+}
+
+func netCost(t *testing.T, b *model.BilledCall) model.Money {
+	t.Helper()
+	net, err := b.NetCost()
+	mustNoErr(t, err)
+	return net
+}
+
+func findBilled(calls []model.BilledCall, id string) *model.BilledCall {
+	for i := range calls {
+		if calls[i].CallID == id {
+			return &calls[i]
+		}
+	}
+	return nil
+}
+
+func assertHistory(t *testing.T, repo repository.CallRepository, callID string, want ...string) {
+	t.Helper()
+	events, err := repo.CallHistory(callID)
+	mustNoErr(t, err)
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Type)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected history %v, got %+v", want, events)
+	}
+}
+
+func findPlaceholder(list []model.Placeholder, id string) *model.Placeholder {
+	for i := range list {
+		if list[i].CallID == id {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+func findPendingEvent(list []model.PendingEvent, id string) *model.PendingEvent {
+	for i := range list {
+		if list[i].CallID == id {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+// applyRefund aplica refund y devuelve si se guardó.
+func applyRefund(t *testing.T, repo repository.CallRepository, refund model.RefundCall) bool {
+	t.Helper()
+	applied, err := repo.ApplyRefund(refund)
+	mustNoErr(t, err)
+	return applied
+}
+
+func mustNoErr(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertStatus(t *testing.T, repo repository.CallRepository, callID, want string) {
+	t.Helper()
+	got, err := repo.GetCallStatus(callID)
+	if err != nil {
+		t.Fatalf("GetCallStatus(%s): %v", callID, err)
+	}
+	if got != want {
+		t.Fatalf("expected status %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+)
+
+// ShadowCostWriter guarda el resultado del proveedor en sombra. Una nueva
+// consulta para la misma llamada reemplaza la anterior.
+type ShadowCostWriter interface {
+	SaveShadowCost(callID string, shadow model.ShadowCost) error
+}
+
+// ShadowCostReader devuelve las llamadas con costo en sombra y start_timestamp
+// en [from, to), junto con su costo facturado.
+type ShadowCostReader interface {
+	ShadowComparisons(from, to time.Time) ([]model.ShadowComparison, error)
+}
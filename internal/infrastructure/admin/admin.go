@@ -0,0 +1,159 @@
+// Package admin expone la API HTTP de administración: operaciones manuales de
+// finanzas sobre llamadas ya procesadas.
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"phonecall-cost-processor-service/internal/application"
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+	"phonecall-cost-processor-service/internal/infrastructure/handler"
+	"phonecall-cost-processor-service/internal/infrastructure/rabbitmq/dto"
+)
+
+type api struct {
+	adjust application.ICallCostAdjustedUseCase
+	bulk   application.IBulkRefundUseCase
+	calls  repository.CallReader
+	token  string
+}
+
+// NewHandler arma las rutas de administración. Todas exigen
+// "Authorization: Bearer <token>". Ajustar el costo de una llamada que no
+// existe responde 404: a diferencia del mensaje call_cost_adjusted, finanzas
+// ajusta llamadas ya procesadas.
+//
+//	POST /admin/calls/{call_id}/cost  {"cost": "7.00", "currency": "ARS", "reason": "...", "actor": "..."}
+//	POST /admin/bulk-refunds          {"caller_prefix": "+54911", "from": "...", "to": "...", "reason": "..."}
+func NewHandler(adjust application.ICallCostAdjustedUseCase, bulk application.IBulkRefundUseCase, calls repository.CallReader, token string) http.Handler {
+	a := &api{adjust: adjust, bulk: bulk, calls: calls, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /admin/calls/{call_id}/cost", a.adjustCost)
+	mux.HandleFunc("POST /admin/bulk-refunds", a.bulkRefund)
+	return a.authorize(mux)
+}
+
+func (a *api) authorize(next http.Handler) http.Handler {
+	want := []byte("Bearer " + a.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "no autorizado"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type adjustmentResponse struct {
+	CallID   string `json:"call_id"`
+	Cost     string `json:"cost"`
+	Currency string `json:"currency"`
+	Reason   string `json:"reason"`
+	Actor    string `json:"actor"`
+	// Changed es false si el mismo ajuste ya estaba guardado.
+	Changed bool `json:"changed"`
+}
+
+type errorResponse struct {
+	Error  string             `json:"error"`
+	Fields []model.FieldError `json:"fields,omitempty"`
+}
+
+func (a *api) adjustCost(w http.ResponseWriter, r *http.Request) {
+	var d dto.CallCostAdjustedDTO
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&d); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "payload inválido: " + err.Error()})
+		return
+	}
+	d.CallID = r.PathValue("call_id")
+
+	adj, err := handler.CostAdjustmentFromDTO(d)
+	if err == nil {
+		err = adj.Validate()
+	}
+	var status string
+	if err == nil {
+		status, err = a.calls.GetCallStatus(adj.CallID)
+	}
+	var changed bool
+	if err == nil && status != "" {
+		changed, err = a.adjust.Execute(adj)
+	}
+	var verr *model.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: verr.Error(), Fields: verr.Fields})
+		return
+	case err == nil && status == "":
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "llamada no encontrada: " + adj.CallID})
+		return
+	case err != nil:
+		log.Printf("❌ Error ajustando costo call_id=%s desde la API: %v", d.CallID, err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "error interno"})
+		return
+	}
+
+	if changed {
+		log.Printf("✏️ Costo ajustado desde la API call_id=%s: %s %s por %s (%s)", adj.CallID, adj.Cost.String(), adj.Cost.Currency, adj.Actor, adj.Reason)
+	}
+	writeJSON(w, http.StatusOK, adjustmentResponse{
+		CallID:   adj.CallID,
+		Cost:     adj.Cost.String(),
+		Currency: adj.Cost.Currency,
+		Reason:   adj.Reason,
+		Actor:    adj.Actor,
+		Changed:  changed,
+	})
+}
+
+type bulkRefundResponse struct {
+	BulkID   string `json:"bulk_id"`
+	Matched  int    `json:"matched"`
+	Refunded int    `json:"refunded"`
+	Rejected int    `json:"rejected"`
+	Done     bool   `json:"done"`
+}
+
+// bulkRefund corre el refund masivo completo antes de responder. Si se corta,
+// reenviar el mismo body retoma desde el último lote guardado.
+func (a *api) bulkRefund(w http.ResponseWriter, r *http.Request) {
+	var d dto.BulkRefundDTO
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "payload inválido: " + err.Error()})
+		return
+	}
+
+	p, err := a.bulk.Execute(handler.BulkRefundFromDTO(d))
+	var verr *model.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: verr.Error(), Fields: verr.Fields})
+		return
+	case err != nil:
+		log.Printf("❌ Refund masivo %s interrumpido desde la API: %v", p.BulkID, err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "refund masivo interrumpido; reenviar el mismo pedido lo retoma"})
+		return
+	}
+
+	log.Printf("💸 Refund masivo %s desde la API: encontradas=%d reembolsadas=%d rechazadas=%d", p.BulkID, p.Matched, p.Refunded, p.Rejected)
+	writeJSON(w, http.StatusOK, bulkRefundResponse{
+		BulkID:   p.BulkID,
+		Matched:  p.Matched,
+		Refunded: p.Refunded,
+		Rejected: p.Rejected,
+		Done:     p.Done,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,151 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"phonecall-cost-processor-service/internal/application"
+	"phonecall-cost-processor-service/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const callID = "550e8400-e29b-41d4-a716-446655440000"
+
+type mockAdjustUseCase struct {
+	input model.CostAdjustment
+	err   error
+}
+
+func (m *mockAdjustUseCase) Execute(adj model.CostAdjustment) (bool, error) {
+	m.input = adj
+	if m.err != nil {
+		return false, m.err
+	}
+	return true, adj.Validate()
+}
+
+var _ application.ICallCostAdjustedUseCase = (*mockAdjustUseCase)(nil)
+
+type mockBulkUseCase struct {
+	input model.BulkRefund
+}
+
+func (m *mockBulkUseCase) Execute(bulk model.BulkRefund) (model.BulkRefundProgress, error) {
+	m.input = bulk
+	if err := bulk.Validate(); err != nil {
+		return model.BulkRefundProgress{}, err
+	}
+	return model.BulkRefundProgress{BulkID: bulk.ID(), Matched: 3, Refunded: 2, Rejected: 1, Done: true}, nil
+}
+
+func (m *mockBulkUseCase) ExecuteBatch(bulk model.BulkRefund) (model.BulkRefundProgress, error) {
+	return m.Execute(bulk)
+}
+
+// mockCalls devuelve el estado de las llamadas conocidas y "" para el resto.
+type mockCalls map[string]string
+
+func (m mockCalls) GetCallStatus(callID string) (string, error) {
+	return m[callID], nil
+}
+
+var knownCalls = mockCalls{callID: "OK"}
+
+func post(h http.Handler, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdjustCost(t *testing.T) {
+	uc := &mockAdjustUseCase{}
+	h := NewHandler(uc, &mockBulkUseCase{}, knownCalls, "secreto")
+
+	rec := post(h, "/admin/calls/"+callID+"/cost", "secreto",
+		`{"cost": 7.5, "currency": "ARS", "reason": "descuento contractual", "actor": "finanzas@telco"}`)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"call_id":"`+callID+`","cost":"7.50","currency":"ARS","reason":"descuento contractual","actor":"finanzas@telco","changed":true}`, rec.Body.String())
+	assert.Equal(t, model.CostAdjustment{CallID: callID, Cost: model.MustParseMoney("7.50", "ARS"), Reason: "descuento contractual", Actor: "finanzas@telco"}, uc.input)
+}
+
+func TestAdjustCost_Unauthorized(t *testing.T) {
+	h := NewHandler(&mockAdjustUseCase{}, &mockBulkUseCase{}, knownCalls, "secreto")
+
+	for _, token := range []string{"", "otro"} {
+		rec := post(h, "/admin/calls/"+callID+"/cost", token, `{}`)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, token)
+	}
+}
+
+func TestAdjustCost_Invalid(t *testing.T) {
+	h := NewHandler(&mockAdjustUseCase{}, &mockBulkUseCase{}, knownCalls, "secreto")
+
+	rec := post(h, "/admin/calls/"+callID+"/cost", "secreto", `{"currency": "ARS", "reason": "x", "actor": "y"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"field":"cost"`)
+
+	rec = post(h, "/admin/calls/"+callID+"/cost", "secreto", `{"cost": "10", "currency": "ARS"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"field":"reason"`)
+	assert.Contains(t, rec.Body.String(), `"field":"actor"`)
+
+	rec = post(h, "/admin/calls/"+callID+"/cost", "secreto", `{"cost": "diez"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAdjustCost_NotStorable(t *testing.T) {
+	h := NewHandler(&mockAdjustUseCase{}, &mockBulkUseCase{}, knownCalls, "secreto")
+
+	rec := post(h, "/admin/calls/"+callID+"/cost", "secreto", `{"cost": "1.234", "currency": "KWD", "reason": "x", "actor": "y"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"field":"cost"`)
+
+	rec = post(h, "/admin/calls/"+callID+"/cost", "secreto", `{"cost": "100000000", "currency": "ARS", "reason": "x", "actor": "y"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAdjustCost_UnknownCall(t *testing.T) {
+	uc := &mockAdjustUseCase{}
+	h := NewHandler(uc, &mockBulkUseCase{}, mockCalls{}, "secreto")
+
+	rec := post(h, "/admin/calls/"+callID+"/cost", "secreto", `{"cost": "10", "currency": "ARS", "reason": "x", "actor": "y"}`)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, uc.input.CallID, "no debe guardar el ajuste")
+}
+
+func TestAdjustCost_RepositoryError(t *testing.T) {
+	h := NewHandler(&mockAdjustUseCase{err: errors.New("db down")}, &mockBulkUseCase{}, knownCalls, "secreto")
+
+	rec := post(h, "/admin/calls/"+callID+"/cost", "secreto", `{"cost": "10", "currency": "ARS", "reason": "x", "actor": "y"}`)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestBulkRefund(t *testing.T) {
+	uc := &mockBulkUseCase{}
+	h := NewHandler(&mockAdjustUseCase{}, uc, knownCalls, "secreto")
+
+	rec := post(h, "/admin/bulk-refunds", "secreto",
+		`{"bulk_id": "corte-0829", "caller_prefix": "+54911", "from": "2024-08-29T10:00:00Z", "to": "2024-08-29T12:00:00Z", "reason": "Corte de red"}`)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"bulk_id":"corte-0829","matched":3,"refunded":2,"rejected":1,"done":true}`, rec.Body.String())
+	assert.Equal(t, "+54911", uc.input.Filter.CallerPrefix)
+
+	rec = post(h, "/admin/bulk-refunds", "secreto", `{"caller_prefix": "+54911", "reason": "Corte de red"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"field":"from"`)
+
+	rec = post(h, "/admin/bulk-refunds", "", `{}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
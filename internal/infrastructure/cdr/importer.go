@@ -0,0 +1,223 @@
+package cdr
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"phonecall-cost-processor-service/internal/application"
+	"phonecall-cost-processor-service/internal/domain/model"
+)
+
+// Result resume una ejecución del import.
+type Result struct {
+	Read     int
+	Imported int
+	Rejected int
+	Skipped  int
+}
+
+// Importer envía las filas de un Reader al IncomingCallUseCase en lotes.
+// Al terminar cada lote guarda un checkpoint con la última línea procesada,
+// así una ejecución interrumpida retoma desde ahí. Las filas inválidas se
+// escriben en el reporte de errores (line,call_id,error) junto con el
+// checkpoint de su lote y no detienen el import.
+type Importer struct {
+	useCase        application.IIncomingCallUseCase
+	batchSize      int
+	workers        int
+	checkpointPath string
+	errorReport    io.Writer
+	progress       func(Result)
+}
+
+type ImporterOption func(*Importer)
+
+// WithWorkers procesa hasta n filas de cada lote a la vez. Así las consultas
+// de costo concurrentes se pueden agrupar en lotes (client.MicroBatcher).
+func WithWorkers(n int) ImporterOption {
+	return func(imp *Importer) {
+		if n > 0 {
+			imp.workers = n
+		}
+	}
+}
+
+func NewImporter(useCase application.IIncomingCallUseCase, batchSize int, checkpointPath string, errorReport io.Writer, progress func(Result), opts ...ImporterOption) *Importer {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if progress == nil {
+		progress = func(r Result) {
+			log.Printf("📦 Progreso import: leídas=%d importadas=%d rechazadas=%d salteadas=%d", r.Read, r.Imported, r.Rejected, r.Skipped)
+		}
+	}
+	imp := &Importer{
+		useCase:        useCase,
+		batchSize:      batchSize,
+		workers:        1,
+		checkpointPath: checkpointPath,
+		errorReport:    errorReport,
+		progress:       progress,
+	}
+	for _, opt := range opts {
+		opt(imp)
+	}
+	return imp
+}
+
+func (imp *Importer) Run(reader Reader) (Result, error) {
+	var res Result
+
+	resumeAfter, err := imp.loadCheckpoint()
+	if err != nil {
+		return res, err
+	}
+	if resumeAfter > 0 {
+		log.Printf("⏩ Retomando import después de la línea %d", resumeAfter)
+	}
+
+	report := csv.NewWriter(imp.errorReport)
+	if resumeAfter == 0 {
+		if err := report.Write(reportHeader); err != nil {
+			return res, fmt.Errorf("error escribiendo reporte de errores: %w", err)
+		}
+	}
+	defer report.Flush()
+
+	var batch []Record
+	for {
+		rec, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return res, fmt.Errorf("error leyendo archivo: %w", err)
+		}
+		res.Read++
+
+		if rec.Line <= resumeAfter {
+			res.Skipped++
+			continue
+		}
+
+		batch = append(batch, rec)
+		if len(batch) == imp.batchSize {
+			if err := imp.processBatch(batch, &res, report); err != nil {
+				return res, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := imp.processBatch(batch, &res, report); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+var reportHeader = []string{"line", "call_id", "error"}
+
+// processBatch escribe los rechazos recién cuando el lote termina: si se corta
+// a mitad, al retomar el lote se reprocesa sin duplicarlos en el reporte.
+func (imp *Importer) processBatch(batch []Record, res *Result, report *csv.Writer) error {
+	now := time.Now()
+	for i := range batch {
+		if batch[i].Err == nil {
+			batch[i].Err = batch[i].Call.Validate(now)
+		}
+	}
+	results := imp.execute(batch)
+
+	var rejected [][]string
+	for i, rec := range batch {
+		if rec.Err == nil {
+			err := results[i]
+			if err == nil {
+				res.Imported++
+				continue
+			}
+			// Un error técnico (p. ej. la base no responde) corta sin avanzar el
+			// checkpoint y el lote se reintenta al retomar; uno permanente (p. ej.
+			// un número imposible) rechaza solo la fila.
+			if !model.IsPermanent(err) {
+				return fmt.Errorf("error importando línea %d (call_id=%s): %w", rec.Line, rec.Call.CallID, err)
+			}
+			rec.Err = err
+		}
+
+		res.Rejected++
+		rejected = append(rejected, []string{strconv.Itoa(rec.Line), rec.Call.CallID, rec.Err.Error()})
+	}
+
+	if err := report.WriteAll(rejected); err != nil {
+		return fmt.Errorf("error escribiendo reporte de errores: %w", err)
+	}
+	if err := imp.saveCheckpoint(batch[len(batch)-1].Line); err != nil {
+		return err
+	}
+	imp.progress(*res)
+	return nil
+}
+
+// execute corre el caso de uso para las filas válidas del lote, hasta
+// imp.workers a la vez, y devuelve el error de cada fila.
+func (imp *Importer) execute(batch []Record) []error {
+	errs := make([]error, len(batch))
+	sem := make(chan struct{}, imp.workers)
+	var wg sync.WaitGroup
+	for i, rec := range batch {
+		if rec.Err != nil {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, call model.NewIncomingCall) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = imp.useCase.Execute(call)
+		}(i, rec.Call)
+	}
+	wg.Wait()
+	return errs
+}
+
+func (imp *Importer) loadCheckpoint() (int, error) {
+	if imp.checkpointPath == "" {
+		return 0, nil
+	}
+	data, err := os.ReadFile(imp.checkpointPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error leyendo checkpoint: %w", err)
+	}
+	line, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("checkpoint inválido en %s: %w", imp.checkpointPath, err)
+	}
+	return line, nil
+}
+
+func (imp *Importer) saveCheckpoint(line int) error {
+	if imp.checkpointPath == "" {
+		return nil
+	}
+	tmp := imp.checkpointPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(line)), 0o644); err != nil {
+		return fmt.Errorf("error guardando checkpoint: %w", err)
+	}
+	return os.Rename(tmp, imp.checkpointPath)
+}
@@ -0,0 +1,224 @@
+package cdr
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockIncomingCallUseCase struct {
+	calls    []model.NewIncomingCall
+	failOn   string
+	rejectOn string
+}
+
+func (m *mockIncomingCallUseCase) Execute(call model.NewIncomingCall) error {
+	if call.CallID == m.failOn {
+		return errors.New("db down")
+	}
+	if call.CallID == m.rejectOn {
+		return &model.ValidationError{Entity: "new_incoming_call", Fields: []model.FieldError{{Field: "caller", Message: "número imposible"}}}
+	}
+	m.calls = append(m.calls, call)
+	return nil
+}
+
+const carrierCSV = `id,origen,destino,segundos,inicio
+11111111-1111-1111-1111-111111111111,+1111,+2222,60,2024-08-29T12:00:00Z
+not-a-uuid,+1111,+2222,60,2024-08-29T12:00:00Z
+22222222-2222-2222-2222-222222222222,+1111,+2222,abc,2024-08-29T12:00:00Z
+33333333-3333-3333-3333-333333333333,+3333,+4444,90,2024-08-29T13:00:00Z
+`
+
+func carrierMapping(t *testing.T) ColumnMapping {
+	m, err := ParseColumnMapping("call_id=id,caller=origen,receiver=destino,duration_in_seconds=segundos,start_timestamp=inicio")
+	assert.NoError(t, err)
+	return m
+}
+
+func TestImporter_Run_CSVWithMapping(t *testing.T) {
+	uc := &mockIncomingCallUseCase{}
+	reader, err := NewCSVReader(strings.NewReader(carrierCSV), carrierMapping(t))
+	assert.NoError(t, err)
+
+	var report bytes.Buffer
+	res, err := NewImporter(uc, 2, "", &report, func(Result) {}).Run(reader)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Result{Read: 4, Imported: 2, Rejected: 2}, res)
+	assert.Len(t, uc.calls, 2)
+	assert.Equal(t, "+3333", uc.calls[1].Caller)
+	assert.Equal(t, 90, uc.calls[1].DurationInSec)
+	assert.True(t, strings.HasPrefix(report.String(), "line,call_id,error\n"))
+	assert.Contains(t, report.String(), "3,not-a-uuid,")
+	assert.Contains(t, report.String(), "4,22222222-2222-2222-2222-222222222222,")
+}
+
+func TestImporter_Run_ResumesFromCheckpoint(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "cdr.checkpoint")
+
+	uc := &mockIncomingCallUseCase{failOn: "33333333-3333-3333-3333-333333333333"}
+	reader, _ := NewCSVReader(strings.NewReader(carrierCSV), carrierMapping(t))
+	_, err := NewImporter(uc, 2, checkpoint, &bytes.Buffer{}, func(Result) {}).Run(reader)
+	assert.Error(t, err)
+
+	saved, _ := os.ReadFile(checkpoint)
+	assert.Equal(t, "3", string(saved))
+
+	uc = &mockIncomingCallUseCase{}
+	reader, _ = NewCSVReader(strings.NewReader(carrierCSV), carrierMapping(t))
+	res, err := NewImporter(uc, 2, checkpoint, &bytes.Buffer{}, func(Result) {}).Run(reader)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, res.Skipped)
+	assert.Equal(t, 1, res.Imported)
+	assert.Equal(t, "33333333-3333-3333-3333-333333333333", uc.calls[0].CallID)
+}
+
+func TestImporter_Run_ResumeDoesNotDuplicateRejections(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "cdr.checkpoint")
+	var report bytes.Buffer
+
+	// El segundo lote (líneas 4 y 5) rechaza la línea 4 y se corta en la 5
+	uc := &mockIncomingCallUseCase{failOn: "33333333-3333-3333-3333-333333333333"}
+	reader, _ := NewCSVReader(strings.NewReader(carrierCSV), carrierMapping(t))
+	_, err := NewImporter(uc, 2, checkpoint, &report, func(Result) {}).Run(reader)
+	assert.Error(t, err)
+
+	reader, _ = NewCSVReader(strings.NewReader(carrierCSV), carrierMapping(t))
+	_, err = NewImporter(&mockIncomingCallUseCase{}, 2, checkpoint, &report, func(Result) {}).Run(reader)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, strings.Count(report.String(), "line,call_id,error"))
+	assert.Equal(t, 1, strings.Count(report.String(), "3,not-a-uuid,"))
+	assert.Equal(t, 1, strings.Count(report.String(), "4,22222222-2222-2222-2222-222222222222,"))
+}
+
+func TestImporter_Run_ReportsPhysicalLines(t *testing.T) {
+	input := `id,origen,destino,segundos,inicio
+
+11111111-1111-1111-1111-111111111111,"+1111
+",+2222,abc,2024-08-29T12:00:00Z
+22222222-2222-2222-2222-222222222222,+1111,+2222,abc,2024-08-29T12:00:00Z
+`
+	reader, err := NewCSVReader(strings.NewReader(input), carrierMapping(t))
+	assert.NoError(t, err)
+
+	var report bytes.Buffer
+	_, err = NewImporter(&mockIncomingCallUseCase{}, 10, "", &report, func(Result) {}).Run(reader)
+
+	assert.NoError(t, err)
+	assert.Contains(t, report.String(), "\n3,11111111-1111-1111-1111-111111111111,")
+	assert.Contains(t, report.String(), "\n5,22222222-2222-2222-2222-222222222222,")
+}
+
+func TestImporter_Run_RejectsRowsWithPermanentUseCaseErrors(t *testing.T) {
+	uc := &mockIncomingCallUseCase{rejectOn: "11111111-1111-1111-1111-111111111111"}
+	reader, _ := NewCSVReader(strings.NewReader(carrierCSV), carrierMapping(t))
+
+	var report bytes.Buffer
+	res, err := NewImporter(uc, 10, "", &report, func(Result) {}).Run(reader)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Result{Read: 4, Imported: 1, Rejected: 3}, res)
+	assert.Contains(t, report.String(), "2,11111111-1111-1111-1111-111111111111,new_incoming_call inválido: caller: número imposible")
+}
+
+// concurrentUseCase retiene cada fila hasta que se cierra release.
+type concurrentUseCase struct {
+	mu      sync.Mutex
+	calls   []string
+	arrived chan struct{}
+	release chan struct{}
+}
+
+func (c *concurrentUseCase) Execute(call model.NewIncomingCall) error {
+	c.arrived <- struct{}{}
+	<-c.release
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, call.CallID)
+	return nil
+}
+
+func TestImporter_Run_WithWorkers(t *testing.T) {
+	uc := &concurrentUseCase{arrived: make(chan struct{}, 4), release: make(chan struct{})}
+	concurrent := make(chan bool, 1)
+	go func() {
+		defer close(uc.release)
+		for i := 0; i < 2; i++ {
+			select {
+			case <-uc.arrived:
+			case <-time.After(2 * time.Second):
+				concurrent <- false
+				return
+			}
+		}
+		concurrent <- true
+	}()
+	reader, _ := NewCSVReader(strings.NewReader(carrierCSV), carrierMapping(t))
+
+	var report bytes.Buffer
+	res, err := NewImporter(uc, 10, "", &report, func(Result) {}, WithWorkers(2)).Run(reader)
+
+	assert.NoError(t, err)
+	assert.True(t, <-concurrent, "las dos filas válidas se procesan a la vez")
+	assert.Equal(t, Result{Read: 4, Imported: 2, Rejected: 2}, res)
+	assert.ElementsMatch(t, []string{"11111111-1111-1111-1111-111111111111", "33333333-3333-3333-3333-333333333333"}, uc.calls)
+}
+
+func TestImporter_Run_JSONLines(t *testing.T) {
+	input := `{"call_id":"11111111-1111-1111-1111-111111111111","caller":"+1","receiver":"+2","duration_in_seconds":30,"start_timestamp":"2024-08-29T12:00:00Z"}
+
+{not json}
+`
+	uc := &mockIncomingCallUseCase{}
+	res, err := NewImporter(uc, 10, "", &bytes.Buffer{}, func(Result) {}).Run(NewJSONLinesReader(strings.NewReader(input), DefaultColumnMapping()))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Imported)
+	assert.Equal(t, 1, res.Rejected)
+	assert.Equal(t, 30, uc.calls[0].DurationInSec)
+}
+
+func TestImporter_Run_CarrierTimestamps(t *testing.T) {
+	input := `{"call_id":"11111111-1111-1111-1111-111111111111","caller":"+1","receiver":"+2","duration_in_seconds":30,"start_timestamp":1724932800000}
+{"call_id":"22222222-2222-2222-2222-222222222222","caller":"+1","receiver":"+2","duration_in_seconds":30,"start_timestamp":"2024-08-29 09:00:00"}
+{"call_id":"33333333-3333-3333-3333-333333333333","caller":"+1","receiver":"+2","duration_in_seconds":30,"start_timestamp":"29/08/2024"}
+`
+	mapping := DefaultColumnMapping()
+	var err error
+	mapping.Timestamps, err = model.NewTimestampParser(nil, time.FixedZone("ART", -3*3600))
+	assert.NoError(t, err)
+
+	uc := &mockIncomingCallUseCase{}
+	var report bytes.Buffer
+	res, err := NewImporter(uc, 10, "", &report, func(Result) {}).Run(NewJSONLinesReader(strings.NewReader(input), mapping))
+
+	assert.NoError(t, err)
+	assert.Equal(t, Result{Read: 3, Imported: 2, Rejected: 1}, res)
+	want := time.Date(2024, 8, 29, 12, 0, 0, 0, time.UTC)
+	for _, c := range uc.calls {
+		assert.Equal(t, want, c.StartTimestamp, c.CallID)
+	}
+	assert.Contains(t, report.String(), "3,33333333-3333-3333-3333-333333333333,\"start_timestamp inválido")
+}
+
+func TestNewCSVReader_MissingColumn(t *testing.T) {
+	_, err := NewCSVReader(strings.NewReader("call_id,caller\n"), DefaultColumnMapping())
+	assert.Error(t, err)
+}
+
+func TestParseColumnMapping_UnknownField(t *testing.T) {
+	_, err := ParseColumnMapping("cost=precio")
+	assert.Error(t, err)
+}
@@ -0,0 +1,225 @@
+package cdr
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+)
+
+// ColumnMapping indica qué columna (CSV) o clave (JSON-lines) del archivo del
+// carrier corresponde a cada campo de model.NewIncomingCall, y cómo leer
+// start_timestamp (formatos y zona horaria del carrier).
+type ColumnMapping struct {
+	CallID         string
+	Caller         string
+	Receiver       string
+	DurationInSec  string
+	StartTimestamp string
+	Timestamps     *model.TimestampParser
+}
+
+func DefaultColumnMapping() ColumnMapping {
+	return ColumnMapping{
+		CallID:         "call_id",
+		Caller:         "caller",
+		Receiver:       "receiver",
+		DurationInSec:  "duration_in_seconds",
+		StartTimestamp: "start_timestamp",
+		Timestamps:     model.DefaultTimestampParser(),
+	}
+}
+
+// parseStart deja start_timestamp vacío para que lo reporte la validación.
+func (m ColumnMapping) parseStart(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	p := m.Timestamps
+	if p == nil {
+		p = model.DefaultTimestampParser()
+	}
+	start, err := p.Parse(raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("start_timestamp inválido: %w", err)
+	}
+	return start, nil
+}
+
+// ParseColumnMapping aplica overrides "campo=columna,..." sobre el mapping por defecto.
+func ParseColumnMapping(spec string) (ColumnMapping, error) {
+	m := DefaultColumnMapping()
+	if strings.TrimSpace(spec) == "" {
+		return m, nil
+	}
+
+	fields := map[string]*string{
+		"call_id":             &m.CallID,
+		"caller":              &m.Caller,
+		"receiver":            &m.Receiver,
+		"duration_in_seconds": &m.DurationInSec,
+		"start_timestamp":     &m.StartTimestamp,
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return m, fmt.Errorf("mapping inválido %q, se espera campo=columna", pair)
+		}
+		target, ok := fields[strings.TrimSpace(kv[0])]
+		if !ok {
+			return m, fmt.Errorf("campo desconocido en mapping: %s", kv[0])
+		}
+		*target = strings.TrimSpace(kv[1])
+	}
+	return m, nil
+}
+
+// Record es una fila leída del archivo. Err indica que la fila no pudo
+// convertirse a NewIncomingCall.
+type Record struct {
+	Line int
+	Call model.NewIncomingCall
+	Err  error
+}
+
+type Reader interface {
+	// Next devuelve io.EOF cuando no hay más filas.
+	Next() (Record, error)
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	mapping ColumnMapping
+	index   map[string]int
+}
+
+// NewCSVReader lee un CSV con encabezado; las columnas se resuelven por nombre.
+func NewCSVReader(r io.Reader, mapping ColumnMapping) (Reader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo encabezado CSV: %w", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.TrimSpace(h)] = i
+	}
+	for _, col := range []string{mapping.CallID, mapping.Caller, mapping.Receiver, mapping.DurationInSec, mapping.StartTimestamp} {
+		if _, ok := index[col]; !ok {
+			return nil, fmt.Errorf("columna %q no encontrada en el encabezado", col)
+		}
+	}
+
+	return &csvReader{r: cr, mapping: mapping, index: index}, nil
+}
+
+func (c *csvReader) Next() (Record, error) {
+	row, err := c.r.Read()
+	if err == io.EOF {
+		return Record{}, io.EOF
+	}
+	if err != nil {
+		if pe, ok := err.(*csv.ParseError); ok {
+			return Record{Line: pe.StartLine, Err: err}, nil
+		}
+		return Record{}, err
+	}
+	// Line es la línea física donde empieza la fila: no coincide con el número
+	// de registro si hay líneas vacías o campos entre comillas con saltos de línea.
+	line, _ := c.r.FieldPos(0)
+
+	get := func(col string) string {
+		if i := c.index[col]; i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	rec := Record{Line: line}
+	duration, err := strconv.Atoi(get(c.mapping.DurationInSec))
+	if err != nil {
+		rec.Err = fmt.Errorf("duración inválida: %w", err)
+	}
+	start, err := c.mapping.parseStart(get(c.mapping.StartTimestamp))
+	if err != nil && rec.Err == nil {
+		rec.Err = err
+	}
+	rec.Call = model.NewIncomingCall{
+		CallID:         get(c.mapping.CallID),
+		Caller:         get(c.mapping.Caller),
+		Receiver:       get(c.mapping.Receiver),
+		DurationInSec:  duration,
+		StartTimestamp: start,
+	}
+	return rec, nil
+}
+
+type jsonLinesReader struct {
+	s       *bufio.Scanner
+	mapping ColumnMapping
+	line    int
+}
+
+// NewJSONLinesReader lee un objeto JSON por línea usando el mapping como claves.
+func NewJSONLinesReader(r io.Reader, mapping ColumnMapping) Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	return &jsonLinesReader{s: s, mapping: mapping}
+}
+
+func (j *jsonLinesReader) Next() (Record, error) {
+	for j.s.Scan() {
+		j.line++
+		text := strings.TrimSpace(j.s.Text())
+		if text == "" {
+			continue
+		}
+
+		rec := Record{Line: j.line}
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &raw); err != nil {
+			rec.Err = fmt.Errorf("JSON inválido: %w", err)
+			return rec, nil
+		}
+
+		str := func(key string) string {
+			switch v := raw[key].(type) {
+			case string:
+				return v
+			case float64:
+				return strconv.FormatFloat(v, 'f', -1, 64)
+			}
+			return ""
+		}
+
+		duration, err := strconv.Atoi(str(j.mapping.DurationInSec))
+		if err != nil {
+			rec.Err = fmt.Errorf("duración inválida: %w", err)
+		}
+		start, err := j.mapping.parseStart(str(j.mapping.StartTimestamp))
+		if err != nil && rec.Err == nil {
+			rec.Err = err
+		}
+		rec.Call = model.NewIncomingCall{
+			CallID:         str(j.mapping.CallID),
+			Caller:         str(j.mapping.Caller),
+			Receiver:       str(j.mapping.Receiver),
+			DurationInSec:  duration,
+			StartTimestamp: start,
+		}
+		return rec, nil
+	}
+	if err := j.s.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
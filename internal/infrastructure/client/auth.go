@@ -0,0 +1,238 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator agrega credenciales a cada request a la API de costos.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// Los authenticators con credenciales cacheadas las descartan cuando la API
+// responde 401, para que el próximo intento pida unas nuevas.
+type invalidator interface {
+	Invalidate()
+}
+
+// WithAuth configura cómo se autentica cada request.
+func WithAuth(auth Authenticator) Option {
+	return func(c *HttpCostClient) {
+		c.auth = auth
+	}
+}
+
+// WithTransport reemplaza el transporte HTTP (p. ej. el de NewMTLSTransport).
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *HttpCostClient) {
+		c.http.Transport = rt
+	}
+}
+
+// APIKeyAuth envía una API key fija en un header.
+type APIKeyAuth struct {
+	header string
+	key    string
+}
+
+func NewAPIKeyAuth(header, key string) *APIKeyAuth {
+	if header == "" {
+		header = "X-API-Key"
+	}
+	return &APIKeyAuth{header: header, key: key}
+}
+
+func (a *APIKeyAuth) Authenticate(req *http.Request) error {
+	req.Header.Set(a.header, a.key)
+	return nil
+}
+
+// OAuth2ClientCredentials obtiene bearer tokens con el grant
+// client_credentials y los cachea hasta RefreshBefore antes de que venzan.
+type OAuth2ClientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	http         *http.Client
+	// RefreshBefore es cuánto antes del vencimiento se pide un token nuevo.
+	RefreshBefore time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	now       func() time.Time
+}
+
+func NewOAuth2ClientCredentials(tokenURL, clientID, clientSecret string, scopes []string, httpClient *http.Client) *OAuth2ClientCredentials {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &OAuth2ClientCredentials{
+		tokenURL:      tokenURL,
+		clientID:      clientID,
+		clientSecret:  clientSecret,
+		scopes:        scopes,
+		http:          httpClient,
+		RefreshBefore: 30 * time.Second,
+		now:           time.Now,
+	}
+}
+
+func (a *OAuth2ClientCredentials) Authenticate(req *http.Request) error {
+	token, err := a.Token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Token devuelve el token cacheado o pide uno nuevo si está por vencer.
+func (a *OAuth2ClientCredentials) Token() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && a.now().Before(a.expiresAt.Add(-a.RefreshBefore)) {
+		return a.token, nil
+	}
+
+	token, ttl, err := a.fetch()
+	if err != nil {
+		return "", err
+	}
+	a.token = token
+	a.expiresAt = a.now().Add(ttl)
+	return token, nil
+}
+
+func (a *OAuth2ClientCredentials) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.token = ""
+}
+
+func (a *OAuth2ClientCredentials) fetch() (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.scopes) > 0 {
+		form.Set("scope", strings.Join(a.scopes, " "))
+	}
+	req, err := http.NewRequest(http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("error pidiendo token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("error pidiendo token: status code %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", 0, fmt.Errorf("error parseando token: %w", err)
+	}
+	if body.AccessToken == "" {
+		return "", 0, errors.New("respuesta de token sin access_token")
+	}
+	ttl := time.Duration(body.ExpiresIn) * time.Second
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	return body.AccessToken, ttl, nil
+}
+
+// NewMTLSTransport arma un transporte que presenta el certificado de cliente
+// y, si caFile no es vacío, solo confía en esa CA para el servidor.
+func NewMTLSTransport(certFile, keyFile, caFile string) (*http.Transport, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error cargando certificado de cliente: %w", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("CA inválida en %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// AuthConfig describe la autenticación contra la API de costos.
+// Mode: none | api_key | oauth2. El mTLS se activa si hay TLSCert y TLSKey,
+// y se puede combinar con cualquier Mode.
+type AuthConfig struct {
+	Mode         string
+	APIKeyHeader string
+	APIKey       string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	TLSCert      string
+	TLSKey       string
+	TLSCA        string
+}
+
+// Options devuelve las opciones de HttpCostClient para esta configuración.
+func (c AuthConfig) Options() ([]Option, error) {
+	var opts []Option
+	var transport *http.Transport
+	if c.TLSCert != "" || c.TLSKey != "" {
+		var err error
+		if transport, err = NewMTLSTransport(c.TLSCert, c.TLSKey, c.TLSCA); err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithTransport(transport))
+	}
+
+	switch c.Mode {
+	case "", "none":
+	case "api_key":
+		if c.APIKey == "" {
+			return nil, errors.New("auth api_key sin API key")
+		}
+		opts = append(opts, WithAuth(NewAPIKeyAuth(c.APIKeyHeader, c.APIKey)))
+	case "oauth2":
+		if c.TokenURL == "" || c.ClientID == "" {
+			return nil, errors.New("auth oauth2 requiere token URL y client id")
+		}
+		tokenClient := &http.Client{Timeout: 10 * time.Second}
+		if transport != nil {
+			tokenClient.Transport = transport
+		}
+		opts = append(opts, WithAuth(NewOAuth2ClientCredentials(c.TokenURL, c.ClientID, c.ClientSecret, c.Scopes, tokenClient)))
+	default:
+		return nil, fmt.Errorf("modo de auth desconocido: %s", c.Mode)
+	}
+	return opts, nil
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/client"
+	"phonecall-cost-processor-service/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var okCost = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(`{"currency":"USD","cost":1.5}`))
+})
+
+func TestHttpCostClient_APIKey(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secreto" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		okCost(w, r)
+	}))
+	defer api.Close()
+
+	resp, err := NewHttpCostClient(api.URL, WithAuth(NewAPIKeyAuth("", "secreto"))).GetCallCost("id")
+	assert.NoError(t, err)
+	assert.Equal(t, model.NewMoney(150, "USD"), resp.Cost)
+
+	// Credenciales inválidas: error técnico, no un 4xx que marque la llamada INVALID
+	_, err = NewHttpCostClient(api.URL, WithAuth(NewAPIKeyAuth("", "otro")), WithRetries(1, 0)).GetCallCost("id")
+	var apiErr *client.CostAPIError
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &apiErr))
+}
+
+func TestHttpCostClient_OAuth2CachesToken(t *testing.T) {
+	tokens := mock.NewTokenServer("svc", "s3cr3t", time.Hour)
+	tokenSrv := httptest.NewServer(tokens)
+	defer tokenSrv.Close()
+	api := httptest.NewServer(tokens.RequireBearer(okCost))
+	defer api.Close()
+
+	auth := NewOAuth2ClientCredentials(tokenSrv.URL, "svc", "s3cr3t", []string{"costs:read"}, nil)
+	c := NewHttpCostClient(api.URL, WithAuth(auth))
+	for i := 0; i < 3; i++ {
+		_, err := c.GetCallCost("id")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, tokens.Issued())
+}
+
+func TestHttpCostClient_OAuth2RefreshesBeforeExpiry(t *testing.T) {
+	tokens := mock.NewTokenServer("svc", "s3cr3t", time.Minute)
+	tokenSrv := httptest.NewServer(tokens)
+	defer tokenSrv.Close()
+
+	now := time.Now()
+	auth := NewOAuth2ClientCredentials(tokenSrv.URL, "svc", "s3cr3t", nil, nil)
+	auth.now = func() time.Time { return now }
+
+	first, err := auth.Token()
+	assert.NoError(t, err)
+
+	now = now.Add(29 * time.Second)
+	same, _ := auth.Token()
+	assert.Equal(t, first, same)
+
+	// Faltan menos de RefreshBefore (30s) para el vencimiento
+	now = now.Add(2 * time.Second)
+	renewed, _ := auth.Token()
+	assert.NotEqual(t, first, renewed)
+	assert.Equal(t, 2, tokens.Issued())
+}
+
+func TestHttpCostClient_OAuth2RenewsRevokedToken(t *testing.T) {
+	tokens := mock.NewTokenServer("svc", "s3cr3t", time.Hour)
+	tokenSrv := httptest.NewServer(tokens)
+	defer tokenSrv.Close()
+	api := httptest.NewServer(tokens.RequireBearer(okCost))
+	defer api.Close()
+
+	c := NewHttpCostClient(api.URL, WithAuth(NewOAuth2ClientCredentials(tokenSrv.URL, "svc", "s3cr3t", nil, nil)), WithRetries(1, 0))
+	_, err := c.GetCallCost("id")
+	assert.NoError(t, err)
+
+	tokens.Revoke()
+	_, err = c.GetCallCost("id")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tokens.Issued())
+}
+
+func TestHttpCostClient_OAuth2InvalidClient(t *testing.T) {
+	tokens := mock.NewTokenServer("svc", "s3cr3t", time.Hour)
+	tokenSrv := httptest.NewServer(tokens)
+	defer tokenSrv.Close()
+	api := httptest.NewServer(tokens.RequireBearer(okCost))
+	defer api.Close()
+
+	c := NewHttpCostClient(api.URL, WithAuth(NewOAuth2ClientCredentials(tokenSrv.URL, "svc", "mal", nil, nil)), WithRetries(1, 0))
+	_, err := c.GetCallCost("id")
+	assert.ErrorContains(t, err, "error pidiendo token")
+}
+
+func TestHttpCostClient_MTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newCA(t)
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", ca.Raw)
+	serverCert := newLeaf(t, ca, caKey, "server", x509.ExtKeyUsageServerAuth)
+	clientCert := newLeaf(t, ca, caKey, "client", x509.ExtKeyUsageClientAuth)
+	writePEM(t, filepath.Join(dir, "client.pem"), "CERTIFICATE", clientCert.Certificate[0])
+	keyDER, err := x509.MarshalPKCS8PrivateKey(clientCert.PrivateKey)
+	assert.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "client-key.pem"), "PRIVATE KEY", keyDER)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	api := httptest.NewUnstartedServer(okCost)
+	api.TLS = &tls.Config{Certificates: []tls.Certificate{serverCert}, ClientCAs: pool, ClientAuth: tls.RequireAndVerifyClientCert}
+	api.StartTLS()
+	defer api.Close()
+
+	opts, err := AuthConfig{
+		TLSCert: filepath.Join(dir, "client.pem"),
+		TLSKey:  filepath.Join(dir, "client-key.pem"),
+		TLSCA:   filepath.Join(dir, "ca.pem"),
+	}.Options()
+	assert.NoError(t, err)
+
+	resp, err := NewHttpCostClient(api.URL, opts...).GetCallCost("id")
+	assert.NoError(t, err)
+	assert.Equal(t, model.NewMoney(150, "USD"), resp.Cost)
+
+	// Sin certificado de cliente el handshake falla
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	_, err = NewHttpCostClient(api.URL, WithTransport(transport), WithRetries(1, 0)).GetCallCost("id")
+	assert.Error(t, err)
+}
+
+func TestAuthConfig_Options(t *testing.T) {
+	_, err := AuthConfig{Mode: "api_key"}.Options()
+	assert.Error(t, err)
+	_, err = AuthConfig{Mode: "oauth2"}.Options()
+	assert.Error(t, err)
+	_, err = AuthConfig{Mode: "kerberos"}.Options()
+	assert.Error(t, err)
+	_, err = AuthConfig{TLSCert: "no-existe.pem", TLSKey: "no-existe.pem"}.Options()
+	assert.Error(t, err)
+
+	opts, err := AuthConfig{Mode: "none"}.Options()
+	assert.NoError(t, err)
+	assert.Empty(t, opts)
+}
+
+func newCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert, key
+}
+
+func newLeaf(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, name string, usage x509.ExtKeyUsage) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	assert.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
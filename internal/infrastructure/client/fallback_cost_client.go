@@ -0,0 +1,81 @@
+package client
+
+import (
+	"errors"
+	"log"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/client"
+)
+
+// FallbackCostClient consulta primary y, si falla por algo que no sea un
+// error 4xx (la llamada es inválida para el proveedor), usa fallback.
+type FallbackCostClient struct {
+	primary  client.CostClient
+	fallback client.CostClient
+}
+
+func NewFallbackCostClient(primary, fallback client.CostClient) *FallbackCostClient {
+	return &FallbackCostClient{primary: primary, fallback: fallback}
+}
+
+func (c *FallbackCostClient) GetCallCost(callID string) (*model.CostResponse, error) {
+	resp, err := c.primary.GetCallCost(callID)
+	if err == nil {
+		return resp, nil
+	}
+	if isClientError(err) {
+		return nil, err
+	}
+
+	log.Printf("🔁 Usando proveedor de costo alternativo para call_id=%s: %v", callID, err)
+	resp, fbErr := c.fallback.GetCallCost(callID)
+	if fbErr != nil {
+		return nil, errors.Join(err, fbErr)
+	}
+	return resp, nil
+}
+
+// GetCallCosts reintenta con fallback solo las llamadas que primary no pudo
+// tarifar por algo distinto de un 4xx.
+func (c *FallbackCostClient) GetCallCosts(callIDs []string) (map[string]client.BatchItem, error) {
+	items, err := c.primary.GetCallCosts(callIDs)
+	if err != nil {
+		if isClientError(err) {
+			return nil, err
+		}
+		items = make(map[string]client.BatchItem, len(callIDs))
+		for _, id := range callIDs {
+			items[id] = client.BatchItem{Err: err}
+		}
+	}
+
+	var retry []string
+	for _, id := range callIDs {
+		if it := items[id]; it.Err != nil && !isClientError(it.Err) {
+			retry = append(retry, id)
+		}
+	}
+	if len(retry) == 0 {
+		return items, nil
+	}
+
+	log.Printf("🔁 Usando proveedor de costo alternativo para %d de %d llamadas", len(retry), len(callIDs))
+	fallback, fbErr := c.fallback.GetCallCosts(retry)
+	for _, id := range retry {
+		it := client.BatchItem{Err: fbErr}
+		if fbErr == nil {
+			it = fallback[id]
+		}
+		if it.Err != nil {
+			it.Err = errors.Join(items[id].Err, it.Err)
+		}
+		items[id] = it
+	}
+	return items, nil
+}
+
+func isClientError(err error) bool {
+	var apiErr *client.CostAPIError
+	return errors.As(err, &apiErr) && apiErr.IsClientError()
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/client"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubCostClient struct {
+	resp    *model.CostResponse
+	err     error
+	called  bool
+	batches [][]string
+}
+
+func (s *stubCostClient) GetCallCost(callID string) (*model.CostResponse, error) {
+	s.called = true
+	return s.resp, s.err
+}
+
+func (s *stubCostClient) GetCallCosts(callIDs []string) (map[string]client.BatchItem, error) {
+	s.batches = append(s.batches, callIDs)
+	return client.GetEach(s.GetCallCost, callIDs), nil
+}
+
+func TestFallbackCostClient_UsesPrimaryWhenOK(t *testing.T) {
+	primary := &stubCostClient{resp: &model.CostResponse{Cost: model.NewMoney(100, "USD")}}
+	fallback := &stubCostClient{}
+
+	resp, err := NewFallbackCostClient(primary, fallback).GetCallCost("id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.NewMoney(100, "USD"), resp.Cost)
+	assert.False(t, fallback.called)
+}
+
+func TestFallbackCostClient_FallsBackOnServerError(t *testing.T) {
+	primary := &stubCostClient{err: errors.New("cost API falló luego de 3 intentos")}
+	fallback := &stubCostClient{resp: &model.CostResponse{Cost: model.NewMoney(250, "ARS")}}
+
+	resp, err := NewFallbackCostClient(primary, fallback).GetCallCost("id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, model.NewMoney(250, "ARS"), resp.Cost)
+}
+
+func TestFallbackCostClient_DoesNotFallBackOnClientError(t *testing.T) {
+	primary := &stubCostClient{err: &client.CostAPIError{StatusCode: 404, Err: errors.New("client error")}}
+	fallback := &stubCostClient{}
+
+	_, err := NewFallbackCostClient(primary, fallback).GetCallCost("id")
+
+	var apiErr *client.CostAPIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.False(t, fallback.called)
+}
+
+func TestFallbackCostClient_ReturnsBothErrors(t *testing.T) {
+	primaryErr := errors.New("timeout")
+	fallbackErr := model.ErrNoRate
+	c := NewFallbackCostClient(&stubCostClient{err: primaryErr}, &stubCostClient{err: fallbackErr})
+
+	_, err := c.GetCallCost("id")
+
+	assert.ErrorIs(t, err, primaryErr)
+	assert.ErrorIs(t, err, fallbackErr)
+}
+
+// itemsCostClient responde el lote con resultados fijos por call_id.
+type itemsCostClient struct {
+	items map[string]client.BatchItem
+}
+
+func (s *itemsCostClient) GetCallCost(callID string) (*model.CostResponse, error) {
+	it := s.items[callID]
+	return it.Response, it.Err
+}
+
+func (s *itemsCostClient) GetCallCosts(callIDs []string) (map[string]client.BatchItem, error) {
+	return client.GetEach(s.GetCallCost, callIDs), nil
+}
+
+func TestFallbackCostClient_BatchRetriesOnlyServerErrors(t *testing.T) {
+	primary := &itemsCostClient{items: map[string]client.BatchItem{
+		"ok":        {Response: &model.CostResponse{Cost: model.NewMoney(100, "USD")}},
+		"not-found": {Err: &client.CostAPIError{StatusCode: 404, Err: errors.New("call_not_found")}},
+		"down":      {Err: &client.CostAPIError{StatusCode: 500, Err: errors.New("internal")}},
+	}}
+	fallback := &stubCostClient{resp: &model.CostResponse{Cost: model.NewMoney(250, "ARS")}}
+
+	items, err := NewFallbackCostClient(primary, fallback).GetCallCosts([]string{"ok", "not-found", "down"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"down"}}, fallback.batches)
+	assert.Equal(t, model.NewMoney(100, "USD"), items["ok"].Response.Cost)
+	assert.Error(t, items["not-found"].Err)
+	assert.Equal(t, model.NewMoney(250, "ARS"), items["down"].Response.Cost)
+}
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -16,33 +18,231 @@ type CostClient interface {
 }
 
 type HttpCostClient struct {
-	baseURL string
+	baseURL    string
+	maxRetries int
+	backoff    time.Duration
+	http       *http.Client
+	auth       Authenticator
+	batchSize  int
 }
 
-func NewHttpCostClient(baseURL string) *HttpCostClient {
-	return &HttpCostClient{baseURL: baseURL}
+type Option func(*HttpCostClient)
+
+// WithRetries configura la cantidad de intentos y el backoff inicial.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *HttpCostClient) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// WithTimeout limita la duración de cada intento; un timeout cuenta como
+// fallo reintentable, igual que un 5xx.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *HttpCostClient) {
+		c.http.Timeout = timeout
+	}
+}
+
+// WithBatchSize limita la cantidad de call_ids por request a POST /calls/costs.
+func WithBatchSize(n int) Option {
+	return func(c *HttpCostClient) {
+		c.batchSize = n
+	}
+}
+
+func NewHttpCostClient(baseURL string, opts ...Option) *HttpCostClient {
+	c := &HttpCostClient{baseURL: baseURL, maxRetries: 3, backoff: time.Second, http: &http.Client{}, batchSize: 100}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *HttpCostClient) GetCallCost(callID string) (*model.CostResponse, error) {
+	url := fmt.Sprintf("%s/calls/%s/cost", c.baseURL, callID)
+	resp, err := c.do(func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, url, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var costResp model.CostResponse
+	if err := json.NewDecoder(resp.Body).Decode(&costResp); err != nil {
+		return nil, fmt.Errorf("error parseando respuesta de costos: %w", err)
+	}
+	return &costResp, nil
+}
+
+type batchRequest struct {
+	CallIDs []string `json:"call_ids"`
+}
+
+// Cada resultado es {"call_id", "currency", "cost"} o {"call_id", "error"}.
+type batchResponse struct {
+	Results []json.RawMessage `json:"results"`
+}
+
+type batchResult struct {
+	CallID string          `json:"call_id"`
+	Error  *batchItemError `json:"error,omitempty"`
+}
+
+type batchItemError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// itemServerError es un 5xx de un resultado del lote. Como en GetCallCost,
+// se reintenta y no es un *client.CostAPIError.
+type itemServerError struct {
+	batchItemError
+}
+
+func (e *itemServerError) Error() string {
+	return fmt.Sprintf("status code %d: %s: %s", e.Status, e.Code, e.Message)
+}
+
+// GetCallCosts usa POST /calls/costs en requests de hasta batchSize ids. Cada
+// resultado trae el costo o un error con el status que tendría la consulta
+// individual.
+func (c *HttpCostClient) GetCallCosts(callIDs []string) (map[string]client.BatchItem, error) {
+	items := make(map[string]client.BatchItem, len(callIDs))
+	for start := 0; start < len(callIDs); start += c.batchSize {
+		end := start + c.batchSize
+		if end > len(callIDs) {
+			end = len(callIDs)
+		}
+		if err := c.postBatchWithRetries(callIDs[start:end], items); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// postBatchWithRetries reenvía, con los mismos intentos y backoff que
+// GetCallCost, los ids cuyo resultado fue un 5xx.
+func (c *HttpCostClient) postBatchWithRetries(callIDs []string, items map[string]client.BatchItem) error {
+	pending := callIDs
+	backoff := c.backoff
+	for attempt := 1; ; attempt++ {
+		if err := c.postBatch(pending, items); err != nil {
+			return err
+		}
+		var failed []string
+		for _, id := range pending {
+			var serverErr *itemServerError
+			if errors.As(items[id].Err, &serverErr) {
+				failed = append(failed, id)
+			}
+		}
+		if len(failed) == 0 {
+			return nil
+		}
+		if attempt >= c.maxRetries {
+			for _, id := range failed {
+				items[id] = client.BatchItem{Err: fmt.Errorf("cost API falló luego de %d intentos: %w", attempt, items[id].Err)}
+			}
+			return nil
+		}
+		log.Printf("⚠️ Fallo en cost API para %d llamadas del lote (intento %d)", len(failed), attempt)
+		time.Sleep(backoff)
+		backoff *= 2
+		for _, id := range failed {
+			delete(items, id)
+		}
+		pending = failed
+	}
+}
+
+func (c *HttpCostClient) postBatch(callIDs []string, items map[string]client.BatchItem) error {
+	payload, err := json.Marshal(batchRequest{CallIDs: callIDs})
+	if err != nil {
+		return err
+	}
+	url := c.baseURL + "/calls/costs"
+	resp, err := c.do(func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		return req, err
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var batch batchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return fmt.Errorf("error parseando respuesta de costos en lote: %w", err)
+	}
+	for _, raw := range batch.Results {
+		var r batchResult
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return fmt.Errorf("error parseando respuesta de costos en lote: %w", err)
+		}
+		if r.Error != nil && r.Error.Status >= 500 {
+			items[r.CallID] = client.BatchItem{Err: &itemServerError{*r.Error}}
+			continue
+		}
+		if r.Error != nil {
+			items[r.CallID] = client.BatchItem{Err: &client.CostAPIError{StatusCode: r.Error.Status, Err: fmt.Errorf("%s: %s", r.Error.Code, r.Error.Message)}}
+			continue
+		}
+		var cost model.CostResponse
+		if err := json.Unmarshal(raw, &cost); err != nil {
+			items[r.CallID] = client.BatchItem{Err: fmt.Errorf("error parseando respuesta de costos: %w", err)}
+			continue
+		}
+		items[r.CallID] = client.BatchItem{Response: &cost}
+	}
+	for _, id := range callIDs {
+		if _, ok := items[id]; !ok {
+			items[id] = client.BatchItem{Err: fmt.Errorf("la respuesta en lote no incluye call_id=%s", id)}
+		}
+	}
+	return nil
+}
+
+// do envía el request con reintentos y backoff ante errores de red y 5xx, y
+// devuelve la respuesta 200 con el body sin leer. Un 4xx se devuelve como
+// *client.CostAPIError sin reintentar.
+func (c *HttpCostClient) do(newRequest func() (*http.Request, error)) (*http.Response, error) {
 	var lastErr error
-	maxRetries := 3
-	backoff := time.Second
+	maxRetries := c.maxRetries
+	backoff := c.backoff
 
+	reauthenticated := false
 	for i := 0; i < maxRetries; i++ {
-		url := fmt.Sprintf("%s/calls/%s/cost", c.baseURL, callID)
-		resp, err := http.Get(url)
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.send(req)
 		if err != nil {
 			lastErr = err
 			log.Printf("⚠️ Error en llamada a cost API (intento %d): %v", i+1, err)
 		} else {
-			defer resp.Body.Close()
-
 			if resp.StatusCode == http.StatusOK {
-				var costResp model.CostResponse
-				if err := json.NewDecoder(resp.Body).Decode(&costResp); err != nil {
-					return nil, fmt.Errorf("error parseando respuesta de costos: %w", err)
-				}
-				return &costResp, nil
+				return resp, nil
+			}
+			resp.Body.Close()
+
+			// Token vencido o revocado: se pide uno nuevo y se reintenta una vez
+			if inv, ok := c.auth.(invalidator); ok && resp.StatusCode == http.StatusUnauthorized && !reauthenticated {
+				log.Printf("🔑 Cost API respondió 401, renovando credenciales")
+				inv.Invalidate()
+				reauthenticated = true
+				i--
+				continue
+			}
+			// Un problema de credenciales no es culpa de la llamada: no se marca INVALID
+			if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+				return nil, fmt.Errorf("cost API rechazó las credenciales: status code %d", resp.StatusCode)
 			}
 			if resp.StatusCode >= 500 {
 				lastErr = fmt.Errorf("status code %d", resp.StatusCode)
@@ -59,3 +259,11 @@ func (c *HttpCostClient) GetCallCost(callID string) (*model.CostResponse, error)
 	return nil, fmt.Errorf("cost API falló luego de %d intentos: %w", maxRetries, lastErr)
 }
 
+func (c *HttpCostClient) send(req *http.Request) (*http.Response, error) {
+	if c.auth != nil {
+		if err := c.auth.Authenticate(req); err != nil {
+			return nil, fmt.Errorf("error autenticando: %w", err)
+		}
+	}
+	return c.http.Do(req)
+}
@@ -1,7 +1,11 @@
 package client
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/client"
 	"net/http/httptest"
 	
 	"sync/atomic"
@@ -32,8 +36,7 @@ func TestGetCallCost_RetriesOnFailure(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	assert.Equal(t, "ARS", resp.Currency)
-	assert.Equal(t, 5.75, resp.Cost)
+	assert.Equal(t, model.NewMoney(575, "ARS"), resp.Cost)
 	assert.GreaterOrEqual(t, int(duration.Seconds()), 1) 
 	assert.Equal(t, int32(3), attempt)
 }
@@ -74,3 +77,121 @@ func TestGetCallCost_DoesNotRetryOnClientError(t *testing.T) {
 	assert.Equal(t, int32(1), attempt, "Should not retry on 4xx errors")
 }
 
+
+func TestGetCallCosts_SplitsIntoBatchesAndReportsItemErrors(t *testing.T) {
+	var batches [][]string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/calls/costs", r.URL.Path)
+		var req struct {
+			CallIDs []string `json:"call_ids"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		batches = append(batches, req.CallIDs)
+
+		results := []map[string]interface{}{}
+		for _, id := range req.CallIDs {
+			if id == "missing" {
+				results = append(results, map[string]interface{}{
+					"call_id": id,
+					"error":   map[string]interface{}{"status": 404, "code": "call_not_found", "message": "Llamada no encontrada"},
+				})
+				continue
+			}
+			results = append(results, map[string]interface{}{"call_id": id, "currency": "ARS", "cost": 1.5})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	}))
+	defer ts.Close()
+
+	c := NewHttpCostClient(ts.URL, WithBatchSize(2))
+
+	items, err := c.GetCallCosts([]string{"a", "b", "missing"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"missing"}}, batches)
+	assert.Equal(t, model.NewMoney(150, "ARS"), items["a"].Response.Cost)
+	assert.Equal(t, model.NewMoney(150, "ARS"), items["b"].Response.Cost)
+
+	var apiErr *client.CostAPIError
+	assert.True(t, errors.As(items["missing"].Err, &apiErr))
+	assert.True(t, apiErr.IsClientError())
+}
+
+func TestGetCallCosts_RetriesWholeBatchOnServerError(t *testing.T) {
+	var attempt int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempt, 1) < 2 {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"results":[{"call_id":"a","currency":"USD","cost":2}]}`))
+	}))
+	defer ts.Close()
+
+	c := NewHttpCostClient(ts.URL, WithRetries(3, time.Millisecond))
+
+	items, err := c.GetCallCosts([]string{"a", "b"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), attempt)
+	assert.Equal(t, model.NewMoney(200, "USD"), items["a"].Response.Cost)
+	assert.Error(t, items["b"].Err, "un id sin resultado se informa como error")
+}
+
+func TestGetCallCosts_RetriesItemServerErrors(t *testing.T) {
+	var attempt int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			CallIDs []string `json:"call_ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		n := atomic.AddInt32(&attempt, 1)
+		if n == 1 {
+			assert.Equal(t, []string{"a", "b", "down"}, req.CallIDs)
+			w.Write([]byte(`{"results":[{"call_id":"a","currency":"USD","cost":2},{"call_id":"b","error":{"status":503,"code":"unavailable","message":"reintentar"}},{"call_id":"down","error":{"status":502,"code":"bad_gateway","message":"caído"}}]}`))
+			return
+		}
+		if n == 2 {
+			assert.Equal(t, []string{"b", "down"}, req.CallIDs, "solo se reenvían los 5xx")
+			w.Write([]byte(`{"results":[{"call_id":"b","currency":"USD","cost":3},{"call_id":"down","error":{"status":502,"code":"bad_gateway","message":"caído"}}]}`))
+			return
+		}
+		assert.Equal(t, []string{"down"}, req.CallIDs)
+		w.Write([]byte(`{"results":[{"call_id":"down","error":{"status":502,"code":"bad_gateway","message":"caído"}}]}`))
+	}))
+	defer ts.Close()
+
+	c := NewHttpCostClient(ts.URL, WithRetries(3, time.Millisecond))
+
+	items, err := c.GetCallCosts([]string{"a", "b", "down"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), attempt)
+	assert.Equal(t, model.NewMoney(200, "USD"), items["a"].Response.Cost)
+	assert.Equal(t, model.NewMoney(300, "USD"), items["b"].Response.Cost)
+	var apiErr *client.CostAPIError
+	assert.Error(t, items["down"].Err)
+	assert.False(t, errors.As(items["down"].Err, &apiErr), "un 5xx agotado no es un error del cliente")
+}
+
+func TestGetCallCost_InvalidResponseIsProviderError(t *testing.T) {
+	var attempt int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempt, 1)
+		w.Write([]byte(`{"currency":"ARS","cost":-1}`))
+	}))
+	defer ts.Close()
+
+	resp, err := NewHttpCostClient(ts.URL).GetCallCost("negative-cost")
+
+	assert.Nil(t, resp)
+	assert.ErrorIs(t, err, model.ErrInvalidCostResponse)
+	var apiErr *client.CostAPIError
+	assert.False(t, errors.As(err, &apiErr), "no debe tratarse como llamada inválida")
+	assert.Equal(t, int32(1), attempt)
+}
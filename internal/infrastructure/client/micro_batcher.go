@@ -0,0 +1,102 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/client"
+)
+
+// MicroBatcher junta las consultas individuales concurrentes en lotes: el
+// primer GetCallCost abre una ventana y el lote se envía al cerrarse la
+// ventana o al llegar a maxSize ids. Consultas repetidas del mismo call_id
+// dentro de un lote se resuelven con una sola entrada.
+type MicroBatcher struct {
+	next    client.CostClient
+	maxSize int
+	window  time.Duration
+
+	mu      sync.Mutex
+	waiters map[string][]chan client.BatchItem
+	order   []string
+	timer   *time.Timer
+}
+
+func NewMicroBatcher(next client.CostClient, maxSize int, window time.Duration) *MicroBatcher {
+	if maxSize <= 0 {
+		maxSize = 100
+	}
+	return &MicroBatcher{next: next, maxSize: maxSize, window: window, waiters: map[string][]chan client.BatchItem{}}
+}
+
+func (b *MicroBatcher) GetCallCost(callID string) (*model.CostResponse, error) {
+	ch := make(chan client.BatchItem, 1)
+
+	b.mu.Lock()
+	if _, ok := b.waiters[callID]; !ok {
+		b.order = append(b.order, callID)
+	}
+	b.waiters[callID] = append(b.waiters[callID], ch)
+	if len(b.order) >= b.maxSize {
+		order, waiters := b.take()
+		b.mu.Unlock()
+		b.flush(order, waiters)
+	} else {
+		if b.timer == nil {
+			b.timer = time.AfterFunc(b.window, b.flushPending)
+		}
+		b.mu.Unlock()
+	}
+
+	item := <-ch
+	return item.Response, item.Err
+}
+
+func (b *MicroBatcher) GetCallCosts(callIDs []string) (map[string]client.BatchItem, error) {
+	return b.next.GetCallCosts(callIDs)
+}
+
+func (b *MicroBatcher) flushPending() {
+	b.mu.Lock()
+	order, waiters := b.take()
+	b.mu.Unlock()
+	b.flush(order, waiters)
+}
+
+// take se llama con el lock tomado.
+func (b *MicroBatcher) take() ([]string, map[string][]chan client.BatchItem) {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	order, waiters := b.order, b.waiters
+	b.order, b.waiters = nil, map[string][]chan client.BatchItem{}
+	return order, waiters
+}
+
+func (b *MicroBatcher) flush(order []string, waiters map[string][]chan client.BatchItem) {
+	if len(order) == 0 {
+		return
+	}
+	results, err := b.next.GetCallCosts(order)
+	for _, id := range order {
+		item, ok := results[id]
+		switch {
+		case err != nil:
+			item = client.BatchItem{Err: err}
+		case !ok:
+			item = client.BatchItem{Err: fmt.Errorf("sin resultado para call_id=%s", id)}
+		}
+		for _, ch := range waiters[id] {
+			// Cada consumidor recibe su copia de la respuesta
+			if item.Response != nil {
+				resp := *item.Response
+				ch <- client.BatchItem{Response: &resp}
+				continue
+			}
+			ch <- item
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/client"
+	"phonecall-cost-processor-service/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchRecorder responde cada id con 1 USD y registra los lotes recibidos.
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]string
+	err     error
+}
+
+func (r *batchRecorder) GetCallCost(callID string) (*model.CostResponse, error) {
+	return nil, errors.New("no debería consultarse de a una")
+}
+
+func (r *batchRecorder) GetCallCosts(callIDs []string) (map[string]client.BatchItem, error) {
+	r.mu.Lock()
+	r.batches = append(r.batches, callIDs)
+	r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	items := map[string]client.BatchItem{}
+	for _, id := range callIDs {
+		items[id] = client.BatchItem{Response: &model.CostResponse{Cost: model.NewMoney(100, "USD")}}
+	}
+	return items, nil
+}
+
+func lookupConcurrently(c client.CostClient, ids []string) ([]*model.CostResponse, []error) {
+	resps := make([]*model.CostResponse, len(ids))
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			resps[i], errs[i] = c.GetCallCost(id)
+		}(i, id)
+	}
+	wg.Wait()
+	return resps, errs
+}
+
+func TestMicroBatcher_CoalescesConcurrentLookupsWithinWindow(t *testing.T) {
+	next := &batchRecorder{}
+	b := NewMicroBatcher(next, 100, 50*time.Millisecond)
+
+	resps, errs := lookupConcurrently(b, []string{"a", "b", "c", "a"})
+
+	for i := range resps {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, model.NewMoney(100, "USD"), resps[i].Cost)
+	}
+	assert.Len(t, next.batches, 1)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, next.batches[0], "los ids repetidos viajan una sola vez")
+	assert.NotSame(t, resps[0], resps[3], "cada consumidor recibe su propia respuesta")
+}
+
+func TestMicroBatcher_FlushesWhenBatchIsFull(t *testing.T) {
+	next := &batchRecorder{}
+	b := NewMicroBatcher(next, 2, time.Hour)
+
+	_, errs := lookupConcurrently(b, []string{"a", "b", "c", "d"})
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Len(t, next.batches, 2)
+	for _, batch := range next.batches {
+		assert.Len(t, batch, 2)
+	}
+}
+
+func TestMicroBatcher_PropagatesBatchError(t *testing.T) {
+	next := &batchRecorder{err: errors.New("cost API caída")}
+	b := NewMicroBatcher(next, 10, time.Millisecond)
+
+	resp, err := b.GetCallCost("a")
+
+	assert.Nil(t, resp)
+	assert.EqualError(t, err, "cost API caída")
+}
+
+func TestMicroBatcher_AgainstMockAPI(t *testing.T) {
+	ts := httptest.NewServer(mock.NewMockCostAPIHandler())
+	defer ts.Close()
+
+	b := NewMicroBatcher(NewHttpCostClient(ts.URL), 10, 20*time.Millisecond)
+
+	resps, errs := lookupConcurrently(b, []string{
+		"11111111-1111-1111-1111-111111111111",
+		"123e4567-e89b-12d3-a456-426614174998",
+	})
+
+	assert.NoError(t, errs[0])
+	assert.Equal(t, model.NewMoney(850, "ARS"), resps[0].Cost)
+
+	var apiErr *client.CostAPIError
+	assert.True(t, errors.As(errs[1], &apiErr))
+	assert.Equal(t, 404, apiErr.StatusCode)
+}
@@ -0,0 +1,244 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/client"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+// RoutingCostClient elige los proveedores según la RoutingTable y los
+// prueba en orden: ante error de red, timeout o 5xx pasa al siguiente; un
+// 4xx se devuelve sin probar otros. La respuesta indica qué proveedor tarifó.
+type RoutingCostClient struct {
+	calls     repository.CallDetailsReader
+	table     model.RoutingTable
+	providers map[string]client.CostClient
+}
+
+func NewRoutingCostClient(calls repository.CallDetailsReader, table model.RoutingTable, providers map[string]client.CostClient) (*RoutingCostClient, error) {
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+	for _, name := range table.ProviderNames() {
+		if providers[name] == nil {
+			return nil, fmt.Errorf("proveedor %q no configurado", name)
+		}
+	}
+	return &RoutingCostClient{calls: calls, table: table, providers: providers}, nil
+}
+
+func (c *RoutingCostClient) GetCallCost(callID string) (*model.CostResponse, error) {
+	rule, names, err := c.route(callID)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for i, name := range names {
+		resp, err := c.providers[name].GetCallCost(callID)
+		if err == nil {
+			resp.Provider = name
+			return resp, nil
+		}
+		if isClientError(err) {
+			return nil, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		if i < len(names)-1 {
+			log.Printf("🔁 Proveedor %s falló para call_id=%s (regla %s), probando %s: %v", name, callID, rule, names[i+1], err)
+		}
+	}
+	return nil, fmt.Errorf("ningún proveedor pudo tarifar call_id=%s (regla %s): %w", callID, rule, errors.Join(errs...))
+}
+
+// GetCallCosts agrupa las llamadas por lista de proveedores y consulta cada
+// grupo en lote; las que fallan pasan juntas al siguiente proveedor.
+func (c *RoutingCostClient) GetCallCosts(callIDs []string) (map[string]client.BatchItem, error) {
+	items := make(map[string]client.BatchItem, len(callIDs))
+	type group struct {
+		rule  string
+		names []string
+		ids   []string
+	}
+	groups := map[string]*group{}
+	var order []string
+	for _, id := range callIDs {
+		rule, names, err := c.route(id)
+		if err != nil {
+			items[id] = client.BatchItem{Err: err}
+			continue
+		}
+		key := strings.Join(names, ",")
+		if groups[key] == nil {
+			groups[key] = &group{rule: rule, names: names}
+			order = append(order, key)
+		}
+		groups[key].ids = append(groups[key].ids, id)
+	}
+
+	for _, key := range order {
+		g := groups[key]
+		pending := g.ids
+		errs := map[string][]error{}
+		for i, name := range g.names {
+			results, batchErr := c.providers[name].GetCallCosts(pending)
+			var failed []string
+			for _, id := range pending {
+				it := client.BatchItem{Err: batchErr}
+				if batchErr == nil {
+					it = results[id]
+				}
+				switch {
+				case it.Err == nil && it.Response != nil:
+					it.Response.Provider = name
+					items[id] = it
+				case isClientError(it.Err):
+					items[id] = it
+				case it.Err == nil:
+					// Un proveedor que omite el id cuenta como fallo y pasa al siguiente
+					errs[id] = append(errs[id], fmt.Errorf("%s: sin resultado para call_id=%s", name, id))
+					failed = append(failed, id)
+				default:
+					errs[id] = append(errs[id], fmt.Errorf("%s: %w", name, it.Err))
+					failed = append(failed, id)
+				}
+			}
+			if len(failed) > 0 && i < len(g.names)-1 {
+				log.Printf("🔁 Proveedor %s falló para %d llamadas (regla %s), probando %s", name, len(failed), g.rule, g.names[i+1])
+			}
+			pending = failed
+			if len(pending) == 0 {
+				break
+			}
+		}
+		for _, id := range pending {
+			items[id] = client.BatchItem{Err: fmt.Errorf("ningún proveedor pudo tarifar call_id=%s (regla %s): %w", id, g.rule, errors.Join(errs[id]...))}
+		}
+	}
+	return items, nil
+}
+
+func (c *RoutingCostClient) route(callID string) (string, []string, error) {
+	call, err := c.calls.GetCall(callID)
+	if err != nil {
+		return "", nil, err
+	}
+	if call == nil {
+		return "", nil, fmt.Errorf("llamada %s no encontrada para rutear", callID)
+	}
+	if call.StartTimestamp.IsZero() {
+		return "", nil, fmt.Errorf("llamada %s sin start_timestamp para rutear", callID)
+	}
+	rule, names := c.table.Route(*call, call.StartTimestamp)
+	return rule, names, nil
+}
+
+// RoutingConfig es el archivo COST_ROUTING_FILE: los proveedores disponibles
+// y la tabla de ruteo.
+type RoutingConfig struct {
+	Providers map[string]ProviderConfig `json:"providers"`
+	Routing   model.RoutingTable        `json:"routing"`
+}
+
+// ProviderConfig: Type "http" (por defecto) usa URL; "local" usa el motor de
+// tarifación local. Cada proveedor HTTP se autentica solo con su Auth.
+type ProviderConfig struct {
+	Type    string        `json:"type,omitempty"`
+	URL     string        `json:"url,omitempty"`
+	Retries int           `json:"retries,omitempty"`
+	Timeout string        `json:"timeout,omitempty"`
+	Auth    *ProviderAuth `json:"auth,omitempty"`
+}
+
+// ProviderAuth es la autenticación de un proveedor del ruteo (ver
+// AuthConfig). Los secretos no van en el archivo: se leen de las variables
+// de entorno indicadas en APIKeyEnv y ClientSecretEnv.
+type ProviderAuth struct {
+	Mode            string   `json:"mode,omitempty"`
+	APIKeyHeader    string   `json:"api_key_header,omitempty"`
+	APIKeyEnv       string   `json:"api_key_env,omitempty"`
+	TokenURL        string   `json:"token_url,omitempty"`
+	ClientID        string   `json:"client_id,omitempty"`
+	ClientSecretEnv string   `json:"client_secret_env,omitempty"`
+	Scopes          []string `json:"scopes,omitempty"`
+	TLSCert         string   `json:"tls_cert,omitempty"`
+	TLSKey          string   `json:"tls_key,omitempty"`
+	TLSCA           string   `json:"tls_ca,omitempty"`
+}
+
+func (a ProviderAuth) config() AuthConfig {
+	return AuthConfig{
+		Mode:         a.Mode,
+		APIKeyHeader: a.APIKeyHeader,
+		APIKey:       os.Getenv(a.APIKeyEnv),
+		TokenURL:     a.TokenURL,
+		ClientID:     a.ClientID,
+		ClientSecret: os.Getenv(a.ClientSecretEnv),
+		Scopes:       a.Scopes,
+		TLSCert:      a.TLSCert,
+		TLSKey:       a.TLSKey,
+		TLSCA:        a.TLSCA,
+	}
+}
+
+func LoadRoutingConfig(path string) (RoutingConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RoutingConfig{}, err
+	}
+	var cfg RoutingConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return RoutingConfig{}, fmt.Errorf("error leyendo %s: %w", path, err)
+	}
+	return cfg, cfg.Routing.Validate()
+}
+
+// NewClients crea un cliente por proveedor. local puede ser nil si ningún
+// proveedor es de tipo "local". opts se aplican a todos los proveedores HTTP
+// y no deben llevar credenciales: cada uno usa las de su Auth.
+func (c RoutingConfig) NewClients(local client.CostClient, opts ...Option) (map[string]client.CostClient, error) {
+	clients := make(map[string]client.CostClient, len(c.Providers))
+	for name, p := range c.Providers {
+		switch p.Type {
+		case "", "http":
+			if p.URL == "" {
+				return nil, fmt.Errorf("proveedor %s sin url", name)
+			}
+			providerOpts := append([]Option{}, opts...)
+			if p.Auth != nil {
+				authOpts, err := p.Auth.config().Options()
+				if err != nil {
+					return nil, fmt.Errorf("proveedor %s: %w", name, err)
+				}
+				providerOpts = append(providerOpts, authOpts...)
+			}
+			if p.Retries > 0 {
+				providerOpts = append(providerOpts, WithRetries(p.Retries, time.Second))
+			}
+			if p.Timeout != "" {
+				timeout, err := time.ParseDuration(p.Timeout)
+				if err != nil {
+					return nil, fmt.Errorf("proveedor %s: timeout inválido: %w", name, err)
+				}
+				providerOpts = append(providerOpts, WithTimeout(timeout))
+			}
+			clients[name] = NewHttpCostClient(p.URL, providerOpts...)
+		case "local":
+			if local == nil {
+				return nil, fmt.Errorf("proveedor %s es local pero no hay tarifarios configurados", name)
+			}
+			clients[name] = local
+		default:
+			return nil, fmt.Errorf("proveedor %s: tipo desconocido %q", name, p.Type)
+		}
+	}
+	return clients, nil
+}
@@ -0,0 +1,198 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/client"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubCallDetails map[string]model.NewIncomingCall
+
+func (s stubCallDetails) GetCall(callID string) (*model.NewIncomingCall, error) {
+	call, ok := s[callID]
+	if !ok {
+		return nil, nil
+	}
+	return &call, nil
+}
+
+var routingCalls = stubCallDetails{
+	"movil": {CallID: "movil", Caller: "+5491100000000", Receiver: "+5491122223333", StartTimestamp: time.Date(2024, 9, 2, 12, 0, 0, 0, time.UTC)},
+	"fijo":  {CallID: "fijo", Caller: "+5491100000000", Receiver: "+543514445555", StartTimestamp: time.Date(2024, 9, 2, 12, 0, 0, 0, time.UTC)},
+}
+
+var routingTable = model.RoutingTable{
+	Rules:   []model.RoutingRule{{Name: "movil", ReceiverPrefixes: []string{"+54911"}, Providers: []string{"carrier_b", "carrier_a"}}},
+	Default: []string{"carrier_a"},
+}
+
+func costServer(status int, body string, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestRoutingCostClient_RoutesByRule(t *testing.T) {
+	a := &stubCostClient{resp: &model.CostResponse{Cost: model.NewMoney(100, "ARS")}}
+	b := &stubCostClient{resp: &model.CostResponse{Cost: model.NewMoney(90, "ARS")}}
+	c, err := NewRoutingCostClient(routingCalls, routingTable, map[string]client.CostClient{"carrier_a": a, "carrier_b": b})
+	assert.NoError(t, err)
+
+	resp, err := c.GetCallCost("movil")
+	assert.NoError(t, err)
+	assert.Equal(t, "carrier_b", resp.Provider)
+	assert.False(t, a.called)
+
+	resp, err = c.GetCallCost("fijo")
+	assert.NoError(t, err)
+	assert.Equal(t, "carrier_a", resp.Provider)
+	assert.Equal(t, model.NewMoney(100, "ARS"), resp.Cost)
+}
+
+func TestRoutingCostClient_FailsOverOn5xxAndTimeout(t *testing.T) {
+	failing := costServer(http.StatusBadGateway, "", 0)
+	defer failing.Close()
+	slow := costServer(http.StatusOK, `{"currency":"ARS","cost":1}`, 200*time.Millisecond)
+	defer slow.Close()
+	healthy := costServer(http.StatusOK, `{"currency":"ARS","cost":2.5}`, 0)
+	defer healthy.Close()
+
+	table := model.RoutingTable{Default: []string{"failing", "slow", "healthy"}}
+	providers := map[string]client.CostClient{
+		"failing": NewHttpCostClient(failing.URL, WithRetries(1, 0)),
+		"slow":    NewHttpCostClient(slow.URL, WithRetries(1, 0), WithTimeout(20*time.Millisecond)),
+		"healthy": NewHttpCostClient(healthy.URL, WithRetries(1, 0)),
+	}
+	c, err := NewRoutingCostClient(routingCalls, table, providers)
+	assert.NoError(t, err)
+
+	resp, err := c.GetCallCost("fijo")
+	assert.NoError(t, err)
+	assert.Equal(t, "healthy", resp.Provider)
+	assert.Equal(t, model.NewMoney(250, "ARS"), resp.Cost)
+}
+
+func TestRoutingCostClient_DoesNotFailOverOn4xx(t *testing.T) {
+	b := &stubCostClient{err: &client.CostAPIError{StatusCode: 404, Err: errors.New("client error")}}
+	a := &stubCostClient{}
+	c, _ := NewRoutingCostClient(routingCalls, routingTable, map[string]client.CostClient{"carrier_a": a, "carrier_b": b})
+
+	_, err := c.GetCallCost("movil")
+
+	var apiErr *client.CostAPIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.False(t, a.called)
+}
+
+func TestRoutingCostClient_AllProvidersFail(t *testing.T) {
+	errA, errB := errors.New("a caído"), errors.New("b caído")
+	c, _ := NewRoutingCostClient(routingCalls, routingTable, map[string]client.CostClient{
+		"carrier_a": &stubCostClient{err: errA},
+		"carrier_b": &stubCostClient{err: errB},
+	})
+
+	_, err := c.GetCallCost("movil")
+	assert.ErrorIs(t, err, errA)
+	assert.ErrorIs(t, err, errB)
+
+	_, err = c.GetCallCost("inexistente")
+	assert.Error(t, err)
+}
+
+// omittingCostClient responde los lotes sin incluir los ids de omit.
+type omittingCostClient struct {
+	stubCostClient
+	omit string
+}
+
+func (o *omittingCostClient) GetCallCosts(callIDs []string) (map[string]client.BatchItem, error) {
+	items, _ := o.stubCostClient.GetCallCosts(callIDs)
+	delete(items, o.omit)
+	return items, nil
+}
+
+func TestRoutingCostClient_GetCallCosts_FailsOverOmittedIDs(t *testing.T) {
+	b := &omittingCostClient{stubCostClient: stubCostClient{resp: &model.CostResponse{Cost: model.NewMoney(90, "ARS")}}, omit: "movil"}
+	a := &stubCostClient{resp: &model.CostResponse{Cost: model.NewMoney(100, "ARS")}}
+	table := model.RoutingTable{Default: []string{"carrier_b", "carrier_a"}}
+	c, err := NewRoutingCostClient(routingCalls, table, map[string]client.CostClient{"carrier_a": a, "carrier_b": b})
+	assert.NoError(t, err)
+
+	items, err := c.GetCallCosts([]string{"movil", "fijo"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "carrier_a", items["movil"].Response.Provider)
+	assert.Equal(t, "carrier_b", items["fijo"].Response.Provider)
+	assert.Equal(t, [][]string{{"movil"}}, a.batches)
+}
+
+func TestNewRoutingCostClient_RequiresAllProviders(t *testing.T) {
+	_, err := NewRoutingCostClient(routingCalls, routingTable, map[string]client.CostClient{"carrier_a": &stubCostClient{}})
+	assert.Error(t, err)
+}
+
+func TestLoadRoutingConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routing.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{
+		"providers": {
+			"carrier_a": {"url": "http://carrier-a", "retries": 2, "timeout": "3s"},
+			"local": {"type": "local"}
+		},
+		"routing": {"rules": [{"name": "movil", "receiver_prefixes": ["+54911"], "providers": ["local"]}], "default": ["carrier_a", "local"]}
+	}`), 0o644))
+
+	cfg, err := LoadRoutingConfig(path)
+	assert.NoError(t, err)
+
+	_, err = cfg.NewClients(nil)
+	assert.Error(t, err, "local provider without rate cards")
+
+	clients, err := cfg.NewClients(&stubCostClient{})
+	assert.NoError(t, err)
+	assert.Len(t, clients, 2)
+	assert.Equal(t, 3*time.Second, clients["carrier_a"].(*HttpCostClient).http.Timeout)
+}
+
+func TestRoutingConfig_NewClients_PerProviderAuth(t *testing.T) {
+	var gotA, gotB http.Header
+	carrierA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotA = r.Header.Clone()
+		okCost(w, r)
+	}))
+	defer carrierA.Close()
+	carrierB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotB = r.Header.Clone()
+		okCost(w, r)
+	}))
+	defer carrierB.Close()
+	t.Setenv("CARRIER_A_KEY", "secreto-a")
+
+	cfg := RoutingConfig{Providers: map[string]ProviderConfig{
+		"carrier_a": {URL: carrierA.URL, Auth: &ProviderAuth{Mode: "api_key", APIKeyHeader: "X-Carrier-Key", APIKeyEnv: "CARRIER_A_KEY"}},
+		"carrier_b": {URL: carrierB.URL},
+	}}
+	clients, err := cfg.NewClients(nil, WithBatchSize(10))
+	assert.NoError(t, err)
+
+	_, err = clients["carrier_a"].GetCallCost("id")
+	assert.NoError(t, err)
+	_, err = clients["carrier_b"].GetCallCost("id")
+	assert.NoError(t, err)
+	assert.Equal(t, "secreto-a", gotA.Get("X-Carrier-Key"))
+	assert.Empty(t, gotB.Get("X-Carrier-Key"), "las credenciales de un proveedor no van a otro")
+
+	cfg.Providers["carrier_b"] = ProviderConfig{URL: carrierB.URL, Auth: &ProviderAuth{Mode: "api_key", APIKeyEnv: "CARRIER_B_KEY"}}
+	_, err = cfg.NewClients(nil)
+	assert.Error(t, err, "variable de entorno de la API key vacía")
+}
@@ -1,17 +1,61 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/infrastructure/client"
+	"phonecall-cost-processor-service/internal/infrastructure/messaging"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	RabbitURL   string
-	RabbitQueue string
-	DBUrl       string
-	CostAPIUrl  string
+	RabbitURL        string
+	RabbitQueue      string
+	DBUrl            string
+	CostAPIUrl       string
+	MessageSource    string
+	MessageFile      string
+	CallRepository   string
+	BaseCurrency     string
+	LocalRating      string
+	RateCardsDir     string
+	ShadowProvider   string
+	ShadowCostAPIUrl string
+	CostRoutingFile  string
+	CostBatchSize    string
+	CostBatchWindow  string
+	MetricsAddr      string
+	PhoneCountry     string
+	TimestampFormats string
+	TimestampZone    string
+	TimestampZones   string
+	QualityCredits   string
+	AdminAddr        string
+	AdminToken       string
+	PlaceholderTTL   string
+	PlaceholderSweep string
+	PendingEventTTL  string
+	MaxAttempts      string
+	RetryBackoff     string
+
+	// Autenticación contra la API de costos
+	CostAPIAuth         string
+	CostAPIKeyHeader    string
+	CostAPIKey          string
+	CostAPITokenURL     string
+	CostAPIClientID     string
+	CostAPIClientSecret string
+	CostAPIScopes       string
+	CostAPITLSCert      string
+	CostAPITLSKey       string
+	CostAPITLSCA        string
 }
 
 func Load() Config {
@@ -21,9 +65,190 @@ func Load() Config {
 	}
 
 	return Config{
-		RabbitURL:   os.Getenv("RABBITMQ_URL"),
-		RabbitQueue: os.Getenv("RABBITMQ_QUEUE"),
-		DBUrl:       os.Getenv("DB_URL"),
-		CostAPIUrl:  os.Getenv("COST_API_URL"),
+		RabbitURL:        os.Getenv("RABBITMQ_URL"),
+		RabbitQueue:      os.Getenv("RABBITMQ_QUEUE"),
+		DBUrl:            os.Getenv("DB_URL"),
+		CostAPIUrl:       os.Getenv("COST_API_URL"),
+		MessageSource:    getEnv("MESSAGE_SOURCE", "rabbitmq"),
+		MessageFile:      getEnv("MESSAGE_FILE", "-"),
+		CallRepository:   getEnv("CALL_REPOSITORY", "postgres"),
+		BaseCurrency:     getEnv("BASE_CURRENCY", "USD"),
+		LocalRating:      getEnv("LOCAL_RATING", "off"),
+		RateCardsDir:     os.Getenv("RATE_CARDS_DIR"),
+		ShadowProvider:   getEnv("SHADOW_PROVIDER", "off"),
+		ShadowCostAPIUrl: os.Getenv("SHADOW_COST_API_URL"),
+		CostRoutingFile:  os.Getenv("COST_ROUTING_FILE"),
+		CostBatchSize:    getEnv("COST_BATCH_SIZE", "100"),
+		CostBatchWindow:  getEnv("COST_BATCH_WINDOW", "0"),
+		MetricsAddr:      os.Getenv("METRICS_ADDR"),
+		PhoneCountry:     os.Getenv("PHONE_DEFAULT_COUNTRY"),
+		TimestampFormats: getEnv("TIMESTAMP_FORMATS", "rfc3339,epoch_ms,local"),
+		TimestampZone:    getEnv("TIMESTAMP_TIMEZONE", "UTC"),
+		TimestampZones:   os.Getenv("TIMESTAMP_TIMEZONES"),
+		QualityCredits:   os.Getenv("QUALITY_CREDITS"),
+		AdminAddr:        os.Getenv("ADMIN_ADDR"),
+		AdminToken:       os.Getenv("ADMIN_TOKEN"),
+		PlaceholderTTL:   os.Getenv("PLACEHOLDER_TTL"),
+		PlaceholderSweep: getEnv("PLACEHOLDER_SWEEP_INTERVAL", "1h"),
+		PendingEventTTL:  os.Getenv("PENDING_EVENT_TTL"),
+		MaxAttempts:      getEnv("MESSAGE_MAX_ATTEMPTS", "5"),
+		RetryBackoff:     getEnv("MESSAGE_RETRY_BACKOFF", "1s"),
+
+		CostAPIAuth:         getEnv("COST_API_AUTH", "none"),
+		CostAPIKeyHeader:    getEnv("COST_API_KEY_HEADER", "X-API-Key"),
+		CostAPIKey:          os.Getenv("COST_API_KEY"),
+		CostAPITokenURL:     os.Getenv("COST_API_TOKEN_URL"),
+		CostAPIClientID:     os.Getenv("COST_API_CLIENT_ID"),
+		CostAPIClientSecret: os.Getenv("COST_API_CLIENT_SECRET"),
+		CostAPIScopes:       os.Getenv("COST_API_SCOPES"),
+		CostAPITLSCert:      os.Getenv("COST_API_TLS_CERT"),
+		CostAPITLSKey:       os.Getenv("COST_API_TLS_KEY"),
+		CostAPITLSCA:        os.Getenv("COST_API_TLS_CA"),
+	}
+}
+
+// CostAPIAuthOptions devuelve las opciones de autenticación para los
+// clientes HTTP de la API de costos.
+func (c Config) CostAPIAuthOptions() ([]client.Option, error) {
+	return client.AuthConfig{
+		Mode:         c.CostAPIAuth,
+		APIKeyHeader: c.CostAPIKeyHeader,
+		APIKey:       c.CostAPIKey,
+		TokenURL:     c.CostAPITokenURL,
+		ClientID:     c.CostAPIClientID,
+		ClientSecret: c.CostAPIClientSecret,
+		Scopes:       strings.Fields(c.CostAPIScopes),
+		TLSCert:      c.CostAPITLSCert,
+		TLSKey:       c.CostAPITLSKey,
+		TLSCA:        c.CostAPITLSCA,
+	}.Options()
+}
+
+// CostBatching devuelve el tamaño máximo de lote y la ventana del
+// micro-batcher; una ventana de 0 lo desactiva.
+func (c Config) CostBatching() (int, time.Duration, error) {
+	size, err := strconv.Atoi(c.CostBatchSize)
+	if err != nil || size <= 0 {
+		return 0, 0, fmt.Errorf("COST_BATCH_SIZE inválido: %q", c.CostBatchSize)
+	}
+	window, err := time.ParseDuration(c.CostBatchWindow)
+	if err != nil || window < 0 {
+		return 0, 0, fmt.Errorf("COST_BATCH_WINDOW inválido: %q", c.CostBatchWindow)
+	}
+	return size, window, nil
+}
+
+// Expiry devuelve cuánto se espera el new_incoming_call de un placeholder
+// REFUND_PARTIALLY (PLACEHOLDER_TTL) y de un evento estacionado
+// (PENDING_EVENT_TTL) antes de descartarlos, y cada cuánto se revisan; un ttl
+// de 0 (variable vacía) desactiva esa expiración.
+func (c Config) Expiry() (placeholderTTL, pendingTTL, sweep time.Duration, err error) {
+	if placeholderTTL, err = parseTTL("PLACEHOLDER_TTL", c.PlaceholderTTL); err != nil {
+		return 0, 0, 0, err
+	}
+	if pendingTTL, err = parseTTL("PENDING_EVENT_TTL", c.PendingEventTTL); err != nil {
+		return 0, 0, 0, err
+	}
+	if placeholderTTL == 0 && pendingTTL == 0 {
+		return 0, 0, 0, nil
+	}
+	sweep, err = time.ParseDuration(c.PlaceholderSweep)
+	if err != nil || sweep <= 0 {
+		return 0, 0, 0, fmt.Errorf("PLACEHOLDER_SWEEP_INTERVAL inválido: %q", c.PlaceholderSweep)
+	}
+	return placeholderTTL, pendingTTL, sweep, nil
+}
+
+func parseTTL(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("%s inválido: %q", name, value)
+	}
+	return ttl, nil
+}
+
+// RetryPolicy devuelve los reintentos de mensajes con error reintentable:
+// MESSAGE_MAX_ATTEMPTS entregas, con MESSAGE_RETRY_BACKOFF de espera inicial.
+func (c Config) RetryPolicy() (messaging.RetryPolicy, error) {
+	policy := messaging.DefaultRetryPolicy()
+	attempts, err := strconv.Atoi(c.MaxAttempts)
+	if err != nil || attempts <= 0 {
+		return policy, fmt.Errorf("MESSAGE_MAX_ATTEMPTS inválido: %q", c.MaxAttempts)
+	}
+	backoff, err := time.ParseDuration(c.RetryBackoff)
+	if err != nil || backoff < 0 {
+		return policy, fmt.Errorf("MESSAGE_RETRY_BACKOFF inválido: %q", c.RetryBackoff)
+	}
+	policy.MaxAttempts, policy.Backoff = attempts, backoff
+	return policy, nil
+}
+
+// PhoneNormalizer devuelve el normalizador E.164 para PHONE_DEFAULT_COUNTRY,
+// o nil si no está configurado (los números se guardan como llegan).
+func (c Config) PhoneNormalizer() (*model.PhoneNormalizer, error) {
+	if c.PhoneCountry == "" {
+		return nil, nil
+	}
+	return model.NewPhoneNormalizer(c.PhoneCountry)
+}
+
+// TimestampParser devuelve el parser de start_timestamp con TIMESTAMP_FORMATS
+// y TIMESTAMP_TIMEZONE.
+func (c Config) TimestampParser() (*model.TimestampParser, error) {
+	return NewTimestampParser(c.TimestampFormats, c.TimestampZone)
+}
+
+// SourceTimestampParsers devuelve un parser con TIMESTAMP_FORMATS por cada
+// source de TIMESTAMP_TIMEZONES ("source=zona,..."), para los productores
+// cuya zona no es TIMESTAMP_TIMEZONE.
+func (c Config) SourceTimestampParsers() (map[string]*model.TimestampParser, error) {
+	parsers := map[string]*model.TimestampParser{}
+	if strings.TrimSpace(c.TimestampZones) == "" {
+		return parsers, nil
+	}
+	for _, pair := range strings.Split(c.TimestampZones, ",") {
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("zona por source inválida %q, se espera source=zona", pair)
+		}
+		source := strings.TrimSpace(pair[:i])
+		p, err := NewTimestampParser(c.TimestampFormats, strings.TrimSpace(pair[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", source, err)
+		}
+		parsers[source] = p
+	}
+	return parsers, nil
+}
+
+// NewTimestampParser arma un parser a partir de formatos separados por comas
+// y un nombre de zona IANA (p. ej. "America/Argentina/Buenos_Aires").
+func NewTimestampParser(formats, zone string) (*model.TimestampParser, error) {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, fmt.Errorf("zona horaria inválida %q: %w", zone, err)
+	}
+	var list []string
+	for _, f := range strings.Split(formats, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			list = append(list, f)
+		}
+	}
+	return model.NewTimestampParser(list, loc)
+}
+
+// CreditPolicy devuelve los porcentajes de crédito por severidad de
+// QUALITY_CREDITS sobre model.DefaultCreditPolicy.
+func (c Config) CreditPolicy() (model.CreditPolicy, error) {
+	return model.ParseCreditPolicy(c.QualityCredits)
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
 	}
+	return fallback
 }
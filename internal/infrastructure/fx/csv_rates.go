@@ -0,0 +1,55 @@
+package fx
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+)
+
+// ReadRatesCSV lee cotizaciones con encabezado date,from,to,rate
+// (date en formato 2006-01-02).
+func ReadRatesCSV(r io.Reader) ([]model.FXRate, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo encabezado: %w", err)
+	}
+	index := map[string]int{}
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, col := range []string{"date", "from", "to", "rate"} {
+		if _, ok := index[col]; !ok {
+			return nil, fmt.Errorf("falta la columna %q", col)
+		}
+	}
+
+	var rates []model.FXRate
+	line := 1
+	for {
+		row, err := cr.Read()
+		line++
+		if err == io.EOF {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		date, err := time.Parse("2006-01-02", row[index["date"]])
+		if err != nil {
+			return nil, fmt.Errorf("línea %d: fecha inválida: %w", line, err)
+		}
+		rate, err := model.ParseFXRate(date, row[index["from"]], row[index["to"]], row[index["rate"]])
+		if err != nil {
+			return nil, fmt.Errorf("línea %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+}
@@ -0,0 +1,34 @@
+package fx
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadRatesCSV(t *testing.T) {
+	input := `date,from,to,rate
+2024-08-01,USD,ARS,925.50
+2024-08-01,eur,usd,1.0850
+`
+	rates, err := ReadRatesCSV(strings.NewReader(input))
+
+	assert.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), rates[0].Date)
+	assert.Equal(t, "1851/2", rates[0].Rate.String())
+	assert.Equal(t, "EUR", rates[1].From)
+	assert.Equal(t, "USD", rates[1].To)
+}
+
+func TestReadRatesCSV_InvalidRate(t *testing.T) {
+	_, err := ReadRatesCSV(strings.NewReader("date,from,to,rate\n2024-08-01,USD,ARS,-1\n"))
+	assert.ErrorContains(t, err, "línea 2")
+}
+
+func TestReadRatesCSV_MissingColumn(t *testing.T) {
+	_, err := ReadRatesCSV(strings.NewReader("date,from,rate\n"))
+	assert.Error(t, err)
+}
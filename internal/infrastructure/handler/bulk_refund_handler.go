@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"phonecall-cost-processor-service/internal/application"
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/infrastructure/rabbitmq/dto"
+)
+
+type BulkRefundHandler struct {
+	useCase   application.IBulkRefundUseCase
+	publisher Publisher
+}
+
+// Publisher publica un evento en la cola de entrada; lo implementa
+// rabbitmq.Publisher.
+type Publisher interface {
+	Publish(eventType, subject string, data interface{}) error
+}
+
+type BulkRefundHandlerOption func(*BulkRefundHandler)
+
+// WithContinuation aplica un lote por mensaje y publica con publisher un
+// bulk_refund para el lote siguiente, así un refund masivo grande no bloquea
+// al consumidor ni excede el timeout de entrega de RabbitMQ.
+func WithContinuation(publisher Publisher) BulkRefundHandlerOption {
+	return func(h *BulkRefundHandler) { h.publisher = publisher }
+}
+
+func NewBulkRefundHandler(useCase application.IBulkRefundUseCase, opts ...BulkRefundHandlerOption) *BulkRefundHandler {
+	h := &BulkRefundHandler{useCase: useCase}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Handle aplica el refund masivo, completo o de a un lote con
+// WithContinuation. Si falla a mitad de camino el mensaje se reintenta y
+// retoma desde el último lote guardado.
+func (h *BulkRefundHandler) Handle(msg []byte) error {
+	var d dto.BulkRefundDTO
+	if err := json.Unmarshal(msg, &d); err != nil {
+		log.Printf("❌ Error parseando DTO de refund masivo: %v", err)
+		return model.Permanent(fmt.Errorf("payload inválido para bulk_refund: %w", err))
+	}
+
+	execute := h.useCase.Execute
+	if h.publisher != nil {
+		execute = h.useCase.ExecuteBatch
+	}
+	p, err := execute(BulkRefundFromDTO(d))
+	if err != nil {
+		if model.IsPermanent(err) {
+			log.Printf("⚠️ Refund masivo rechazado: %v", err)
+		} else {
+			log.Printf("❌ Refund masivo interrumpido: %v", err)
+		}
+		return err
+	}
+
+	if h.publisher != nil && !p.Done {
+		// El avance ya está guardado: la continuación retoma después de p.Cursor
+		d.BulkID = p.BulkID
+		if err := h.publisher.Publish("bulk_refund", p.BulkID, d); err != nil {
+			log.Printf("❌ Error publicando el siguiente lote del refund masivo %s: %v", p.BulkID, err)
+			return err
+		}
+		log.Printf("📦 Refund masivo %s continúa después de call_id=%s", p.BulkID, p.Cursor)
+		return nil
+	}
+	log.Printf("💸 Refund masivo %s finalizado: encontradas=%d reembolsadas=%d rechazadas=%d", p.BulkID, p.Matched, p.Refunded, p.Rejected)
+	return nil
+}
+
+func BulkRefundFromDTO(d dto.BulkRefundDTO) model.BulkRefund {
+	return model.BulkRefund{
+		BulkID: d.BulkID,
+		Filter: model.BulkRefundFilter{
+			Callers:      d.Callers,
+			CallerPrefix: d.CallerPrefix,
+			From:         d.From,
+			To:           d.To,
+			Statuses:     d.Statuses,
+		},
+		Reason:  d.Reason,
+		Percent: d.Percent,
+	}
+}
@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/infrastructure/handler"
+	"phonecall-cost-processor-service/internal/infrastructure/rabbitmq/dto"
+)
+
+type MockBulkRefundUseCase struct {
+	Called  bool
+	Batches int
+	Input   model.BulkRefund
+	Err     error
+}
+
+func (m *MockBulkRefundUseCase) Execute(bulk model.BulkRefund) (model.BulkRefundProgress, error) {
+	m.Called = true
+	m.Input = bulk
+	if m.Err != nil {
+		return model.BulkRefundProgress{}, m.Err
+	}
+	return model.BulkRefundProgress{BulkID: bulk.ID(), Done: true}, bulk.Validate()
+}
+
+// ExecuteBatch simula un refund masivo de dos lotes: el primero queda
+// pendiente y el segundo lo termina.
+func (m *MockBulkRefundUseCase) ExecuteBatch(bulk model.BulkRefund) (model.BulkRefundProgress, error) {
+	m.Batches++
+	m.Input = bulk
+	if m.Err != nil {
+		return model.BulkRefundProgress{}, m.Err
+	}
+	return model.BulkRefundProgress{BulkID: bulk.ID(), Cursor: "c", Done: m.Batches > 1}, bulk.Validate()
+}
+
+type MockPublisher struct {
+	Types []string
+	Data  []interface{}
+	Err   error
+}
+
+func (m *MockPublisher) Publish(eventType, _ string, data interface{}) error {
+	m.Types = append(m.Types, eventType)
+	m.Data = append(m.Data, data)
+	return m.Err
+}
+
+func TestBulkRefundHandler_Handle_WithContinuation(t *testing.T) {
+	mockUC := &MockBulkRefundUseCase{}
+	publisher := &MockPublisher{}
+	h := handler.NewBulkRefundHandler(mockUC, handler.WithContinuation(publisher))
+	msg := []byte(`{"caller_prefix":"+54911","from":"2024-08-29T10:00:00Z","to":"2024-08-29T12:00:00Z","reason":"Corte"}`)
+
+	if err := h.Handle(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mockUC.Called || mockUC.Batches != 1 {
+		t.Fatalf("expected a single batch, got Execute=%v batches=%d", mockUC.Called, mockUC.Batches)
+	}
+	if len(publisher.Types) != 1 || publisher.Types[0] != "bulk_refund" {
+		t.Fatalf("expected the next batch to be published, got %v", publisher.Types)
+	}
+	next, ok := publisher.Data[0].(dto.BulkRefundDTO)
+	if !ok || next.BulkID != mockUC.Input.ID() || next.CallerPrefix != "+54911" {
+		t.Fatalf("unexpected continuation %+v", publisher.Data[0])
+	}
+
+	// El último lote termina el refund masivo sin publicar otro mensaje
+	if err := h.Handle(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(publisher.Types) != 1 {
+		t.Errorf("expected no continuation after the last batch, got %v", publisher.Types)
+	}
+}
+
+func TestBulkRefundHandler_Handle_ContinuationPublishErrorIsRetryable(t *testing.T) {
+	h := handler.NewBulkRefundHandler(&MockBulkRefundUseCase{}, handler.WithContinuation(&MockPublisher{Err: errors.New("canal cerrado")}))
+
+	err := h.Handle([]byte(`{"caller_prefix":"+54911","from":"2024-08-29T10:00:00Z","to":"2024-08-29T12:00:00Z","reason":"Corte"}`))
+
+	if err == nil || model.IsPermanent(err) {
+		t.Errorf("expected a retryable error so the message resumes from the saved batch, got %v", err)
+	}
+}
+
+func TestBulkRefundHandler_Handle_Success(t *testing.T) {
+	mockUC := &MockBulkRefundUseCase{}
+	h := handler.NewBulkRefundHandler(mockUC)
+
+	err := h.Handle([]byte(`{"bulk_id":"corte-0829","callers":["+5491111111111","+5491122222222"],"from":"2024-08-29T10:00:00Z","to":"2024-08-29T12:00:00-03:00","statuses":["OK"],"reason":"Corte de red","percent":50}`))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f := mockUC.Input.Filter
+	if mockUC.Input.BulkID != "corte-0829" || len(f.Callers) != 2 || f.Statuses[0] != "OK" || *mockUC.Input.Percent != 50 {
+		t.Errorf("unexpected input %+v", mockUC.Input)
+	}
+	if !f.From.Equal(time.Date(2024, 8, 29, 10, 0, 0, 0, time.UTC)) || !f.To.Equal(time.Date(2024, 8, 29, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected window [%s, %s)", f.From, f.To)
+	}
+}
+
+func TestBulkRefundHandler_Handle_InvalidPayloadIsPermanent(t *testing.T) {
+	for name, msg := range map[string]string{
+		"no JSON":       "not-json",
+		"from inválido": `{"caller_prefix":"+54911","from":"ayer","to":"2024-08-29T12:00:00Z","reason":"Corte"}`,
+		"sin callers":   `{"from":"2024-08-29T10:00:00Z","to":"2024-08-29T12:00:00Z","reason":"Corte"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := handler.NewBulkRefundHandler(&MockBulkRefundUseCase{})
+
+			if err := h.Handle([]byte(msg)); !model.IsPermanent(err) {
+				t.Errorf("expected permanent error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBulkRefundHandler_Handle_UseCaseError(t *testing.T) {
+	h := handler.NewBulkRefundHandler(&MockBulkRefundUseCase{Err: errors.New("db down")})
+
+	err := h.Handle([]byte(`{"caller_prefix":"+54911","from":"2024-08-29T10:00:00Z","to":"2024-08-29T12:00:00Z","reason":"Corte"}`))
+
+	if err == nil || model.IsPermanent(err) {
+		t.Errorf("expected a retryable error so the message is retried and resumes, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"phonecall-cost-processor-service/internal/application"
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/infrastructure/rabbitmq/dto"
+)
+
+type CallCostAdjustedHandler struct {
+	useCase application.ICallCostAdjustedUseCase
+}
+
+func NewCallCostAdjustedHandler(useCase application.ICallCostAdjustedUseCase) *CallCostAdjustedHandler {
+	return &CallCostAdjustedHandler{useCase: useCase}
+}
+
+func (h *CallCostAdjustedHandler) Handle(msg []byte) error {
+	adj, err := parseCallCostAdjusted(msg)
+	if err != nil {
+		return err
+	}
+	saved, err := h.useCase.Execute(adj)
+	if err != nil {
+		if model.IsPermanent(err) {
+			log.Printf("⚠️ Ajuste de costo rechazado: %v", err)
+		} else {
+			log.Printf("❌ Error guardando ajuste de costo: %v", err)
+		}
+		return err
+	}
+	if !saved {
+		log.Printf("ℹ️ call_id=%s ya tenía el mismo ajuste de costo, se descarta", adj.CallID)
+		return nil
+	}
+
+	log.Printf("✏️ Costo ajustado call_id=%s: %s %s por %s (%s)", adj.CallID, adj.Cost.String(), adj.Cost.Currency, adj.Actor, adj.Reason)
+	return nil
+}
+
+// Validate revisa el mensaje sin aplicarlo (ver messaging.Validator).
+func (h *CallCostAdjustedHandler) Validate(msg []byte) error {
+	_, err := parseCallCostAdjusted(msg)
+	return err
+}
+
+func parseCallCostAdjusted(msg []byte) (model.CostAdjustment, error) {
+	var d dto.CallCostAdjustedDTO
+	if err := json.Unmarshal(msg, &d); err != nil {
+		log.Printf("❌ Error parseando DTO de ajuste de costo: %v", err)
+		return model.CostAdjustment{}, model.Permanent(fmt.Errorf("payload inválido para call_cost_adjusted: %w", err))
+	}
+
+	adj, err := CostAdjustmentFromDTO(d)
+	if err == nil {
+		err = adj.Validate()
+	}
+	if err != nil {
+		log.Printf("⚠️ Ajuste de costo rechazado: %v", err)
+		return adj, err
+	}
+	return adj, nil
+}
+
+// CostAdjustmentFromDTO convierte el payload en el ajuste; un costo ilegible
+// es un error de validación del campo cost.
+func CostAdjustmentFromDTO(d dto.CallCostAdjustedDTO) (model.CostAdjustment, error) {
+	adj := model.CostAdjustment{CallID: d.CallID, Reason: d.Reason, Actor: d.Actor}
+	cost, err := model.ParseMoney(d.Cost.String(), d.Currency)
+	if err != nil {
+		return adj, &model.ValidationError{Entity: "call_cost_adjusted", Fields: []model.FieldError{{Field: "cost", Message: err.Error()}}}
+	}
+	adj.Cost = cost
+	return adj, nil
+}
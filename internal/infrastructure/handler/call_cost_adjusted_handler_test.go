@@ -0,0 +1,74 @@
+package handler_test
+
+import (
+	"errors"
+	"testing"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/infrastructure/handler"
+)
+
+type MockCallCostAdjustedUseCase struct {
+	Called bool
+	Input  model.CostAdjustment
+	Saved  bool
+	Err    error
+}
+
+func (m *MockCallCostAdjustedUseCase) Execute(adj model.CostAdjustment) (bool, error) {
+	m.Called = true
+	m.Input = adj
+	return m.Saved, m.Err
+}
+
+func TestCallCostAdjustedHandler_Handle_Success(t *testing.T) {
+	mockUC := &MockCallCostAdjustedUseCase{Saved: true}
+	h := handler.NewCallCostAdjustedHandler(mockUC)
+
+	err := h.Handle([]byte(`{"call_id":"550e8400-e29b-41d4-a716-446655440000","cost":7.5,"currency":"ARS","reason":"descuento contractual","actor":"finanzas@telco"}`))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := model.CostAdjustment{
+		CallID: "550e8400-e29b-41d4-a716-446655440000",
+		Cost:   model.MustParseMoney("7.50", "ARS"),
+		Reason: "descuento contractual",
+		Actor:  "finanzas@telco",
+	}
+	if mockUC.Input != want {
+		t.Errorf("expected input %+v but got %+v", want, mockUC.Input)
+	}
+}
+
+func TestCallCostAdjustedHandler_Handle_InvalidPayloadIsPermanent(t *testing.T) {
+	for name, msg := range map[string]string{
+		"no JSON":    "not-json",
+		"sin cost":   `{"call_id":"550e8400-e29b-41d4-a716-446655440000","currency":"ARS","reason":"x","actor":"y"}`,
+		"cost texto": `{"call_id":"550e8400-e29b-41d4-a716-446655440000","cost":"siete","currency":"ARS"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockUC := &MockCallCostAdjustedUseCase{}
+			h := handler.NewCallCostAdjustedHandler(mockUC)
+
+			err := h.Handle([]byte(msg))
+
+			if !model.IsPermanent(err) {
+				t.Errorf("expected permanent error, got %v", err)
+			}
+			if mockUC.Called {
+				t.Error("Execute should not be called")
+			}
+		})
+	}
+}
+
+func TestCallCostAdjustedHandler_Handle_UseCaseError(t *testing.T) {
+	h := handler.NewCallCostAdjustedHandler(&MockCallCostAdjustedUseCase{Err: errors.New("db down")})
+
+	err := h.Handle([]byte(`{"call_id":"550e8400-e29b-41d4-a716-446655440000","cost":"7.00","currency":"ARS","reason":"x","actor":"y"}`))
+
+	if err == nil || model.IsPermanent(err) {
+		t.Errorf("expected a retryable error, got %v", err)
+	}
+}
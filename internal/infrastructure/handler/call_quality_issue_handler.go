@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"phonecall-cost-processor-service/internal/application"
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/infrastructure/rabbitmq/dto"
+)
+
+type CallQualityIssueHandler struct {
+	useCase application.ICallQualityIssueUseCase
+}
+
+func NewCallQualityIssueHandler(useCase application.ICallQualityIssueUseCase) *CallQualityIssueHandler {
+	return &CallQualityIssueHandler{useCase: useCase}
+}
+
+// Handle registra el crédito del problema. Cada llamada conserva un solo
+// crédito, el de mayor porcentaje: un problema igual o menos severo se
+// confirma y se descarta, y uno más severo reemplaza severidad y tipo del
+// registrado.
+func (h *CallQualityIssueHandler) Handle(msg []byte) error {
+	issue, err := parseCallQualityIssue(msg)
+	if err != nil {
+		return err
+	}
+
+	credit, err := h.useCase.Execute(issue)
+	if err != nil {
+		log.Printf("❌ Error registrando crédito por calidad: %v", err)
+		return err
+	}
+	if credit == nil {
+		log.Printf("ℹ️ call_id=%s ya tenía un crédito igual o mayor, se descarta %s/%s", issue.CallID, issue.Severity, issue.IssueType)
+		return nil
+	}
+
+	log.Printf("🎧 Crédito por calidad registrado call_id=%s: %d%% (%s/%s)", credit.CallID, credit.Percent, credit.Severity, credit.IssueType)
+	return nil
+}
+
+// Validate revisa el mensaje sin aplicarlo (ver messaging.Validator).
+func (h *CallQualityIssueHandler) Validate(msg []byte) error {
+	_, err := parseCallQualityIssue(msg)
+	return err
+}
+
+func parseCallQualityIssue(msg []byte) (model.CallQualityIssue, error) {
+	var d dto.CallQualityIssueDTO
+	if err := json.Unmarshal(msg, &d); err != nil {
+		log.Printf("❌ Error parseando DTO de problema de calidad: %v", err)
+		return model.CallQualityIssue{}, model.Permanent(fmt.Errorf("payload inválido para call_quality_issue: %w", err))
+	}
+
+	issue := model.CallQualityIssue{
+		CallID:    d.CallID,
+		Severity:  model.QualitySeverity(d.Severity),
+		IssueType: d.IssueType,
+	}
+	if err := issue.Validate(); err != nil {
+		log.Printf("⚠️ Problema de calidad rechazado: %v", err)
+		return issue, err
+	}
+	return issue, nil
+}
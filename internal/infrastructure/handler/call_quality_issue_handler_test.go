@@ -0,0 +1,70 @@
+package handler_test
+
+import (
+	"errors"
+	"testing"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/infrastructure/handler"
+)
+
+type MockCallQualityIssueUseCase struct {
+	Called bool
+	Input  model.CallQualityIssue
+	Err    error
+}
+
+func (m *MockCallQualityIssueUseCase) Execute(issue model.CallQualityIssue) (*model.QualityCredit, error) {
+	m.Called = true
+	m.Input = issue
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &model.QualityCredit{CallID: issue.CallID, Severity: issue.Severity, IssueType: issue.IssueType, Percent: 25}, nil
+}
+
+func TestCallQualityIssueHandler_Handle_Success(t *testing.T) {
+	mockUC := &MockCallQualityIssueUseCase{}
+	h := handler.NewCallQualityIssueHandler(mockUC)
+
+	err := h.Handle([]byte(`{"call_id":"550e8400-e29b-41d4-a716-446655440000","severity":"high","issue_type":"dropped"}`))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := model.CallQualityIssue{CallID: "550e8400-e29b-41d4-a716-446655440000", Severity: model.SeverityHigh, IssueType: "dropped"}
+	if mockUC.Input != want {
+		t.Errorf("expected input %+v but got %+v", want, mockUC.Input)
+	}
+}
+
+func TestCallQualityIssueHandler_Handle_InvalidPayloadIsPermanent(t *testing.T) {
+	for name, msg := range map[string]string{
+		"no JSON":            "not-json",
+		"severidad inválida": `{"call_id":"550e8400-e29b-41d4-a716-446655440000","severity":"grave","issue_type":"dropped"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockUC := &MockCallQualityIssueUseCase{}
+			h := handler.NewCallQualityIssueHandler(mockUC)
+
+			err := h.Handle([]byte(msg))
+
+			if !model.IsPermanent(err) {
+				t.Errorf("expected permanent error, got %v", err)
+			}
+			if mockUC.Called {
+				t.Error("Execute should not be called")
+			}
+		})
+	}
+}
+
+func TestCallQualityIssueHandler_Handle_UseCaseError(t *testing.T) {
+	h := handler.NewCallQualityIssueHandler(&MockCallQualityIssueUseCase{Err: errors.New("db down")})
+
+	err := h.Handle([]byte(`{"call_id":"550e8400-e29b-41d4-a716-446655440000","severity":"low","issue_type":"echo"}`))
+
+	if err == nil || model.IsPermanent(err) {
+		t.Errorf("expected transient error, got %v", err)
+	}
+}
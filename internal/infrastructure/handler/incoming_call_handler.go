@@ -2,7 +2,6 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 	"time"
 
@@ -12,23 +11,50 @@ import (
 )
 
 type IncomingCallHandler struct {
-    useCase application.IIncomingCallUseCase
+    useCase    application.IIncomingCallUseCase
+    timestamps *model.TimestampParser
+    // sources son los parsers por atributo source del CloudEvent, para
+    // productores con su propia zona horaria.
+    sources map[string]*model.TimestampParser
 }
 
-func NewIncomingCallHandler(useCase application.IIncomingCallUseCase) *IncomingCallHandler {
-    return &IncomingCallHandler{useCase: useCase}
+type IncomingCallHandlerOption func(*IncomingCallHandler)
+
+// WithTimestampParser define los formatos y la zona horaria con que se lee
+// start_timestamp. Por defecto: model.DefaultTimestampParser.
+func WithTimestampParser(p *model.TimestampParser) IncomingCallHandlerOption {
+    return func(h *IncomingCallHandler) { h.timestamps = p }
+}
+
+// WithSourceTimestampParsers usa, para los mensajes de cada source, su propio
+// parser en vez del de WithTimestampParser.
+func WithSourceTimestampParsers(parsers map[string]*model.TimestampParser) IncomingCallHandlerOption {
+    return func(h *IncomingCallHandler) { h.sources = parsers }
+}
+
+func NewIncomingCallHandler(useCase application.IIncomingCallUseCase, opts ...IncomingCallHandlerOption) *IncomingCallHandler {
+    h := &IncomingCallHandler{useCase: useCase, timestamps: model.DefaultTimestampParser()}
+    for _, opt := range opts {
+        opt(h)
+    }
+    return h
 }
 
 func (h *IncomingCallHandler) Handle(msg []byte) error {
+    return h.HandleFrom("", msg)
+}
+
+// HandleFrom lee start_timestamp con el parser de source, si tiene uno.
+func (h *IncomingCallHandler) HandleFrom(source string, msg []byte) error {
+    timestamps := h.timestamps
+    if p, ok := h.sources[source]; ok {
+        timestamps = p
+    }
+
     var d dto.NewIncomingCallDTO
     if err := json.Unmarshal(msg, &d); err != nil {
         log.Printf("❌ Error parseando DTO: %v\n", err)
-        return err
-    }
-
-    startTime, err := time.Parse(time.RFC3339, d.StartTimestamp)
-    if err != nil {
-        return fmt.Errorf("start_timestamp inválido: %w", err)
+        return model.Permanent(err)
     }
 
     call := model.NewIncomingCall{
@@ -36,7 +62,20 @@ func (h *IncomingCallHandler) Handle(msg []byte) error {
         Caller:         d.Caller,
         Receiver:       d.Receiver,
         DurationInSec:  d.DurationInSec,
-        StartTimestamp: startTime.Format(time.RFC3339),
+    }
+    if d.StartTimestamp != "" {
+        start, err := timestamps.Parse(string(d.StartTimestamp))
+        if err != nil {
+            log.Printf("⚠️ Llamada rechazada: %v\n", err)
+            return &model.ValidationError{Entity: "new_incoming_call", Fields: []model.FieldError{
+                {Field: "start_timestamp", Message: err.Error()},
+            }}
+        }
+        call.StartTimestamp = start
+    }
+    if err := call.Validate(time.Now()); err != nil {
+        log.Printf("⚠️ Llamada rechazada: %v\n", err)
+        return err
     }
 
     if err := h.useCase.Execute(call); err != nil {
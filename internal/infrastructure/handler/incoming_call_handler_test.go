@@ -3,7 +3,9 @@ package handler_test
 import (
 	"encoding/json"
 	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"phonecall-cost-processor-service/internal/domain/model"
 	"phonecall-cost-processor-service/internal/infrastructure/handler"
@@ -29,13 +31,12 @@ func TestIncomingCallHandler_Handle_Success(t *testing.T) {
 	mockUC := &MockIncomingCallUseCase{}
 	h := handler.NewIncomingCallHandler(mockUC)
 
-	tsStr := "2025-07-25T03:00:00Z"
 	d := dto.NewIncomingCallDTO{
-		CallID:         "123",
+		CallID:         "550e8400-e29b-41d4-a716-446655440000",
 		Caller:         "+123",
 		Receiver:       "+456",
 		DurationInSec:  60,
-		StartTimestamp: tsStr,
+		StartTimestamp: "2025-07-25T00:00:00-03:00",
 	}
 	jsonBytes, err := json.Marshal(d)
 	if err != nil {
@@ -55,7 +56,7 @@ func TestIncomingCallHandler_Handle_Success(t *testing.T) {
 		Caller:         d.Caller,
 		Receiver:       d.Receiver,
 		DurationInSec:  d.DurationInSec,
-		StartTimestamp: tsStr,
+		StartTimestamp: time.Date(2025, 7, 25, 3, 0, 0, 0, time.UTC),
 	}
 	if mockUC.Input != expected {
 		t.Errorf("expected input %+v but got %+v", expected, mockUC.Input)
@@ -79,13 +80,12 @@ func TestIncomingCallHandler_Handle_UseCaseError(t *testing.T) {
 	mockUC := &MockIncomingCallUseCase{ShouldErr: true}
 	h := handler.NewIncomingCallHandler(mockUC)
 
-	tsStr := "2025-07-25T03:00:00Z"
 	d := dto.NewIncomingCallDTO{
-		CallID:         "123",
+		CallID:         "550e8400-e29b-41d4-a716-446655440000",
 		Caller:         "+123",
 		Receiver:       "+456",
 		DurationInSec:  60,
-		StartTimestamp: tsStr,
+		StartTimestamp: "2025-07-25T03:00:00Z",
 	}
 	jsonBytes, _ := json.Marshal(d)
 
@@ -94,3 +94,114 @@ func TestIncomingCallHandler_Handle_UseCaseError(t *testing.T) {
 		t.Error("expected error from use case")
 	}
 }
+
+func TestIncomingCallHandler_Handle_InvalidPayloadIsPermanent(t *testing.T) {
+	mockUC := &MockIncomingCallUseCase{}
+	h := handler.NewIncomingCallHandler(mockUC)
+
+	d := dto.NewIncomingCallDTO{
+		CallID:         "123",
+		Caller:         "+123",
+		DurationInSec:  0,
+		StartTimestamp: dto.Timestamp(time.Now().Add(time.Hour).UTC().Format(time.RFC3339)),
+	}
+	jsonBytes, _ := json.Marshal(d)
+
+	err := h.Handle(jsonBytes)
+
+	var verr *model.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if !model.IsPermanent(err) {
+		t.Error("validation errors should be permanent")
+	}
+	var fields []string
+	for _, f := range verr.Fields {
+		fields = append(fields, f.Field)
+	}
+	expected := []string{"call_id", "receiver", "duration_in_seconds", "start_timestamp"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected fields %v, got %v", expected, fields)
+	}
+	if mockUC.Called {
+		t.Error("Execute should not be called")
+	}
+}
+
+func TestIncomingCallHandler_Handle_TimestampFormats(t *testing.T) {
+	buenosAires := time.FixedZone("ART", -3*3600)
+	parser, err := model.NewTimestampParser([]string{model.TimestampRFC3339, model.TimestampEpochMillis, model.TimestampLocal}, buenosAires)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := time.Date(2025, 7, 25, 3, 0, 0, 0, time.UTC)
+
+	tests := map[string]string{
+		"epoch ms como número": `1753412400000`,
+		"epoch ms como texto":  `"1753412400000"`,
+		"RFC3339Nano":          `"2025-07-25T03:00:00.000000000Z"`,
+		"local sin offset":     `"2025-07-25 00:00:00"`,
+	}
+	for name, ts := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockUC := &MockIncomingCallUseCase{}
+			h := handler.NewIncomingCallHandler(mockUC, handler.WithTimestampParser(parser))
+
+			msg := `{"call_id":"550e8400-e29b-41d4-a716-446655440000","caller":"+123","receiver":"+456",` +
+				`"duration_in_seconds":60,"start_timestamp":` + ts + `}`
+			if err := h.Handle([]byte(msg)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !mockUC.Input.StartTimestamp.Equal(want) {
+				t.Errorf("expected %s, got %s", want, mockUC.Input.StartTimestamp)
+			}
+		})
+	}
+}
+
+func TestIncomingCallHandler_HandleFrom_SourceTimezone(t *testing.T) {
+	madrid, err := model.NewTimestampParser(nil, time.FixedZone("CEST", 2*3600))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg := []byte(`{"call_id":"550e8400-e29b-41d4-a716-446655440000","caller":"+123","receiver":"+456",` +
+		`"duration_in_seconds":60,"start_timestamp":"2025-07-25 05:00:00"}`)
+
+	tests := map[string]time.Time{
+		"/carrier-es":   time.Date(2025, 7, 25, 3, 0, 0, 0, time.UTC),
+		"/otro-carrier": time.Date(2025, 7, 25, 5, 0, 0, 0, time.UTC),
+		"":              time.Date(2025, 7, 25, 5, 0, 0, 0, time.UTC),
+	}
+	for source, want := range tests {
+		mockUC := &MockIncomingCallUseCase{}
+		h := handler.NewIncomingCallHandler(mockUC, handler.WithSourceTimestampParsers(map[string]*model.TimestampParser{"/carrier-es": madrid}))
+
+		if err := h.HandleFrom(source, msg); err != nil {
+			t.Fatalf("source %q: unexpected error: %v", source, err)
+		}
+		if !mockUC.Input.StartTimestamp.Equal(want) {
+			t.Errorf("source %q: expected %s, got %s", source, want, mockUC.Input.StartTimestamp)
+		}
+	}
+}
+
+func TestIncomingCallHandler_Handle_UnparseableTimestampIsPermanent(t *testing.T) {
+	mockUC := &MockIncomingCallUseCase{}
+	h := handler.NewIncomingCallHandler(mockUC)
+
+	msg := `{"call_id":"550e8400-e29b-41d4-a716-446655440000","caller":"+123","receiver":"+456",` +
+		`"duration_in_seconds":60,"start_timestamp":"25/07/2025"}`
+	err := h.Handle([]byte(msg))
+
+	var verr *model.ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != "start_timestamp" {
+		t.Fatalf("expected start_timestamp ValidationError, got %v", err)
+	}
+	if !model.IsPermanent(err) {
+		t.Error("validation errors should be permanent")
+	}
+	if mockUC.Called {
+		t.Error("Execute should not be called")
+	}
+}
@@ -22,18 +22,37 @@ func (h *RefundCallHandler) Handle(msg []byte) error {
 	var d dto.RefundCallDTO
 	if err := json.Unmarshal(msg, &d); err != nil {
 		log.Printf("❌ Error parseando DTO de refund: %v", err)
-		return fmt.Errorf("payload inválido para refund_call: %w", err)
+		return model.Permanent(fmt.Errorf("payload inválido para refund_call: %w", err))
 	}
 
 	refund := model.RefundCall{
-		CallID: d.CallID,
-		Reason: d.Reason,
+		CallID:   d.CallID,
+		Reason:   d.Reason,
+		RefundID: d.RefundID,
+		Percent:  d.Percent,
+	}
+	if d.Amount != "" {
+		amount, err := model.ParseMoney(d.Amount.String(), d.Currency)
+		if err != nil {
+			log.Printf("⚠️ Refund rechazado: %v", err)
+			return &model.ValidationError{Entity: "refund_call", Fields: []model.FieldError{{Field: "amount", Message: err.Error()}}}
+		}
+		refund.Amount = &amount
+	}
+	if err := refund.Validate(); err != nil {
+		log.Printf("⚠️ Refund rechazado: %v", err)
+		return err
 	}
 
-	if err := h.useCase.Execute(refund); err != nil {
+	applied, err := h.useCase.Execute(refund)
+	if err != nil {
 		log.Printf("❌ Error aplicando refund: %v", err)
 		return err
 	}
+	if !applied {
+		log.Printf("ℹ️ call_id=%s ya tenía el refund %s, se descarta", refund.CallID, refund.ID())
+		return nil
+	}
 
 	log.Printf("💸 Refund aplicado correctamente: %+v", refund)
 	return nil
@@ -17,13 +17,13 @@ type MockRefundCallUseCase struct {
 	ShouldFail bool
 }
 
-func (m *MockRefundCallUseCase) Execute(refund model.RefundCall) error {
+func (m *MockRefundCallUseCase) Execute(refund model.RefundCall) (bool, error) {
 	m.Called = true
 	m.Input = refund
 	if m.ShouldFail {
-		return errors.New("apply refund failed")
+		return false, errors.New("apply refund failed")
 	}
-	return nil
+	return true, nil
 }
 
 func TestRefundCallHandler_Handle_Success(t *testing.T) {
@@ -81,3 +81,54 @@ func TestRefundCallHandler_Handle_UseCaseError(t *testing.T) {
 		t.Error("expected error from use case")
 	}
 }
+
+func TestRefundCallHandler_Handle_InvalidCallIDIsPermanent(t *testing.T) {
+	mockUC := &MockRefundCallUseCase{}
+	h := handler.NewRefundCallHandler(mockUC)
+
+	msg, _ := json.Marshal(dto.RefundCallDTO{CallID: "abc-123", Reason: "reclamo"})
+
+	err := h.Handle(msg)
+	if !model.IsPermanent(err) {
+		t.Errorf("expected a permanent error, got %v", err)
+	}
+	if mockUC.Called {
+		t.Error("Execute should not be called with an invalid call_id")
+	}
+}
+
+func TestRefundCallHandler_Handle_PartialAmount(t *testing.T) {
+	mockUC := &MockRefundCallUseCase{}
+	h := handler.NewRefundCallHandler(mockUC)
+
+	msg := []byte(`{"call_id":"550e8400-e29b-41d4-a716-446655440000","reason":"corte","refund_id":"r1","amount":2.5,"currency":"ARS"}`)
+	if err := h.Handle(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := mockUC.Input
+	if got.RefundID != "r1" || got.Amount == nil || *got.Amount != model.MustParseMoney("2.50", "ARS") || got.Full() {
+		t.Errorf("unexpected refund %+v", got)
+	}
+}
+
+func TestRefundCallHandler_Handle_InvalidPartialRefund(t *testing.T) {
+	cases := map[string]string{
+		"moneda desconocida":     `{"call_id":"550e8400-e29b-41d4-a716-446655440000","amount":"2.50","currency":"XXX"}`,
+		"amount y percent":       `{"call_id":"550e8400-e29b-41d4-a716-446655440000","amount":"2.50","currency":"ARS","percent":10}`,
+		"percent fuera de rango": `{"call_id":"550e8400-e29b-41d4-a716-446655440000","percent":150}`,
+	}
+	for name, msg := range cases {
+		t.Run(name, func(t *testing.T) {
+			mockUC := &MockRefundCallUseCase{}
+			h := handler.NewRefundCallHandler(mockUC)
+
+			err := h.Handle([]byte(msg))
+			if !model.IsPermanent(err) {
+				t.Errorf("expected permanent error, got %v", err)
+			}
+			if mockUC.Called {
+				t.Error("Execute should not be called on invalid refund")
+			}
+		})
+	}
+}
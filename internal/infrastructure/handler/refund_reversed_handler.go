@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"phonecall-cost-processor-service/internal/application"
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/infrastructure/rabbitmq/dto"
+)
+
+type RefundReversedHandler struct {
+	useCase application.IRefundReversedUseCase
+}
+
+func NewRefundReversedHandler(useCase application.IRefundReversedUseCase) *RefundReversedHandler {
+	return &RefundReversedHandler{useCase: useCase}
+}
+
+func (h *RefundReversedHandler) Handle(msg []byte) error {
+	reversal, err := parseRefundReversed(msg)
+	if err != nil {
+		return err
+	}
+
+	if err := h.useCase.Execute(reversal); err != nil {
+		log.Printf("❌ Error anulando refund: %v", err)
+		return err
+	}
+
+	log.Printf("↩️ Anulación de refund registrada: %s", reversal)
+	return nil
+}
+
+// Validate revisa el mensaje sin aplicarlo (ver messaging.Validator).
+func (h *RefundReversedHandler) Validate(msg []byte) error {
+	_, err := parseRefundReversed(msg)
+	return err
+}
+
+func parseRefundReversed(msg []byte) (model.RefundReversal, error) {
+	var d dto.RefundReversedDTO
+	if err := json.Unmarshal(msg, &d); err != nil {
+		log.Printf("❌ Error parseando DTO de anulación de refund: %v", err)
+		return model.RefundReversal{}, model.Permanent(fmt.Errorf("payload inválido para refund_reversed: %w", err))
+	}
+
+	reversal := model.RefundReversal{
+		CallID:   d.CallID,
+		RefundID: d.RefundID,
+		Reason:   d.Reason,
+	}
+	if err := reversal.Validate(); err != nil {
+		log.Printf("⚠️ Anulación de refund rechazada: %v", err)
+		return reversal, err
+	}
+	return reversal, nil
+}
@@ -0,0 +1,67 @@
+package handler_test
+
+import (
+	"errors"
+	"testing"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/infrastructure/handler"
+)
+
+type MockRefundReversedUseCase struct {
+	Called bool
+	Input  model.RefundReversal
+	Err    error
+}
+
+func (m *MockRefundReversedUseCase) Execute(reversal model.RefundReversal) error {
+	m.Called = true
+	m.Input = reversal
+	return m.Err
+}
+
+func TestRefundReversedHandler_Handle_Success(t *testing.T) {
+	mockUC := &MockRefundReversedUseCase{}
+	h := handler.NewRefundReversedHandler(mockUC)
+
+	err := h.Handle([]byte(`{"call_id":"550e8400-e29b-41d4-a716-446655440000","refund_id":"r1","reason":"refund por error"}`))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := model.RefundReversal{CallID: "550e8400-e29b-41d4-a716-446655440000", RefundID: "r1", Reason: "refund por error"}
+	if mockUC.Input != want {
+		t.Errorf("expected input %+v but got %+v", want, mockUC.Input)
+	}
+}
+
+func TestRefundReversedHandler_Handle_InvalidPayloadIsPermanent(t *testing.T) {
+	for name, msg := range map[string]string{
+		"no JSON":          "not-json",
+		"call_id inválido": `{"call_id":"abc","reason":"refund por error"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockUC := &MockRefundReversedUseCase{}
+			h := handler.NewRefundReversedHandler(mockUC)
+
+			err := h.Handle([]byte(msg))
+
+			if !model.IsPermanent(err) {
+				t.Errorf("expected permanent error, got %v", err)
+			}
+			if mockUC.Called {
+				t.Error("Execute should not be called")
+			}
+		})
+	}
+}
+
+func TestRefundReversedHandler_Handle_UseCaseError(t *testing.T) {
+	h := handler.NewRefundReversedHandler(&MockRefundReversedUseCase{Err: errors.New("db down")})
+
+	err := h.Handle([]byte(`{"call_id":"550e8400-e29b-41d4-a716-446655440000"}`))
+
+	if err == nil || model.IsPermanent(err) {
+		t.Errorf("expected a retryable error, got %v", err)
+	}
+}
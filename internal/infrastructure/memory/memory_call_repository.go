@@ -0,0 +1,671 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+
+	"github.com/google/uuid"
+)
+
+// Call es el estado de una fila de la tabla calls.
+type Call struct {
+	CallID         string
+	Caller         *string
+	Receiver       *string
+	Numbering      model.CallNumbering
+	DurationInSec  *int
+	StartTimestamp *time.Time
+	Cost           *model.Money
+	BaseCost       *model.Money
+	Provider       *string
+	Breakdown      model.CostBreakdown
+	Refunded       bool
+	RefundReason   *string
+	Status         string
+	PriorStatus    string // estado que tendría sin el refund total
+	ProcessedAt    time.Time
+	CreatedAt      time.Time
+}
+
+// reversal es una fila de refund_reversals: appliedTo es el refund que
+// anuló, vacío mientras está pendiente.
+type reversal struct {
+	model.RefundReversal
+	appliedTo string
+}
+
+// CallRepository implementa repository.CallRepository en memoria
+// reproduciendo la semántica de las queries de PostgresCallRepository.
+type CallRepository struct {
+	mu          sync.RWMutex
+	calls       map[string]*Call
+	shadows     map[string]model.ShadowCost
+	credits     map[string]model.QualityCredit
+	refunds     map[string][]model.RefundCall
+	reversals   map[string][]reversal
+	adjustments map[string]model.CostAdjustment
+	bulks       map[string]model.BulkRefundProgress
+	pending     map[string][]model.PendingEvent
+	pendingSeq  int64
+	events      map[string][]model.CallEvent
+	now         func() time.Time
+}
+
+var _ repository.CallRepository = (*CallRepository)(nil)
+
+func NewCallRepository() *CallRepository {
+	return &CallRepository{
+		calls:       make(map[string]*Call),
+		shadows:     make(map[string]model.ShadowCost),
+		credits:     make(map[string]model.QualityCredit),
+		refunds:     make(map[string][]model.RefundCall),
+		reversals:   make(map[string][]reversal),
+		adjustments: make(map[string]model.CostAdjustment),
+		bulks:       make(map[string]model.BulkRefundProgress),
+		pending:     make(map[string][]model.PendingEvent),
+		events:      make(map[string][]model.CallEvent),
+		now:         time.Now,
+	}
+}
+
+// Find devuelve una copia de la llamada guardada.
+func (r *CallRepository) Find(callID string) (Call, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.calls[callID]
+	if !ok {
+		return Call{}, false
+	}
+	return *c, true
+}
+
+// INSERT ... ON CONFLICT (call_id) DO NOTHING
+func (r *CallRepository) SaveIncomingCall(call model.NewIncomingCall) error {
+	ts := call.StartTimestamp.UTC()
+	if err := validateCallID(call.CallID); err != nil {
+		return fmt.Errorf("error insertando llamada: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.calls[call.CallID]; ok {
+		return nil
+	}
+	r.calls[call.CallID] = &Call{
+		CallID:         call.CallID,
+		Caller:         strPtr(call.Caller),
+		Receiver:       strPtr(call.Receiver),
+		Numbering:      call.Numbering,
+		DurationInSec:  intPtr(call.DurationInSec),
+		StartTimestamp: &ts,
+		Status:         "PENDING",
+		ProcessedAt:    r.now(),
+		CreatedAt:      r.now(),
+	}
+	return nil
+}
+
+// UPDATE ... SET prior_status = 'OK', status = CASE WHEN status = 'REFUNDED' ... WHERE call_id = $10
+func (r *CallRepository) UpdateCallCost(callID string, result model.CostResult) error {
+	if err := result.Cost.CheckPrecision(repository.CostPrecision, repository.CostScale); err != nil {
+		return fmt.Errorf("error actualizando costo: %w", err)
+	}
+	if result.BaseCost != nil {
+		if err := result.BaseCost.CheckPrecision(repository.CostPrecision, repository.CostScale); err != nil {
+			return fmt.Errorf("error actualizando costo: %w", err)
+		}
+	}
+	if err := validateCallID(callID); err != nil {
+		return fmt.Errorf("error actualizando costo: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.calls[callID]
+	if !ok {
+		return nil
+	}
+	cost := result.Cost
+	c.Cost = &cost
+	c.BaseCost = result.BaseCost
+	c.Provider = nil
+	if result.Provider != "" {
+		c.Provider = strPtr(result.Provider)
+	}
+	c.Breakdown = result.Breakdown
+	c.setStatus("OK")
+	c.ProcessedAt = r.now()
+	r.rejectRefunds(callID, cost)
+	return nil
+}
+
+// UPDATE refunds SET rejected = $3 WHERE call_id = $1 AND refund_id = $2
+func (r *CallRepository) rejectRefunds(callID string, cost model.Money) {
+	if adj, ok := r.adjustments[callID]; ok {
+		cost = adj.Cost
+	}
+	refunds := r.refunds[callID]
+	for _, rejected := range model.RecheckRefunds(cost, refunds) {
+		for i := range refunds {
+			if refunds[i].ID() == rejected.ID() {
+				refunds[i].Rejected = rejected.Rejected
+			}
+		}
+		r.record(model.RejectedRefundEvent(rejected))
+	}
+}
+
+// UPDATE ... SET prior_status = 'ERROR', status = CASE WHEN status = 'REFUNDED' ... WHERE call_id = $1
+func (r *CallRepository) MarkCostAsFailed(callID string) error {
+	if err := validateCallID(callID); err != nil {
+		return fmt.Errorf("error marcando fallo de costo: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.calls[callID]
+	if !ok {
+		return nil
+	}
+	c.setStatus("ERROR")
+	c.ProcessedAt = r.now()
+	return nil
+}
+
+// setStatus no saca a una llamada de REFUNDED: guarda status como el previo
+// al refund para restaurarlo si se anula.
+func (c *Call) setStatus(status string) {
+	c.PriorStatus = status
+	if c.Status != "REFUNDED" {
+		c.Status = status
+	}
+}
+
+// SELECT ... FOR UPDATE; INSERT INTO refunds; UPDATE refund_reversals; INSERT ... 'REFUND_PARTIALLY' o UPDATE calls; INSERT INTO call_events
+func (r *CallRepository) ApplyRefund(refund model.RefundCall) (bool, error) {
+	if err := validateCallID(refund.CallID); err != nil {
+		return false, fmt.Errorf("error aplicando refund: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.calls[refund.CallID]
+	var cost *model.Money
+	if ok && (c.Status == "OK" || c.Status == "REFUNDED") {
+		cost = c.Cost
+		if adj, adjusted := r.adjustments[refund.CallID]; adjusted {
+			cost = &adj.Cost
+		}
+	}
+	save, err := model.CheckRefund(refund, cost, r.refunds[refund.CallID])
+	if err != nil {
+		return false, fmt.Errorf("error aplicando refund: %w", err)
+	}
+	if !save {
+		return false, nil
+	}
+	if i := pendingReversal(r.reversals[refund.CallID], refund); i >= 0 {
+		r.reversals[refund.CallID][i].appliedTo = refund.ID()
+		refund.Reversed = true
+	}
+	r.refunds[refund.CallID] = append(r.refunds[refund.CallID], refund)
+	r.record(model.RefundEvent(refund, refund.Reversed))
+
+	if !ok {
+		r.calls[refund.CallID] = &Call{
+			CallID:       refund.CallID,
+			Refunded:     refund.Full() && !refund.Reversed,
+			RefundReason: strPtr(refund.Reason),
+			Cost:         &model.Money{},
+			Status:       "REFUND_PARTIALLY",
+			ProcessedAt:  r.now(),
+			CreatedAt:    r.now(),
+		}
+		return true, nil
+	}
+	if !refund.Full() || refund.Reversed {
+		return true, nil
+	}
+	c.Refunded = true
+	c.RefundReason = strPtr(refund.Reason)
+	next := model.RefundStatus(refund, c.Status)
+	if prior := model.StatusBeforeRefund(c.Status, next); prior != "" {
+		c.PriorStatus = prior
+	}
+	c.Status = next
+	c.ProcessedAt = r.now()
+	return true, nil
+}
+
+// SELECT ... FOR UPDATE; SELECT EXISTS ... refund_reversals; INSERT INTO refund_reversals; UPDATE calls; INSERT INTO call_events
+func (r *CallRepository) ReverseRefund(v model.RefundReversal) error {
+	if err := validateCallID(v.CallID); err != nil {
+		return fmt.Errorf("error anulando refund: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.reversals[v.CallID] {
+		if existing.RefundID == v.RefundID {
+			return nil
+		}
+	}
+	refunds := r.refunds[v.CallID]
+	i := v.Target(refunds)
+	if i < 0 && v.AlreadyReversed(refunds) {
+		return nil
+	}
+	if i < 0 {
+		r.reversals[v.CallID] = append(r.reversals[v.CallID], reversal{RefundReversal: v})
+		r.record(model.ReversalEvent(v, nil, "", ""))
+		return nil
+	}
+	refunds[i].Reversed = true
+	r.reversals[v.CallID] = append(r.reversals[v.CallID], reversal{RefundReversal: v, appliedTo: refunds[i].ID()})
+
+	c, ok := r.calls[v.CallID]
+	if !ok {
+		r.record(model.ReversalEvent(v, &refunds[i], "", ""))
+		return nil
+	}
+	from := c.Status
+	c.Status = model.ReversedStatus(c.Status, c.PriorStatus, refunds)
+	c.Refunded = model.FilledStatus(refunds) == "REFUNDED"
+	c.ProcessedAt = r.now()
+	r.record(model.ReversalEvent(v, &refunds[i], from, c.Status))
+	return nil
+}
+
+// SELECT ... FOR UPDATE; SELECT ... FROM refunds; INSERT INTO cost_adjustments ... ON CONFLICT (call_id) DO UPDATE ... WHERE (cost, currency, reason, actor) IS DISTINCT FROM ...; INSERT INTO call_events
+func (r *CallRepository) AdjustCost(adj model.CostAdjustment) (bool, error) {
+	if err := adj.Cost.CheckPrecision(repository.CostPrecision, repository.CostScale); err != nil {
+		return false, fmt.Errorf("error ajustando costo: %w", err)
+	}
+	if err := validateCallID(adj.CallID); err != nil {
+		return false, fmt.Errorf("error ajustando costo: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := adj.CheckRefunds(r.refunds[adj.CallID]); err != nil {
+		return false, fmt.Errorf("error ajustando costo: %w", err)
+	}
+	if existing, ok := r.adjustments[adj.CallID]; ok && existing.Same(adj) {
+		return false, nil
+	}
+	adj.At = r.now()
+	r.adjustments[adj.CallID] = adj
+	r.record(model.AdjustmentEvent(adj))
+	return true, nil
+}
+
+// SELECT call_id FROM calls WHERE start_timestamp >= $1 AND start_timestamp < $2 AND caller ... AND status ... AND call_id > $n ORDER BY call_id LIMIT $m
+func (r *CallRepository) RefundCandidates(f model.BulkRefundFilter, afterCallID string, limit int) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids []string
+	for id, c := range r.calls {
+		if c.StartTimestamp == nil || c.Caller == nil || id <= afterCallID {
+			continue
+		}
+		if f.Matches(*c.Caller, *c.StartTimestamp, c.Status) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+// SELECT ... FROM bulk_refunds WHERE bulk_id = $1
+func (r *CallRepository) BulkRefundProgress(bulkID string) (model.BulkRefundProgress, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if p, ok := r.bulks[bulkID]; ok {
+		return p, nil
+	}
+	return model.BulkRefundProgress{BulkID: bulkID}, nil
+}
+
+// INSERT INTO bulk_refunds ... ON CONFLICT (bulk_id) DO UPDATE
+func (r *CallRepository) SaveBulkRefundProgress(p model.BulkRefundProgress) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bulks[p.BulkID] = p
+	return nil
+}
+
+// SELECT ... FROM calls / pending_events; INSERT INTO pending_events ... ON CONFLICT (call_id, event_id) DO NOTHING
+func (r *CallRepository) ParkEvent(e model.PendingEvent) (bool, error) {
+	if err := validateCallID(e.CallID); err != nil {
+		return false, fmt.Errorf("error estacionando evento: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	parked := r.pending[e.CallID]
+	if c, ok := r.calls[e.CallID]; ok && (c.Caller != nil || c.Status == "EXPIRED") && len(parked) == 0 {
+		return false, nil
+	}
+	id := e.ID()
+	for _, p := range parked {
+		if p.ID() == id {
+			return true, nil
+		}
+	}
+	r.pendingSeq++
+	e.Seq = r.pendingSeq
+	e.ReceivedAt = r.now()
+	r.pending[e.CallID] = append(parked, e)
+	return true, nil
+}
+
+// SELECT ... FROM pending_events WHERE call_id = $1 ORDER BY seq
+func (r *CallRepository) PendingEvents(callID string) ([]model.PendingEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]model.PendingEvent(nil), r.pending[callID]...), nil
+}
+
+// DELETE FROM pending_events WHERE call_id = $1 AND seq = $2
+func (r *CallRepository) DeletePendingEvent(callID string, seq int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rest []model.PendingEvent
+	for _, e := range r.pending[callID] {
+		if e.Seq != seq {
+			rest = append(rest, e)
+		}
+	}
+	if len(rest) == 0 {
+		delete(r.pending, callID)
+	} else {
+		r.pending[callID] = rest
+	}
+	return nil
+}
+
+// DELETE FROM pending_events WHERE created_at < $1 RETURNING ...
+func (r *CallRepository) ExpirePendingEvents(receivedBefore time.Time) ([]model.PendingEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var expired []model.PendingEvent
+	for callID, events := range r.pending {
+		var rest []model.PendingEvent
+		for _, e := range events {
+			if e.ReceivedAt.Before(receivedBefore) {
+				expired = append(expired, e)
+			} else {
+				rest = append(rest, e)
+			}
+		}
+		if len(rest) == 0 {
+			delete(r.pending, callID)
+		} else {
+			r.pending[callID] = rest
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].Seq < expired[j].Seq })
+	return expired, nil
+}
+
+// SELECT call_id, created_at FROM calls WHERE status = 'REFUND_PARTIALLY' AND caller IS NULL ORDER BY created_at
+func (r *CallRepository) Placeholders() ([]model.Placeholder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.placeholders(time.Time{}), nil
+}
+
+// UPDATE calls SET status = 'EXPIRED' ... WHERE status = 'REFUND_PARTIALLY' AND caller IS NULL AND created_at < $1 RETURNING ...; INSERT INTO call_events; DELETE FROM pending_events
+func (r *CallRepository) ExpirePlaceholders(createdBefore time.Time) ([]model.Placeholder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expired := r.placeholders(createdBefore)
+	for _, p := range expired {
+		c := r.calls[p.CallID]
+		c.Status = "EXPIRED"
+		c.ProcessedAt = r.now()
+		r.record(model.ExpiryEvent(p))
+		delete(r.pending, p.CallID)
+	}
+	return expired, nil
+}
+
+// placeholders devuelve los placeholders vigentes creados antes de
+// createdBefore (cero = todos), del más viejo al más nuevo.
+func (r *CallRepository) placeholders(createdBefore time.Time) []model.Placeholder {
+	var list []model.Placeholder
+	for _, c := range r.calls {
+		if c.Status != "REFUND_PARTIALLY" || c.Caller != nil {
+			continue
+		}
+		if !createdBefore.IsZero() && !c.CreatedAt.Before(createdBefore) {
+			continue
+		}
+		list = append(list, model.Placeholder{CallID: c.CallID, CreatedAt: c.CreatedAt})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// pendingReversal es model.PendingReversal sobre las filas sin aplicar.
+func pendingReversal(reversals []reversal, refund model.RefundCall) int {
+	for i, v := range reversals {
+		if v.appliedTo == "" && v.Matches(refund) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *CallRepository) record(e model.CallEvent) {
+	e.At = r.now()
+	r.events[e.CallID] = append(r.events[e.CallID], e)
+}
+
+// SELECT ... FROM call_events WHERE call_id = $1 ORDER BY id
+func (r *CallRepository) CallHistory(callID string) ([]model.CallEvent, error) {
+	if err := validateCallID(callID); err != nil {
+		return nil, fmt.Errorf("error leyendo historial: %w", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]model.CallEvent(nil), r.events[callID]...), nil
+}
+
+func (r *CallRepository) GetCallStatus(callID string) (string, error) {
+	if err := validateCallID(callID); err != nil {
+		return "", err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.calls[callID]
+	if !ok {
+		return "", nil
+	}
+	return c.Status, nil
+}
+
+// SELECT caller, receiver, duration_in_seconds, start_timestamp WHERE call_id = $1
+func (r *CallRepository) GetCall(callID string) (*model.NewIncomingCall, error) {
+	if err := validateCallID(callID); err != nil {
+		return nil, fmt.Errorf("error leyendo llamada: %w", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.calls[callID]
+	if !ok {
+		return nil, nil
+	}
+	call := &model.NewIncomingCall{CallID: c.CallID, Numbering: c.Numbering}
+	if c.Caller != nil {
+		call.Caller = *c.Caller
+	}
+	if c.Receiver != nil {
+		call.Receiver = *c.Receiver
+	}
+	if c.DurationInSec != nil {
+		call.DurationInSec = *c.DurationInSec
+	}
+	if c.StartTimestamp != nil {
+		call.StartTimestamp = *c.StartTimestamp
+	}
+	return call, nil
+}
+
+// UPDATE ... SET status = REFUNDED o PENDING WHERE call_id = $9 AND status = 'REFUND_PARTIALLY'
+func (r *CallRepository) FillMissingCallData(call model.NewIncomingCall) error {
+	if err := validateCallID(call.CallID); err != nil {
+		return err
+	}
+	ts := call.StartTimestamp.UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.calls[call.CallID]
+	if !ok || c.Status != "REFUND_PARTIALLY" {
+		return nil
+	}
+	c.Caller = strPtr(call.Caller)
+	c.Receiver = strPtr(call.Receiver)
+	c.Numbering = call.Numbering
+	c.DurationInSec = intPtr(call.DurationInSec)
+	c.StartTimestamp = &ts
+	c.Status = model.FilledStatus(r.refunds[call.CallID])
+	c.PriorStatus = "PENDING"
+	return nil
+}
+
+// UPDATE ... SET prior_status = 'INVALID', status = CASE WHEN status = 'REFUNDED' ... WHERE call_id = $1
+func (r *CallRepository) MarkCallAsInvalid(callID string) error {
+	if err := validateCallID(callID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.calls[callID]
+	if !ok {
+		return nil
+	}
+	c.setStatus("INVALID")
+	c.ProcessedAt = r.now()
+	return nil
+}
+
+// SELECT ... LEFT JOIN call_credits LEFT JOIN cost_adjustments WHERE start_timestamp >= $1 AND start_timestamp < $2 AND status IN ('OK', 'REFUNDED')
+func (r *CallRepository) BilledCalls(from, to time.Time) ([]model.BilledCall, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var calls []model.BilledCall
+	for _, c := range r.calls {
+		if c.StartTimestamp == nil || c.StartTimestamp.Before(from) || !c.StartTimestamp.Before(to) {
+			continue
+		}
+		if c.Status != "OK" && c.Status != "REFUNDED" {
+			continue
+		}
+		b := model.BilledCall{CallID: c.CallID, StartTimestamp: *c.StartTimestamp, Status: c.Status, BaseCost: c.BaseCost, Breakdown: c.Breakdown, Numbering: c.Numbering}
+		if c.Caller != nil {
+			b.Caller = *c.Caller
+		}
+		if c.Cost != nil {
+			b.Cost = *c.Cost
+		}
+		if c.Provider != nil {
+			b.Provider = *c.Provider
+		}
+		if credit, ok := r.credits[c.CallID]; ok {
+			b.Credit = &credit
+		}
+		b.Refunds = append([]model.RefundCall(nil), r.refunds[c.CallID]...)
+		if adj, ok := r.adjustments[c.CallID]; ok {
+			b.Adjustment = &adj
+		}
+		calls = append(calls, b)
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].StartTimestamp.Before(calls[j].StartTimestamp) })
+	return calls, nil
+}
+
+// INSERT INTO call_credits ... ON CONFLICT (call_id) DO UPDATE ... WHERE percent < EXCLUDED.percent
+func (r *CallRepository) SaveQualityCredit(credit model.QualityCredit) (bool, error) {
+	if err := validateCallID(credit.CallID); err != nil {
+		return false, fmt.Errorf("error guardando crédito por calidad: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.credits[credit.CallID]; ok && existing.Percent >= credit.Percent {
+		return false, nil
+	}
+	r.credits[credit.CallID] = credit
+	return true, nil
+}
+
+// INSERT INTO shadow_costs ... ON CONFLICT (call_id) DO UPDATE
+func (r *CallRepository) SaveShadowCost(callID string, shadow model.ShadowCost) error {
+	if shadow.Cost != nil {
+		if err := shadow.Cost.CheckPrecision(repository.CostPrecision, repository.CostScale); err != nil {
+			return fmt.Errorf("error guardando costo en sombra: %w", err)
+		}
+	}
+	if err := validateCallID(callID); err != nil {
+		return fmt.Errorf("error guardando costo en sombra: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shadows[callID] = shadow
+	return nil
+}
+
+// SELECT ... FROM shadow_costs JOIN calls WHERE start_timestamp >= $1 AND start_timestamp < $2
+func (r *CallRepository) ShadowComparisons(from, to time.Time) ([]model.ShadowComparison, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var comparisons []model.ShadowComparison
+	for id, shadow := range r.shadows {
+		c, ok := r.calls[id]
+		if !ok || c.StartTimestamp == nil || c.StartTimestamp.Before(from) || !c.StartTimestamp.Before(to) {
+			continue
+		}
+		cmp := model.ShadowComparison{CallID: id, StartTimestamp: *c.StartTimestamp, Status: c.Status, Shadow: shadow}
+		if c.Receiver != nil {
+			cmp.Receiver = *c.Receiver
+		}
+		if c.Status == "OK" && c.Cost != nil {
+			cost := *c.Cost
+			cmp.Cost = &cost
+		}
+		comparisons = append(comparisons, cmp)
+	}
+	sort.Slice(comparisons, func(i, j int) bool {
+		return comparisons[i].StartTimestamp.Before(comparisons[j].StartTimestamp)
+	})
+	return comparisons, nil
+}
+
+// La columna call_id es UUID: Postgres rechaza cualquier otro valor.
+func validateCallID(callID string) error {
+	if _, err := uuid.Parse(callID); err != nil {
+		return fmt.Errorf("invalid input syntax for type uuid: %q", callID)
+	}
+	return nil
+}
+
+func strPtr(s string) *string { return &s }
+func intPtr(i int) *int       { return &i }
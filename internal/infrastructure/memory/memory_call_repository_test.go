@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+	"phonecall-cost-processor-service/internal/domain/port/repository/repositorytest"
+
+	"github.com/google/uuid"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.CallRepository {
+		return NewCallRepository()
+	})
+}
+
+func TestApplyRefund_KeepsOriginalCost(t *testing.T) {
+	repo := NewCallRepository()
+	id := uuid.NewString()
+	_ = repo.SaveIncomingCall(model.NewIncomingCall{CallID: id, Caller: "a", Receiver: "b", DurationInSec: 1, StartTimestamp: time.Now()})
+	_ = repo.UpdateCallCost(id, model.CostResult{Cost: model.MustParseMoney("8.50", "ARS")})
+
+	if _, err := repo.ApplyRefund(model.RefundCall{CallID: id, Reason: "reclamo"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c, _ := repo.Find(id)
+	if *c.Cost != model.MustParseMoney("8.50", "ARS") || c.Status != "REFUNDED" {
+		t.Errorf("expected REFUNDED with 8.50 ARS, got %s %v", c.Status, c.Cost)
+	}
+}
+
+func TestApplyRefund_BeforeCall_LeavesCallerEmpty(t *testing.T) {
+	repo := NewCallRepository()
+	id := uuid.NewString()
+
+	_, _ = repo.ApplyRefund(model.RefundCall{CallID: id, Reason: "anticipado"})
+
+	c, ok := repo.Find(id)
+	if !ok || c.Caller != nil || c.Cost.Amount != 0 || !c.Refunded {
+		t.Errorf("unexpected placeholder: %+v", c)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	repo := NewCallRepository()
+	id := uuid.NewString()
+	call := model.NewIncomingCall{CallID: id, Caller: "a", Receiver: "b", DurationInSec: 1, StartTimestamp: time.Now()}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(3)
+		go func() { defer wg.Done(); _ = repo.SaveIncomingCall(call) }()
+		go func() {
+			defer wg.Done()
+			_ = repo.UpdateCallCost(id, model.CostResult{Cost: model.NewMoney(100, "USD")})
+		}()
+		go func() { defer wg.Done(); _, _ = repo.GetCallStatus(id) }()
+	}
+	wg.Wait()
+
+	if status, _ := repo.GetCallStatus(id); status != "OK" && status != "PENDING" {
+		t.Errorf("unexpected status %s", status)
+	}
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type FXRateRepository struct {
+	mu    sync.RWMutex
+	rates map[[2]string][]model.FXRate
+}
+
+var (
+	_ repository.FXRateReader = (*FXRateRepository)(nil)
+	_ repository.FXRateWriter = (*FXRateRepository)(nil)
+)
+
+func NewFXRateRepository() *FXRateRepository {
+	return &FXRateRepository{rates: make(map[[2]string][]model.FXRate)}
+}
+
+func (r *FXRateRepository) SaveRates(rates []model.FXRate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rate := range rates {
+		key := [2]string{rate.From, rate.To}
+		list := r.rates[key]
+		replaced := false
+		for i := range list {
+			if list[i].Date.Equal(rate.Date) {
+				list[i] = rate
+				replaced = true
+			}
+		}
+		if !replaced {
+			list = append(list, rate)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Date.Before(list[j].Date) })
+		r.rates[key] = list
+	}
+	return nil
+}
+
+func (r *FXRateRepository) GetRate(from, to string, on time.Time) (model.FXRate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	day := model.DateOnly(on)
+	list := r.rates[[2]string{from, to}]
+	for i := len(list) - 1; i >= 0; i-- {
+		if !list[i].Date.After(day) {
+			return list[i], nil
+		}
+	}
+	return model.FXRate{}, repository.ErrRateNotFound
+}
@@ -0,0 +1,139 @@
+package messaging
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	CloudEventsContentType = "application/cloudevents+json"
+)
+
+// Prefijos de headers aceptados en modo binario. El binding AMQP oficial usa
+// "cloudEvents:" pero algunos productores envían los atributos estilo HTTP.
+var cloudEventsHeaderPrefixes = []string{"cloudEvents:", "cloudEvents_", "ce-", "ce_"}
+
+// CloudEvent representa un evento en modo estructurado (spec 1.0).
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	DataBase64      string          `json:"data_base64,omitempty"`
+}
+
+// decodeMessage obtiene el tipo y el payload de un mensaje entrante, ya sea
+// CloudEvent binario (atributos en headers), CloudEvent estructurado o el
+// sobre legacy {type, body}.
+func decodeMessage(contentType string, headers map[string]interface{}, body []byte) (string, []byte, error) {
+	if ceType, ok := cloudEventsHeader(headers, "type"); ok {
+		return ceType, body, nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return "", nil, fmt.Errorf("error parseando mensaje: %w", err)
+	}
+
+	if _, ok := raw["specversion"]; ok || strings.HasPrefix(contentType, CloudEventsContentType) {
+		return decodeStructuredCloudEvent(body)
+	}
+
+	var msgType string
+	if err := json.Unmarshal(raw["type"], &msgType); err != nil {
+		return "", nil, fmt.Errorf("error leyendo tipo: %w", err)
+	}
+	return msgType, raw["body"], nil
+}
+
+// messageSource devuelve el atributo source de un CloudEvent binario o
+// estructurado; el sobre legacy no lo tiene.
+func messageSource(headers map[string]interface{}, body []byte) string {
+	if source, ok := cloudEventsHeader(headers, "source"); ok {
+		return source
+	}
+	var ce struct {
+		SpecVersion string `json:"specversion"`
+		Source      string `json:"source"`
+	}
+	if err := json.Unmarshal(body, &ce); err != nil || ce.SpecVersion == "" {
+		return ""
+	}
+	return ce.Source
+}
+
+func decodeStructuredCloudEvent(body []byte) (string, []byte, error) {
+	var ce CloudEvent
+	if err := json.Unmarshal(body, &ce); err != nil {
+		return "", nil, fmt.Errorf("error parseando CloudEvent: %w", err)
+	}
+	if ce.Type == "" {
+		return "", nil, errors.New("CloudEvent sin atributo type")
+	}
+	if ce.DataBase64 != "" {
+		data, err := base64.StdEncoding.DecodeString(ce.DataBase64)
+		if err != nil {
+			return "", nil, fmt.Errorf("data_base64 inválido: %w", err)
+		}
+		return ce.Type, data, nil
+	}
+	return ce.Type, ce.Data, nil
+}
+
+func cloudEventsHeader(headers map[string]interface{}, attr string) (string, bool) {
+	for _, prefix := range cloudEventsHeaderPrefixes {
+		if v, ok := headers[prefix+attr]; ok {
+			switch s := v.(type) {
+			case string:
+				return s, true
+			case []byte:
+				return string(s), true
+			}
+		}
+	}
+	return "", false
+}
+
+// resolveHandler busca el handler por tipo exacto y, si no existe, por el
+// último segmento de un tipo CloudEvents con namespace (p. ej.
+// "com.telco.calls.refund_call" -> "refund_call").
+func resolveHandler(handlers map[string]Handler, msgType string) (Handler, bool) {
+	if h, ok := handlers[msgType]; ok {
+		return h, true
+	}
+	if i := strings.LastIndex(msgType, "."); i >= 0 {
+		h, ok := handlers[msgType[i+1:]]
+		return h, ok
+	}
+	return nil, false
+}
+
+// NewCloudEvent serializa un CloudEvent estructurado con id, source y subject.
+func NewCloudEvent(source, eventType, callID string, data interface{}) ([]byte, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("error serializando data del evento: %w", err)
+	}
+
+	return json.Marshal(CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              uuid.New().String(),
+		Source:          source,
+		Type:            eventType,
+		Subject:         callID,
+		Time:            time.Now().UTC().Format(time.RFC3339),
+		DataContentType: "application/json",
+		Data:            payload,
+	})
+}
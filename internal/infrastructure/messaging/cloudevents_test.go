@@ -0,0 +1,101 @@
+package messaging
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type noopHandler struct{}
+
+func (noopHandler) Handle([]byte) error { return nil }
+
+func TestDecodeMessage_LegacyEnvelope(t *testing.T) {
+	body := []byte(`{"type":"refund_call","body":{"call_id":"abc","reason":"x"}}`)
+
+	msgType, payload, err := decodeMessage("application/json", nil, body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "refund_call", msgType)
+	assert.JSONEq(t, `{"call_id":"abc","reason":"x"}`, string(payload))
+}
+
+func TestDecodeMessage_StructuredCloudEvent(t *testing.T) {
+	body := []byte(`{
+		"specversion":"1.0",
+		"id":"1",
+		"source":"/carrier",
+		"type":"new_incoming_call",
+		"subject":"abc",
+		"data":{"call_id":"abc"}
+	}`)
+
+	msgType, payload, err := decodeMessage(CloudEventsContentType, nil, body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "new_incoming_call", msgType)
+	assert.JSONEq(t, `{"call_id":"abc"}`, string(payload))
+}
+
+func TestDecodeMessage_StructuredCloudEventBase64(t *testing.T) {
+	body := []byte(`{"specversion":"1.0","id":"1","source":"/s","type":"refund_call","data_base64":"eyJjYWxsX2lkIjoiYWJjIn0="}`)
+
+	msgType, payload, err := decodeMessage("", nil, body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "refund_call", msgType)
+	assert.JSONEq(t, `{"call_id":"abc"}`, string(payload))
+}
+
+func TestDecodeMessage_StructuredCloudEventWithoutType(t *testing.T) {
+	body := []byte(`{"specversion":"1.0","id":"1","source":"/s","data":{}}`)
+
+	_, _, err := decodeMessage(CloudEventsContentType, nil, body)
+
+	assert.Error(t, err)
+}
+
+func TestDecodeMessage_BinaryCloudEvent(t *testing.T) {
+	for _, header := range []string{"cloudEvents:type", "cloudEvents_type", "ce-type", "ce_type"} {
+		headers := map[string]interface{}{header: "refund_call", "cloudEvents:id": "1"}
+		body := []byte(`{"call_id":"abc"}`)
+
+		msgType, payload, err := decodeMessage("application/json", headers, body)
+
+		assert.NoError(t, err, header)
+		assert.Equal(t, "refund_call", msgType, header)
+		assert.Equal(t, body, payload, header)
+	}
+}
+
+func TestDecodeMessage_InvalidJSON(t *testing.T) {
+	_, _, err := decodeMessage("application/json", nil, []byte("not-json"))
+	assert.Error(t, err)
+}
+
+func TestResolveHandler_NamespacedType(t *testing.T) {
+	handlers := map[string]Handler{"refund_call": noopHandler{}}
+
+	_, ok := resolveHandler(handlers, "com.telco.calls.refund_call")
+	assert.True(t, ok)
+
+	_, ok = resolveHandler(handlers, "refund_call")
+	assert.True(t, ok)
+
+	_, ok = resolveHandler(handlers, "com.telco.calls.unknown")
+	assert.False(t, ok)
+}
+
+func TestNewCloudEvent_SetsAttributes(t *testing.T) {
+	body, err := NewCloudEvent("/phonecall-cost-processor", "refund_call", "abc", map[string]string{"call_id": "abc"})
+	assert.NoError(t, err)
+
+	var ce CloudEvent
+	assert.NoError(t, json.Unmarshal(body, &ce))
+	assert.Equal(t, CloudEventsSpecVersion, ce.SpecVersion)
+	assert.NotEmpty(t, ce.ID)
+	assert.Equal(t, "/phonecall-cost-processor", ce.Source)
+	assert.Equal(t, "abc", ce.Subject)
+	assert.JSONEq(t, `{"call_id":"abc"}`, string(ce.Data))
+}
@@ -0,0 +1,174 @@
+package messaging
+
+import (
+	"log"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/messaging"
+)
+
+type Handler interface {
+	Handle([]byte) error
+}
+
+// SourceHandler lo implementan los handlers que leen el mensaje según quién
+// lo produjo (p. ej. la zona horaria de sus timestamps). source es el
+// atributo source del CloudEvent, vacío en el sobre legacy.
+type SourceHandler interface {
+	HandleFrom(source string, body []byte) error
+}
+
+// Dispatcher decodifica cada delivery, lo enruta al handler de su tipo y lo
+// confirma o rechaza según el resultado: los errores permanentes (payload
+// inválido, tipo desconocido) se descartan y el resto se reintenta según la
+// RetryPolicy.
+type Dispatcher struct {
+	handlers map[string]Handler
+	metrics  *Metrics
+	retries  RetryPolicy
+}
+
+// RetryPolicy limita los reintentos de un mensaje que falla con un error
+// reintentable: se reentrega después de Backoff, que se duplica en cada
+// intento hasta MaxBackoff, y después de MaxAttempts entregas va a dead
+// letter. Solo aplica a transportes con messaging.RetryableDelivery.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 5, Backoff: time.Second, MaxBackoff: time.Minute}
+}
+
+// delay es la espera antes de la entrega siguiente a attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+type DispatcherOption func(*Dispatcher)
+
+func WithMetrics(m *Metrics) DispatcherOption {
+	return func(d *Dispatcher) { d.metrics = m }
+}
+
+func WithRetryPolicy(p RetryPolicy) DispatcherOption {
+	return func(d *Dispatcher) { d.retries = p }
+}
+
+func NewDispatcher(handlers map[string]Handler, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{handlers: handlers, metrics: NewMetrics(), retries: DefaultRetryPolicy()}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Run consume la fuente hasta que su canal se cierra.
+func (d *Dispatcher) Run(source messaging.MessageSource) error {
+	deliveries, err := source.Deliveries()
+	if err != nil {
+		return err
+	}
+
+	for delivery := range deliveries {
+		d.Dispatch(delivery)
+	}
+	return nil
+}
+
+func (d *Dispatcher) Dispatch(delivery messaging.Delivery) {
+	msgType, body, err := decodeMessage(delivery.ContentType(), delivery.Headers(), delivery.Body())
+	if err != nil {
+		log.Printf("❌ %v\n", err)
+		d.reject(delivery, "undecodable", err)
+		return
+	}
+
+	handler, ok := resolveHandler(d.handlers, msgType)
+	if !ok {
+		log.Printf("⚠️ Tipo de mensaje desconocido: %s\n", msgType)
+		d.reject(delivery, "unknown", nil)
+		return
+	}
+
+	source := ""
+	if _, ok := handler.(SourceHandler); ok {
+		source = messageSource(delivery.Headers(), delivery.Body())
+	}
+	if err := handle(handler, source, body); err != nil {
+		if model.IsPermanent(err) {
+			log.Printf("🚫 Mensaje tipo %s descartado: %v\n", msgType, err)
+			d.reject(delivery, msgType, err)
+			return
+		}
+		d.retry(delivery, msgType, err)
+		return
+	}
+
+	d.metrics.record(msgType, OutcomeAcked, nil)
+	if err := delivery.Ack(); err != nil {
+		log.Printf("⚠️ Error confirmando mensaje: %v\n", err)
+	}
+}
+
+func handle(h Handler, source string, body []byte) error {
+	if sh, ok := h.(SourceHandler); ok {
+		return sh.HandleFrom(source, body)
+	}
+	return h.Handle(body)
+}
+
+// retry reentrega el mensaje con backoff o, agotados los intentos, lo manda a
+// dead letter. Si el transporte no sabe reintentar, o falla al hacerlo, se
+// reencola con Nack.
+func (d *Dispatcher) retry(delivery messaging.Delivery, msgType string, cause error) {
+	r, ok := delivery.(messaging.RetryableDelivery)
+	if !ok {
+		log.Printf("❌ Error procesando mensaje tipo %s, se reencola: %v\n", msgType, cause)
+		d.metrics.record(msgType, OutcomeRequeued, cause)
+		d.requeue(delivery)
+		return
+	}
+
+	attempt := r.Attempt()
+	if attempt >= d.retries.MaxAttempts {
+		log.Printf("☠️ Mensaje tipo %s enviado a dead letter después de %d intentos: %v\n", msgType, attempt, cause)
+		d.metrics.record(msgType, OutcomeDeadLettered, cause)
+		if err := r.DeadLetter(cause.Error()); err != nil {
+			log.Printf("⚠️ Error enviando mensaje a dead letter: %v\n", err)
+			d.requeue(delivery)
+		}
+		return
+	}
+
+	delay := d.retries.delay(attempt)
+	log.Printf("❌ Error procesando mensaje tipo %s (intento %d de %d), se reintenta en %s: %v\n", msgType, attempt, d.retries.MaxAttempts, delay, cause)
+	d.metrics.record(msgType, OutcomeRequeued, cause)
+	if err := r.Retry(delay); err != nil {
+		log.Printf("⚠️ Error reprogramando mensaje: %v\n", err)
+		d.requeue(delivery)
+	}
+}
+
+func (d *Dispatcher) requeue(delivery messaging.Delivery) {
+	if err := delivery.Nack(true); err != nil {
+		log.Printf("⚠️ Error reencolando mensaje: %v\n", err)
+	}
+}
+
+func (d *Dispatcher) reject(delivery messaging.Delivery, msgType string, err error) {
+	d.metrics.record(msgType, OutcomeRejected, err)
+	if err := delivery.Nack(false); err != nil {
+		log.Printf("⚠️ Error rechazando mensaje: %v\n", err)
+	}
+}
@@ -0,0 +1,122 @@
+package messaging_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/infrastructure/messaging"
+	"phonecall-cost-processor-service/internal/infrastructure/messaging/memory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingHandler struct {
+	bodies [][]byte
+	err    error
+}
+
+func (h *recordingHandler) Handle(body []byte) error {
+	h.bodies = append(h.bodies, body)
+	return h.err
+}
+
+func TestDispatcher_Run_AcksHandledMessages(t *testing.T) {
+	refund := &recordingHandler{}
+	src := memory.NewSource(2)
+	src.Publish([]byte(`{"type":"refund_call","body":{"call_id":"a"}}`), "application/json", nil)
+	src.Publish([]byte(`{"call_id":"b"}`), "application/json", map[string]interface{}{"cloudEvents:type": "refund_call"})
+	src.Close()
+
+	err := messaging.NewDispatcher(map[string]messaging.Handler{"refund_call": refund}).Run(src)
+
+	assert.NoError(t, err)
+	assert.Len(t, refund.bodies, 2)
+	assert.Len(t, src.Acked(), 2)
+	assert.Empty(t, src.Nacked())
+}
+
+type sourceHandler struct {
+	recordingHandler
+	sources []string
+}
+
+func (h *sourceHandler) HandleFrom(source string, body []byte) error {
+	h.sources = append(h.sources, source)
+	return h.Handle(body)
+}
+
+func TestDispatcher_Run_PassesCloudEventsSource(t *testing.T) {
+	incoming := &sourceHandler{}
+	src := memory.NewSource(3)
+	src.Publish([]byte(`{"specversion":"1.0","id":"1","source":"/carrier-a","type":"new_incoming_call","data":{"call_id":"a"}}`), "application/json", nil)
+	src.Publish([]byte(`{"call_id":"b"}`), "application/json", map[string]interface{}{"cloudEvents:type": "new_incoming_call", "cloudEvents:source": "/carrier-b"})
+	src.Publish([]byte(`{"type":"new_incoming_call","body":{"call_id":"c"}}`), "application/json", nil)
+	src.Close()
+
+	err := messaging.NewDispatcher(map[string]messaging.Handler{"new_incoming_call": incoming}).Run(src)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/carrier-a", "/carrier-b", ""}, incoming.sources)
+	assert.Len(t, src.Acked(), 3)
+}
+
+func TestDispatcher_Run_NacksFailures(t *testing.T) {
+	failing := &recordingHandler{err: errors.New("boom")}
+	src := memory.NewSource(3)
+	src.Publish([]byte(`{"type":"refund_call","body":{}}`), "application/json", nil)
+	src.Publish([]byte(`{"type":"unknown","body":{}}`), "application/json", nil)
+	src.Publish([]byte(`not-json`), "application/json", nil)
+	src.Close()
+
+	err := messaging.NewDispatcher(map[string]messaging.Handler{"refund_call": failing}).Run(src)
+
+	assert.NoError(t, err)
+	assert.Empty(t, src.Acked())
+	assert.Len(t, src.Nacked(), 2, "tipo desconocido y payload ilegible se descartan")
+	assert.Len(t, src.Requeued(), 1, "un error del handler no permanente se reintenta")
+}
+
+func TestDispatcher_Run_RejectsPermanentErrorsAndCountsThem(t *testing.T) {
+	invalid := &recordingHandler{err: &model.ValidationError{Entity: "refund_call", Fields: []model.FieldError{{Field: "call_id", Message: "debe ser un UUID"}}}}
+	ok := &recordingHandler{}
+	src := memory.NewSource(3)
+	src.Publish([]byte(`{"type":"refund_call","body":{"call_id":"x"}}`), "application/json", nil)
+	src.Publish([]byte(`{"type":"refund_call","body":{"call_id":"y"}}`), "application/json", nil)
+	src.Publish([]byte(`{"type":"new_incoming_call","body":{}}`), "application/json", nil)
+	src.Close()
+
+	metrics := messaging.NewMetrics()
+	err := messaging.NewDispatcher(map[string]messaging.Handler{"refund_call": invalid, "new_incoming_call": ok}, messaging.WithMetrics(metrics)).Run(src)
+
+	assert.NoError(t, err)
+	assert.Len(t, src.Nacked(), 2)
+	assert.Empty(t, src.Requeued())
+	assert.Equal(t, int64(2), metrics.Count("refund_call", messaging.OutcomeRejected))
+	assert.Equal(t, int64(1), metrics.Count("new_incoming_call", messaging.OutcomeAcked))
+	assert.Equal(t, int64(2), metrics.ValidationCount("refund_call", "call_id"))
+}
+
+func TestDispatcher_Run_RetriesWithBackoffAndDeadLetters(t *testing.T) {
+	failing := &recordingHandler{err: errors.New("db down")}
+	src := memory.NewSource(3)
+	src.Publish([]byte(`{"type":"refund_call","body":{"call_id":"a"}}`), "application/json", nil)
+	src.Publish([]byte(`{"type":"refund_call","body":{"call_id":"b"}}`), "application/json", map[string]interface{}{"x-retry-count": 2})
+	src.Publish([]byte(`{"type":"refund_call","body":{"call_id":"c"}}`), "application/json", map[string]interface{}{"x-retry-count": 3})
+	src.Close()
+
+	metrics := messaging.NewMetrics()
+	policy := messaging.RetryPolicy{MaxAttempts: 4, Backoff: time.Second, MaxBackoff: 3 * time.Second}
+	err := messaging.NewDispatcher(map[string]messaging.Handler{"refund_call": failing},
+		messaging.WithMetrics(metrics), messaging.WithRetryPolicy(policy)).Run(src)
+
+	assert.NoError(t, err)
+	assert.Len(t, src.Requeued(), 2)
+	assert.Equal(t, []time.Duration{time.Second, 3 * time.Second}, src.RetryDelays(), "el backoff se duplica hasta MaxBackoff")
+	if assert.Len(t, src.DeadLettered(), 1) {
+		assert.Contains(t, string(src.DeadLettered()[0]), `"c"`)
+	}
+	assert.Equal(t, int64(2), metrics.Count("refund_call", messaging.OutcomeRequeued))
+	assert.Equal(t, int64(1), metrics.Count("refund_call", messaging.OutcomeDeadLettered))
+}
@@ -0,0 +1,175 @@
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+
+	"github.com/google/uuid"
+)
+
+// incomingCallType es el mensaje que crea la llamada y libera los eventos
+// estacionados.
+const incomingCallType = "new_incoming_call"
+
+// Validator lo implementan los handlers que saben validar un mensaje sin
+// aplicarlo. Los mensajes inválidos se rechazan al llegar en vez de
+// estacionarse y descartarse recién al llegar la llamada.
+type Validator interface {
+	Validate([]byte) error
+}
+
+// WithEarlyEvents hace que los mensajes de una llamada cuyo new_incoming_call
+// todavía no llegó se estacionen en store y se reprocesen, en orden de
+// llegada, cuando ese new_incoming_call se procesa bien. Aplica a todos los
+// tipos salvo new_incoming_call y los de except (p. ej. refund_call, que
+// tiene su propio placeholder). Los mensajes sin call_id válido pasan directo
+// a su handler.
+func WithEarlyEvents(store repository.PendingEventRepository, except ...string) DispatcherOption {
+	return func(d *Dispatcher) {
+		inner := d.handlers
+		// El dispatcher puede recibir WithMetrics después de esta opción
+		events := &EventReleaser{handlers: inner, store: store, metrics: func() *Metrics { return d.metrics }}
+		wrapped := make(map[string]Handler, len(inner))
+		for msgType, h := range inner {
+			switch {
+			case msgType == incomingCallType:
+				wrapped[msgType] = &releasingHandler{next: h, events: events}
+			case contains(except, msgType):
+				wrapped[msgType] = h
+			default:
+				wrapped[msgType] = &parkingHandler{msgType: msgType, next: h, store: store}
+			}
+		}
+		d.handlers = wrapped
+	}
+}
+
+type parkingHandler struct {
+	msgType string
+	next    Handler
+	store   repository.PendingEventRepository
+}
+
+func (h *parkingHandler) Handle(body []byte) error {
+	callID := callIDOf(body)
+	if callID == "" {
+		return h.next.Handle(body)
+	}
+	if v, ok := h.next.(Validator); ok {
+		if err := v.Validate(body); err != nil {
+			return err
+		}
+	}
+	parked, err := h.store.ParkEvent(model.PendingEvent{CallID: callID, Type: h.msgType, Body: body})
+	if err != nil {
+		return err
+	}
+	if !parked {
+		return h.next.Handle(body)
+	}
+	log.Printf("🅿️ %s call_id=%s estacionado hasta que llegue la llamada", h.msgType, callID)
+	return nil
+}
+
+type releasingHandler struct {
+	next   Handler
+	events *EventReleaser
+}
+
+// Handle procesa la llamada y después sus eventos estacionados. Si uno falla
+// con un error reintentable se devuelve el error: el new_incoming_call se
+// reencola y, al reprocesarse, retoma desde ese evento.
+func (h *releasingHandler) Handle(body []byte) error {
+	return h.HandleFrom("", body)
+}
+
+func (h *releasingHandler) HandleFrom(source string, body []byte) error {
+	if err := handle(h.next, source, body); err != nil {
+		return err
+	}
+	callID := callIDOf(body)
+	if callID == "" {
+		return nil
+	}
+	return h.events.Release(callID)
+}
+
+// EventReleaser reprocesa los eventos estacionados de una llamada. Lo usa el
+// dispatcher después de cada new_incoming_call y cualquier camino que cree
+// llamadas sin pasar por él (cmd/import): si no, los eventos quedan
+// estacionados y ParkEvent estaciona también los siguientes.
+type EventReleaser struct {
+	// handlers son los handlers sin envolver: reprocesar un evento no lo
+	// vuelve a estacionar.
+	handlers map[string]Handler
+	store    repository.PendingEventRepository
+	metrics  func() *Metrics
+}
+
+// NewEventReleaser cuenta los eventos descartados en metrics como rejected
+// de su tipo, igual que el dispatcher.
+func NewEventReleaser(handlers map[string]Handler, store repository.PendingEventRepository, metrics *Metrics) *EventReleaser {
+	return &EventReleaser{handlers: handlers, store: store, metrics: func() *Metrics { return metrics }}
+}
+
+// Release relee la cola hasta vaciarla, así también se aplican los eventos
+// estacionados mientras se reprocesaban los anteriores. Un evento que falla
+// con un error permanente se descarta; con uno reintentable se devuelve el
+// error y el evento queda estacionado.
+func (r *EventReleaser) Release(callID string) error {
+	for {
+		events, err := r.store.PendingEvents(callID)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		for _, e := range events {
+			err := r.replay(e)
+			if err != nil && !model.IsPermanent(err) {
+				return fmt.Errorf("error reprocesando %s estacionado de call_id=%s: %w", e.Type, callID, err)
+			}
+			if err != nil {
+				log.Printf("🚫 Mensaje tipo %s estacionado de call_id=%s descartado: %v", e.Type, callID, err)
+				r.metrics().record(e.Type, OutcomeRejected, err)
+			} else {
+				log.Printf("▶️ %s estacionado de call_id=%s reprocesado", e.Type, callID)
+			}
+			if err := r.store.DeletePendingEvent(callID, e.Seq); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (r *EventReleaser) replay(e model.PendingEvent) error {
+	next, ok := r.handlers[e.Type]
+	if !ok {
+		return model.Permanent(fmt.Errorf("tipo de mensaje sin handler: %s", e.Type))
+	}
+	return next.Handle(e.Body)
+}
+
+func callIDOf(body []byte) string {
+	var ids struct {
+		CallID string `json:"call_id"`
+	}
+	if err := json.Unmarshal(body, &ids); err != nil {
+		return ""
+	}
+	if _, err := uuid.Parse(ids.CallID); err != nil {
+		return ""
+	}
+	return ids.CallID
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package messaging_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	callmemory "phonecall-cost-processor-service/internal/infrastructure/memory"
+	"phonecall-cost-processor-service/internal/infrastructure/messaging"
+	"phonecall-cost-processor-service/internal/infrastructure/messaging/memory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const earlyCallID = "550e8400-e29b-41d4-a716-446655440000"
+
+// savingHandler guarda la llamada como lo haría el IncomingCallHandler.
+type savingHandler struct {
+	repo *callmemory.CallRepository
+}
+
+func (h *savingHandler) Handle(body []byte) error {
+	var call model.NewIncomingCall
+	if err := json.Unmarshal(body, &call); err != nil {
+		return model.Permanent(err)
+	}
+	call.Caller, call.Receiver, call.DurationInSec = "+5491111111111", "+5491122222222", 60
+	call.StartTimestamp = time.Now().UTC()
+	return h.repo.SaveIncomingCall(call)
+}
+
+func publishAll(src *memory.Source, msgs ...string) {
+	for _, m := range msgs {
+		src.Publish([]byte(m), "application/json", nil)
+	}
+}
+
+func TestWithEarlyEvents_ParksUntilTheCallArrives(t *testing.T) {
+	repo := callmemory.NewCallRepository()
+	quality, adjusted, refund := &recordingHandler{}, &recordingHandler{}, &recordingHandler{}
+	src := memory.NewSource(8)
+	publishAll(src,
+		`{"type":"call_quality_issue","body":{"call_id":"`+earlyCallID+`","severity":"high"}}`,
+		`{"type":"call_cost_adjusted","body":{"call_id":"`+earlyCallID+`","cost":"1.00"}}`,
+		`{"type":"call_quality_issue","body":{"call_id":"`+earlyCallID+`","severity":"high"}}`,
+		`{"type":"refund_call","body":{"call_id":"`+earlyCallID+`"}}`,
+		`{"type":"call_quality_issue","body":{"call_id":"sin-uuid","severity":"low"}}`,
+		`{"type":"new_incoming_call","body":{"call_id":"`+earlyCallID+`"}}`,
+		`{"type":"call_quality_issue","body":{"call_id":"`+earlyCallID+`","severity":"critical"}}`,
+	)
+	src.Close()
+
+	err := messaging.NewDispatcher(map[string]messaging.Handler{
+		"new_incoming_call":  &savingHandler{repo: repo},
+		"call_quality_issue": quality,
+		"call_cost_adjusted": adjusted,
+		"refund_call":        refund,
+	}, messaging.WithEarlyEvents(repo, "refund_call")).Run(src)
+
+	assert.NoError(t, err)
+	assert.Len(t, src.Acked(), 7)
+	assert.Len(t, refund.bodies, 1, "refund_call no se estaciona")
+	if assert.Len(t, quality.bodies, 3, "la reentrega estacionada se aplica una vez") {
+		assert.Contains(t, string(quality.bodies[0]), "sin-uuid", "sin call_id válido pasa directo")
+		assert.Contains(t, string(quality.bodies[1]), `"high"`)
+		assert.Contains(t, string(quality.bodies[2]), `"critical"`, "después de la llamada se procesa directo")
+	}
+	assert.Len(t, adjusted.bodies, 1)
+
+	pending, err := repo.PendingEvents(earlyCallID)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestWithEarlyEvents_ResumesAfterReplayError(t *testing.T) {
+	repo := callmemory.NewCallRepository()
+	quality, adjusted := &recordingHandler{}, &recordingHandler{err: errors.New("db down")}
+	handlers := map[string]messaging.Handler{
+		"new_incoming_call":  &savingHandler{repo: repo},
+		"call_quality_issue": quality,
+		"call_cost_adjusted": adjusted,
+	}
+
+	src := memory.NewSource(4)
+	publishAll(src,
+		`{"type":"call_quality_issue","body":{"call_id":"`+earlyCallID+`","severity":"high"}}`,
+		`{"type":"call_cost_adjusted","body":{"call_id":"`+earlyCallID+`","cost":"1.00"}}`,
+		`{"type":"new_incoming_call","body":{"call_id":"`+earlyCallID+`"}}`,
+		`{"type":"call_quality_issue","body":{"call_id":"`+earlyCallID+`","severity":"critical"}}`,
+	)
+	src.Close()
+	assert.NoError(t, messaging.NewDispatcher(handlers, messaging.WithEarlyEvents(repo)).Run(src))
+
+	assert.Len(t, src.Requeued(), 1, "el new_incoming_call se reintenta")
+	assert.Len(t, quality.bodies, 1, "el evento posterior espera a los estacionados antes que él")
+	pending, _ := repo.PendingEvents(earlyCallID)
+	assert.Len(t, pending, 2)
+
+	// Reentrega del new_incoming_call: retoma desde el evento que falló
+	adjusted.err = nil
+	src = memory.NewSource(1)
+	publishAll(src, `{"type":"new_incoming_call","body":{"call_id":"`+earlyCallID+`"}}`)
+	src.Close()
+	assert.NoError(t, messaging.NewDispatcher(handlers, messaging.WithEarlyEvents(repo)).Run(src))
+
+	assert.Len(t, src.Acked(), 1)
+	assert.Len(t, adjusted.bodies, 2)
+	if assert.Len(t, quality.bodies, 2) {
+		assert.Contains(t, string(quality.bodies[1]), `"critical"`)
+	}
+	pending, _ = repo.PendingEvents(earlyCallID)
+	assert.Empty(t, pending)
+}
+
+// validatingHandler rechaza en Validate los problemas de calidad sin severity.
+type validatingHandler struct {
+	recordingHandler
+}
+
+func (h *validatingHandler) Validate(body []byte) error {
+	var issue struct {
+		Severity string `json:"severity"`
+	}
+	if err := json.Unmarshal(body, &issue); err != nil || issue.Severity == "" {
+		return &model.ValidationError{Entity: "call_quality_issue", Fields: []model.FieldError{{Field: "severity", Message: "es obligatorio"}}}
+	}
+	return nil
+}
+
+func TestWithEarlyEvents_ValidatesBeforeParking(t *testing.T) {
+	repo := callmemory.NewCallRepository()
+	quality := &validatingHandler{}
+	metrics := messaging.NewMetrics()
+	src := memory.NewSource(2)
+	publishAll(src,
+		`{"type":"call_quality_issue","body":{"call_id":"`+earlyCallID+`"}}`,
+		`{"type":"call_quality_issue","body":{"call_id":"`+earlyCallID+`","severity":"high"}}`,
+	)
+	src.Close()
+
+	err := messaging.NewDispatcher(map[string]messaging.Handler{
+		"new_incoming_call":  &savingHandler{repo: repo},
+		"call_quality_issue": quality,
+	}, messaging.WithEarlyEvents(repo), messaging.WithMetrics(metrics)).Run(src)
+
+	assert.NoError(t, err)
+	assert.Len(t, src.Nacked(), 1, "el mensaje inválido se rechaza sin estacionarse")
+	assert.Equal(t, int64(1), metrics.Count("call_quality_issue", messaging.OutcomeRejected))
+	assert.Equal(t, int64(1), metrics.ValidationCount("call_quality_issue", "severity"))
+	pending, _ := repo.PendingEvents(earlyCallID)
+	assert.Len(t, pending, 1)
+}
+
+func TestWithEarlyEvents_CountsDiscardedReplays(t *testing.T) {
+	repo := callmemory.NewCallRepository()
+	adjusted := &recordingHandler{err: &model.ValidationError{Entity: "call_cost_adjusted", Fields: []model.FieldError{{Field: "currency", Message: "la llamada tiene el refund r1 en ARS"}}}}
+	metrics := messaging.NewMetrics()
+	src := memory.NewSource(2)
+	publishAll(src,
+		`{"type":"call_cost_adjusted","body":{"call_id":"`+earlyCallID+`","cost":"1.00"}}`,
+		`{"type":"new_incoming_call","body":{"call_id":"`+earlyCallID+`"}}`,
+	)
+	src.Close()
+
+	// WithMetrics después de WithEarlyEvents: el reprocesamiento usa las métricas finales
+	err := messaging.NewDispatcher(map[string]messaging.Handler{
+		"new_incoming_call":  &savingHandler{repo: repo},
+		"call_cost_adjusted": adjusted,
+	}, messaging.WithEarlyEvents(repo), messaging.WithMetrics(metrics)).Run(src)
+
+	assert.NoError(t, err)
+	assert.Len(t, src.Acked(), 2)
+	assert.Len(t, adjusted.bodies, 1)
+	assert.Equal(t, int64(1), metrics.Count("call_cost_adjusted", messaging.OutcomeRejected))
+	assert.Equal(t, int64(1), metrics.ValidationCount("call_cost_adjusted", "currency"))
+	pending, _ := repo.PendingEvents(earlyCallID)
+	assert.Empty(t, pending)
+}
+
+func TestEventReleaser_Release(t *testing.T) {
+	repo := callmemory.NewCallRepository()
+	quality := &recordingHandler{}
+	parked, err := repo.ParkEvent(model.PendingEvent{CallID: earlyCallID, Type: "call_quality_issue", Body: []byte(`{"call_id":"` + earlyCallID + `","severity":"high"}`)})
+	assert.NoError(t, err)
+	assert.True(t, parked)
+	// La llamada llega sin pasar por el dispatcher, p. ej. desde cmd/import
+	assert.NoError(t, (&savingHandler{repo: repo}).Handle([]byte(`{"call_id":"`+earlyCallID+`"}`)))
+
+	releaser := messaging.NewEventReleaser(map[string]messaging.Handler{"call_quality_issue": quality}, repo, messaging.NewMetrics())
+	assert.NoError(t, releaser.Release(earlyCallID))
+
+	assert.Len(t, quality.bodies, 1)
+	pending, _ := repo.PendingEvents(earlyCallID)
+	assert.Empty(t, pending)
+}
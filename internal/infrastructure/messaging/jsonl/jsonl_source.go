@@ -0,0 +1,86 @@
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log"
+	"os"
+
+	"phonecall-cost-processor-service/internal/domain/port/messaging"
+)
+
+// Source reproduce tráfico capturado: cada línea no vacía es el body de un
+// mensaje (sobre legacy o CloudEvent estructurado).
+type Source struct {
+	r      io.Reader
+	closer io.Closer
+	name   string
+}
+
+func NewSource(r io.Reader, name string) *Source {
+	return &Source{r: r, name: name}
+}
+
+// Open abre el archivo indicado o stdin si path es "-".
+func Open(path string) (*Source, error) {
+	if path == "-" {
+		return NewSource(os.Stdin, "stdin"), nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	s := NewSource(f, path)
+	s.closer = f
+	return s, nil
+}
+
+func (s *Source) Deliveries() (<-chan messaging.Delivery, error) {
+	out := make(chan messaging.Delivery)
+
+	go func() {
+		defer close(out)
+		scanner := bufio.NewScanner(s.r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+		line := 0
+		for scanner.Scan() {
+			line++
+			body := bytes.TrimSpace(scanner.Bytes())
+			if len(body) == 0 {
+				continue
+			}
+			out <- &delivery{body: append([]byte(nil), body...), source: s.name, line: line}
+		}
+		if err := scanner.Err(); err != nil {
+			log.Printf("❌ Error leyendo %s: %v", s.name, err)
+		}
+	}()
+
+	return out, nil
+}
+
+func (s *Source) Close() error {
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
+
+type delivery struct {
+	body   []byte
+	source string
+	line   int
+}
+
+func (d *delivery) Body() []byte                    { return d.body }
+func (d *delivery) ContentType() string             { return "application/json" }
+func (d *delivery) Headers() map[string]interface{} { return nil }
+func (d *delivery) Ack() error                      { return nil }
+
+// Nack no puede devolver el mensaje a un archivo: solo se informa la línea.
+func (d *delivery) Nack(requeue bool) error {
+	log.Printf("⚠️ Mensaje rechazado %s:%d", d.source, d.line)
+	return nil
+}
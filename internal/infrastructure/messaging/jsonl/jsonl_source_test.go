@@ -0,0 +1,30 @@
+package jsonl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSource_Deliveries_SkipsBlankLines(t *testing.T) {
+	input := `{"type":"new_incoming_call","body":{"call_id":"a"}}
+
+  {"type":"refund_call","body":{"call_id":"a"}}
+`
+	src := NewSource(strings.NewReader(input), "test")
+
+	deliveries, err := src.Deliveries()
+	assert.NoError(t, err)
+
+	var bodies []string
+	for d := range deliveries {
+		bodies = append(bodies, string(d.Body()))
+		assert.NoError(t, d.Ack())
+	}
+
+	assert.Equal(t, []string{
+		`{"type":"new_incoming_call","body":{"call_id":"a"}}`,
+		`{"type":"refund_call","body":{"call_id":"a"}}`,
+	}, bodies)
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/port/messaging"
+)
+
+// Source es una fuente en memoria pensada para tests: los mensajes se
+// publican con Publish y se registra el resultado de cada uno. El número de
+// entrega sale del header x-retry-count, como en RabbitMQ.
+type Source struct {
+	ch           chan messaging.Delivery
+	mu           sync.Mutex
+	acked        [][]byte
+	nacked       [][]byte
+	requeued     [][]byte
+	deadLettered [][]byte
+	delays       []time.Duration
+	once         sync.Once
+}
+
+var _ messaging.RetryableDelivery = (*delivery)(nil)
+
+func NewSource(buffer int) *Source {
+	return &Source{ch: make(chan messaging.Delivery, buffer)}
+}
+
+func (s *Source) Deliveries() (<-chan messaging.Delivery, error) {
+	return s.ch, nil
+}
+
+func (s *Source) Close() error {
+	s.once.Do(func() { close(s.ch) })
+	return nil
+}
+
+// Publish encola un mensaje con content type y headers opcionales.
+func (s *Source) Publish(body []byte, contentType string, headers map[string]interface{}) {
+	s.ch <- &delivery{source: s, body: body, contentType: contentType, headers: headers}
+}
+
+func (s *Source) Acked() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.acked...)
+}
+
+func (s *Source) Nacked() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.nacked...)
+}
+
+// Requeued devuelve los mensajes reencolados o reprogramados con Retry. No
+// se vuelven a entregar para que los tests sean deterministas.
+func (s *Source) Requeued() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.requeued...)
+}
+
+// RetryDelays devuelve la espera pedida en cada Retry.
+func (s *Source) RetryDelays() []time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Duration(nil), s.delays...)
+}
+
+func (s *Source) DeadLettered() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.deadLettered...)
+}
+
+type delivery struct {
+	source      *Source
+	body        []byte
+	contentType string
+	headers     map[string]interface{}
+}
+
+func (d *delivery) Body() []byte                    { return d.body }
+func (d *delivery) ContentType() string             { return d.contentType }
+func (d *delivery) Headers() map[string]interface{} { return d.headers }
+
+func (d *delivery) Ack() error {
+	d.source.mu.Lock()
+	defer d.source.mu.Unlock()
+	d.source.acked = append(d.source.acked, d.body)
+	return nil
+}
+
+func (d *delivery) Attempt() int {
+	if n, ok := d.headers["x-retry-count"].(int); ok {
+		return n + 1
+	}
+	return 1
+}
+
+func (d *delivery) Retry(delay time.Duration) error {
+	d.source.mu.Lock()
+	defer d.source.mu.Unlock()
+	d.source.requeued = append(d.source.requeued, d.body)
+	d.source.delays = append(d.source.delays, delay)
+	return nil
+}
+
+func (d *delivery) DeadLetter(reason string) error {
+	d.source.mu.Lock()
+	defer d.source.mu.Unlock()
+	d.source.deadLettered = append(d.source.deadLettered, d.body)
+	return nil
+}
+
+func (d *delivery) Nack(requeue bool) error {
+	d.source.mu.Lock()
+	defer d.source.mu.Unlock()
+	if requeue {
+		d.source.requeued = append(d.source.requeued, d.body)
+		return nil
+	}
+	d.source.nacked = append(d.source.nacked, d.body)
+	return nil
+}
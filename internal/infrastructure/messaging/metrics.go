@@ -0,0 +1,62 @@
+package messaging
+
+import (
+	"errors"
+	"expvar"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+)
+
+// Resultados de un mensaje en Metrics.Messages.
+const (
+	OutcomeAcked    = "acked"
+	OutcomeRejected = "rejected"
+	OutcomeRequeued = "requeued"
+	// OutcomeDeadLettered: agotó los reintentos de la RetryPolicy.
+	OutcomeDeadLettered = "dead_lettered"
+)
+
+// Metrics cuenta los mensajes por "<tipo>.<resultado>" y los errores de
+// validación por "<entidad>.<campo>".
+type Metrics struct {
+	Messages   *expvar.Map
+	Validation *expvar.Map
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{Messages: new(expvar.Map).Init(), Validation: new(expvar.Map).Init()}
+}
+
+// Publish expone los contadores en /debug/vars como <prefix>_messages y
+// <prefix>_validation_errors. Solo puede llamarse una vez por prefijo.
+func (m *Metrics) Publish(prefix string) {
+	expvar.Publish(prefix+"_messages", m.Messages)
+	expvar.Publish(prefix+"_validation_errors", m.Validation)
+}
+
+// Count devuelve el contador de un mensaje tipo/resultado (0 si no hubo).
+func (m *Metrics) Count(msgType, outcome string) int64 {
+	return counter(m.Messages, msgType+"."+outcome)
+}
+
+// ValidationCount devuelve cuántas veces falló entity.field.
+func (m *Metrics) ValidationCount(entity, field string) int64 {
+	return counter(m.Validation, entity+"."+field)
+}
+
+func (m *Metrics) record(msgType, outcome string, err error) {
+	m.Messages.Add(msgType+"."+outcome, 1)
+	var verr *model.ValidationError
+	if errors.As(err, &verr) {
+		for _, f := range verr.Fields {
+			m.Validation.Add(verr.Entity+"."+f.Field, 1)
+		}
+	}
+}
+
+func counter(m *expvar.Map, key string) int64 {
+	if v, ok := m.Get(key).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
@@ -21,6 +21,10 @@ func NewPostgresConnection(dsn string) (*sql.DB, error) {
 		return nil, fmt.Errorf("error creating calls table: %w", err)
 	}
 
+	if err := migrate(db); err != nil {
+		return nil, fmt.Errorf("error migrating schema: %w", err)
+	}
+
 	return db, nil
 }
 
@@ -43,3 +47,128 @@ func createCallsTableIfNotExists(db *sql.DB) error {
 	_, err := db.Exec(query)
 	return err
 }
+
+// migrations se aplican en orden sobre una base existente; cada sentencia
+// debe ser idempotente.
+var migrations = []string{
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS base_cost NUMERIC(10, 2)`,
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS base_currency TEXT`,
+	`CREATE TABLE IF NOT EXISTS fx_rates (
+		rate_date DATE NOT NULL,
+		from_currency TEXT NOT NULL,
+		to_currency TEXT NOT NULL,
+		rate NUMERIC(20, 10) NOT NULL,
+		PRIMARY KEY (rate_date, from_currency, to_currency)
+	)`,
+	`CREATE TABLE IF NOT EXISTS rate_cards (
+		version TEXT PRIMARY KEY,
+		effective_from TIMESTAMPTZ NOT NULL,
+		card JSONB NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS shadow_costs (
+		call_id UUID PRIMARY KEY,
+		provider TEXT NOT NULL,
+		cost NUMERIC(10, 2),
+		currency TEXT,
+		error TEXT,
+		created_at TIMESTAMPTZ DEFAULT now() NOT NULL
+	)`,
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS provider TEXT`,
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS taxes JSONB`,
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS rate_id TEXT`,
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS billed_duration_sec INTEGER`,
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS provider_reference TEXT`,
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS caller_country TEXT`,
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS caller_type TEXT`,
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS receiver_country TEXT`,
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS receiver_type TEXT`,
+	`CREATE TABLE IF NOT EXISTS call_credits (
+		call_id UUID PRIMARY KEY,
+		severity TEXT NOT NULL,
+		issue_type TEXT NOT NULL,
+		percent INTEGER NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT now() NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS refunds (
+		call_id UUID NOT NULL,
+		refund_id TEXT NOT NULL,
+		reason TEXT,
+		amount NUMERIC(10, 2),
+		currency TEXT,
+		percent INTEGER,
+		created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+		PRIMARY KEY (call_id, refund_id)
+	)`,
+	// Los refunds anteriores a la tabla refunds eran todos totales
+	`INSERT INTO refunds (call_id, refund_id, reason, created_at)
+	SELECT call_id, 'legacy', refund_reason, processed_at FROM calls WHERE refunded
+	ON CONFLICT (call_id, refund_id) DO NOTHING`,
+	`ALTER TABLE calls ADD COLUMN IF NOT EXISTS prior_status VARCHAR(20)`,
+	`CREATE TABLE IF NOT EXISTS refund_reversals (
+		call_id UUID NOT NULL,
+		reversal_id TEXT NOT NULL,
+		refund_id TEXT,
+		reason TEXT,
+		applied_to TEXT,
+		created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+		PRIMARY KEY (call_id, reversal_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS call_events (
+		id BIGSERIAL PRIMARY KEY,
+		call_id UUID NOT NULL,
+		type TEXT NOT NULL,
+		detail TEXT,
+		created_at TIMESTAMPTZ DEFAULT now() NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS call_events_call_id_idx ON call_events (call_id)`,
+	`CREATE TABLE IF NOT EXISTS cost_adjustments (
+		call_id UUID PRIMARY KEY,
+		cost NUMERIC(10, 2) NOT NULL,
+		currency TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		actor TEXT NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT now() NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS bulk_refunds (
+		bulk_id TEXT PRIMARY KEY,
+		cursor UUID,
+		matched INT NOT NULL DEFAULT 0,
+		refunded INT NOT NULL DEFAULT 0,
+		rejected INT NOT NULL DEFAULT 0,
+		done BOOLEAN NOT NULL DEFAULT false,
+		updated_at TIMESTAMPTZ DEFAULT now() NOT NULL
+	)`,
+	// El DEFAULT estampa las filas existentes con la hora de la migración: los
+	// placeholders previos toman processed_at (su último refund) para que la
+	// expiración no los reinicie. Corre una sola vez, al agregar la columna.
+	`DO $$
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'calls' AND column_name = 'created_at') THEN
+			ALTER TABLE calls ADD COLUMN created_at TIMESTAMPTZ DEFAULT now() NOT NULL;
+			UPDATE calls SET created_at = processed_at
+			WHERE status = 'REFUND_PARTIALLY' AND caller IS NULL AND processed_at IS NOT NULL;
+		END IF;
+	END $$`,
+	`CREATE INDEX IF NOT EXISTS calls_placeholders_idx ON calls (created_at) WHERE status = 'REFUND_PARTIALLY'`,
+	`CREATE TABLE IF NOT EXISTS pending_events (
+		seq BIGSERIAL PRIMARY KEY,
+		call_id UUID NOT NULL,
+		event_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		body TEXT NOT NULL,
+		created_at TIMESTAMPTZ DEFAULT now() NOT NULL,
+		UNIQUE (call_id, event_id)
+	)`,
+	`ALTER TABLE refunds ADD COLUMN IF NOT EXISTS rejected TEXT`,
+	`CREATE INDEX IF NOT EXISTS pending_events_created_at_idx ON pending_events (created_at)`,
+}
+
+func migrate(db *sql.DB) error {
+	for _, m := range migrations {
+		if _, err := db.Exec(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
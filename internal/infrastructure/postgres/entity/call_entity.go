@@ -7,33 +7,32 @@ import (
 )
 
 type CallEntity struct {
-	CallID         string    `db:"call_id"`
-	Caller         string    `db:"caller"`
-	Receiver       string    `db:"receiver"`
-	DurationInSec  int       `db:"duration_in_seconds"`
-	StartTimestamp time.Time `db:"start_timestamp"`
-	Cost           float64   `db:"cost"`
-	Currency       string    `db:"currency"`
-	Refunded       bool      `db:"refunded"`
-	RefundReason   *string   `db:"refund_reason"`
-	Status         string    `db:"status"`
-	ProcessedAt    time.Time `db:"processed_at"`
+	CallID         string       `db:"call_id"`
+	Caller         string       `db:"caller"`
+	Receiver       string       `db:"receiver"`
+	DurationInSec  int          `db:"duration_in_seconds"`
+	StartTimestamp time.Time    `db:"start_timestamp"`
+	Cost           *model.Money `db:"cost"`
+	Refunded       bool         `db:"refunded"`
+	RefundReason   *string      `db:"refund_reason"`
+	Status         string       `db:"status"`
+	ProcessedAt    time.Time    `db:"processed_at"`
+
+	// caller_country, caller_type, receiver_country, receiver_type
+	Numbering model.CallNumbering
 }
 
-func FromNewIncomingCall(m model.NewIncomingCall) (CallEntity, error) {
-ts, err := time.Parse(time.RFC3339, m.StartTimestamp)
-	if err != nil {
-		return CallEntity{}, err
-	}
+func FromNewIncomingCall(m model.NewIncomingCall) CallEntity {
 	return CallEntity{
 		CallID:         m.CallID,
 		Caller:         m.Caller,
 		Receiver:       m.Receiver,
+		Numbering:      m.Numbering,
 		DurationInSec:  m.DurationInSec,
-		StartTimestamp: ts,
+		StartTimestamp: m.StartTimestamp,
 		Status:         "PENDING",
 		ProcessedAt:    time.Now(),
-	}, nil
+	}
 }
 
 func FromRefundCall(m model.RefundCall) CallEntity {
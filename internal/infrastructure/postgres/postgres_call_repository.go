@@ -2,10 +2,16 @@ package postgres
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
+
 	"phonecall-cost-processor-service/internal/domain/model"
 	"phonecall-cost-processor-service/internal/domain/port/repository"
 	"phonecall-cost-processor-service/internal/infrastructure/postgres/entity"
+
+	"github.com/lib/pq"
 )
 
 type PostgresCallRepository struct {
@@ -15,7 +21,8 @@ type PostgresCallRepository struct {
 func (r *PostgresCallRepository) MarkCallAsInvalid(callID string) error {
 	const query = `
 		UPDATE calls
-		SET status = 'INVALID',
+		SET status = CASE WHEN status = 'REFUNDED' THEN status ELSE 'INVALID' END,
+			prior_status = 'INVALID',
 			processed_at = NOW()
 		WHERE call_id = $1;
 	`
@@ -28,20 +35,28 @@ func NewPostgresCallRepository(db *sql.DB) *PostgresCallRepository {
 	return &PostgresCallRepository{db: db}
 }
 
-var _ repository.CallRepository = (*PostgresCallRepository)(nil)
+var (
+	_ repository.CallRepository    = (*PostgresCallRepository)(nil)
+	_ repository.CallWriter        = (*PostgresCallRepository)(nil)
+	_ repository.CostResultWriter  = (*PostgresCallRepository)(nil)
+	_ repository.RefundRepository  = (*PostgresCallRepository)(nil)
+	_ repository.CallReader        = (*PostgresCallRepository)(nil)
+	_ repository.CallDetailsReader = (*PostgresCallRepository)(nil)
+	_ repository.ShadowCostWriter  = (*PostgresCallRepository)(nil)
+	_ repository.ShadowCostReader  = (*PostgresCallRepository)(nil)
+)
 
 func (r *PostgresCallRepository) SaveIncomingCall(call model.NewIncomingCall) error {
-	e, err := entity.FromNewIncomingCall(call)
-	if err != nil {
-		return fmt.Errorf("error mapeando NewIncomingCall: %w", err)
-	}
+	e := entity.FromNewIncomingCall(call)
 
 	const query = `
 	INSERT INTO calls (
-		call_id, caller, receiver, duration_in_seconds, start_timestamp, status, processed_at
-	) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		call_id, caller, receiver, duration_in_seconds, start_timestamp, status, processed_at,
+		caller_country, caller_type, receiver_country, receiver_type
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	ON CONFLICT (call_id) DO NOTHING;
 	`
+	n := e.Numbering
 	if _, err := r.db.Exec(query,
 		e.CallID,
 		e.Caller,
@@ -50,35 +65,122 @@ func (r *PostgresCallRepository) SaveIncomingCall(call model.NewIncomingCall) er
 		e.StartTimestamp,
 		e.Status,
 		e.ProcessedAt,
+		nullString(n.CallerCountry),
+		nullString(string(n.CallerType)),
+		nullString(n.ReceiverCountry),
+		nullString(string(n.ReceiverType)),
 	); err != nil {
 		return fmt.Errorf("error insertando llamada: %w", err)
 	}
 	return nil
 }
 
-func (r *PostgresCallRepository) UpdateCallCost(callID string, cost float64, currency string) error {
+// UpdateCallCost guarda el costo y, en la misma transacción, rechaza los
+// refunds anticipados que no entran en él (ver model.RecheckRefunds).
+func (r *PostgresCallRepository) UpdateCallCost(callID string, result model.CostResult) error {
+	if err := result.Cost.CheckPrecision(repository.CostPrecision, repository.CostScale); err != nil {
+		return fmt.Errorf("error actualizando costo: %w", err)
+	}
+	var baseCost, baseCurrency sql.NullString
+	if result.BaseCost != nil {
+		if err := result.BaseCost.CheckPrecision(repository.CostPrecision, repository.CostScale); err != nil {
+			return fmt.Errorf("error actualizando costo: %w", err)
+		}
+		baseCost = sql.NullString{String: result.BaseCost.String(), Valid: true}
+		baseCurrency = sql.NullString{String: result.BaseCost.Currency, Valid: true}
+	}
+
 	const query = `
 	UPDATE calls
 	SET cost = $1,
 		currency = $2,
-		status = 'OK',
+		base_cost = $3,
+		base_currency = $4,
+		provider = $5,
+		taxes = $6,
+		rate_id = $7,
+		billed_duration_sec = $8,
+		provider_reference = $9,
+		status = CASE WHEN status = 'REFUNDED' THEN status ELSE 'OK' END,
+		prior_status = 'OK',
 		processed_at = NOW()
-	WHERE call_id = $3
-	AND status != 'REFUNDED';
+	WHERE call_id = $10;
 	`
-	if _, err := r.db.Exec(query, cost, currency, callID); err != nil {
+	provider := sql.NullString{String: result.Provider, Valid: result.Provider != ""}
+	b := result.Breakdown
+	var taxes sql.NullString
+	if len(b.Taxes) > 0 {
+		raw, err := json.Marshal(b.Taxes)
+		if err != nil {
+			return fmt.Errorf("error actualizando costo: %w", err)
+		}
+		taxes = sql.NullString{String: string(raw), Valid: true}
+	}
+	var billed sql.NullInt64
+	if b.BilledDurationSec != nil {
+		billed = sql.NullInt64{Int64: int64(*b.BilledDurationSec), Valid: true}
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error actualizando costo: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(query, result.Cost.String(), result.Cost.Currency, baseCost, baseCurrency, provider,
+		taxes, nullString(b.RateID), billed, nullString(b.ProviderReference), callID); err != nil {
+		return fmt.Errorf("error actualizando costo: %w", err)
+	}
+	if err := rejectRefunds(tx, callID); err != nil {
+		return fmt.Errorf("error revisando refunds: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("error actualizando costo: %w", err)
 	}
 	return nil
 }
 
+// rejectRefunds marca los refunds que no entran en el costo facturado (el
+// ajustado si lo hay) y los registra en el historial.
+func rejectRefunds(tx *sql.Tx, callID string) error {
+	var cost, currency sql.NullString
+	err := tx.QueryRow(`
+	SELECT COALESCE(a.cost, c.cost)::text, COALESCE(a.currency, c.currency)
+	FROM calls c
+	LEFT JOIN cost_adjustments a ON a.call_id = c.call_id
+	WHERE c.call_id = $1`, callID).Scan(&cost, &currency)
+	if err == sql.ErrNoRows || (err == nil && !cost.Valid) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	billed, err := model.ParseMoney(cost.String, currency.String)
+	if err != nil {
+		return err
+	}
+	refunds, err := loadRefunds(tx, callID)
+	if err != nil {
+		return err
+	}
+	for _, refund := range model.RecheckRefunds(billed, refunds) {
+		if _, err := tx.Exec(`UPDATE refunds SET rejected = $3 WHERE call_id = $1 AND refund_id = $2`,
+			callID, refund.ID(), refund.Rejected); err != nil {
+			return err
+		}
+		if err := recordEvent(tx, model.RejectedRefundEvent(refund)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *PostgresCallRepository) MarkCostAsFailed(callID string) error {
 	const query = `
 	UPDATE calls
-	SET status = 'ERROR',
+	SET status = CASE WHEN status = 'REFUNDED' THEN status ELSE 'ERROR' END,
+		prior_status = 'ERROR',
 		processed_at = NOW()
-	WHERE call_id = $1
-	AND status != 'REFUNDED';
+	WHERE call_id = $1;
 	`
 	if _, err := r.db.Exec(query, callID); err != nil {
 		return fmt.Errorf("error marcando fallo de costo: %w", err)
@@ -86,27 +188,543 @@ func (r *PostgresCallRepository) MarkCostAsFailed(callID string) error {
 	return nil
 }
 
-func (r *PostgresCallRepository) ApplyRefund(refund model.RefundCall) error {
+// ApplyRefund bloquea la fila de la llamada para validar el refund contra su
+// costo y los refunds ya guardados sin carreras con otros refunds.
+func (r *PostgresCallRepository) ApplyRefund(refund model.RefundCall) (bool, error) {
 	e := entity.FromRefundCall(refund)
 
-	const query = `
-	INSERT INTO calls (call_id, refunded, refund_reason, cost, status, processed_at)
-	VALUES ($1, true, $2, 0, 'REFUND_PARTIALLY', NOW())
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("error aplicando refund: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	var cost, currency sql.NullString
+	err = tx.QueryRow(`
+	SELECT c.status, COALESCE(a.cost, c.cost)::text, COALESCE(a.currency, c.currency)
+	FROM calls c
+	LEFT JOIN cost_adjustments a ON a.call_id = c.call_id
+	WHERE c.call_id = $1
+	FOR UPDATE OF c`, e.CallID).
+		Scan(&status, &cost, &currency)
+	exists := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return false, fmt.Errorf("error aplicando refund: %w", err)
+	}
+	var current *model.Money
+	if exists && (status == "OK" || status == "REFUNDED") && cost.Valid {
+		m, err := model.ParseMoney(cost.String, currency.String)
+		if err != nil {
+			return false, fmt.Errorf("error aplicando refund: %w", err)
+		}
+		current = &m
+	}
+	existing, err := loadRefunds(tx, e.CallID)
+	if err != nil {
+		return false, fmt.Errorf("error aplicando refund: %w", err)
+	}
+	save, err := model.CheckRefund(refund, current, existing)
+	if err != nil {
+		return false, fmt.Errorf("error aplicando refund: %w", err)
+	}
+	if !save {
+		return false, nil
+	}
+	pending, err := loadPendingReversals(tx, e.CallID)
+	if err != nil {
+		return false, fmt.Errorf("error aplicando refund: %w", err)
+	}
+	if i := model.PendingReversal(refund, pending); i >= 0 {
+		// Por refund_id y no por reversal_id: las filas anteriores tienen el
+		// hash viejo, que incluía el motivo
+		if _, err := tx.Exec(`UPDATE refund_reversals SET applied_to = $3 WHERE call_id = $1 AND COALESCE(refund_id, '') = $2 AND applied_to IS NULL`,
+			e.CallID, pending[i].RefundID, refund.ID()); err != nil {
+			return false, fmt.Errorf("error aplicando refund: %w", err)
+		}
+		refund.Reversed = true
+	}
+
+	var amount, amountCurrency sql.NullString
+	if refund.Amount != nil {
+		if err := refund.Amount.CheckPrecision(repository.CostPrecision, repository.CostScale); err != nil {
+			return false, fmt.Errorf("error aplicando refund: %w", err)
+		}
+		amount = sql.NullString{String: refund.Amount.String(), Valid: true}
+		amountCurrency = sql.NullString{String: refund.Amount.Currency, Valid: true}
+	}
+	var percent sql.NullInt64
+	if refund.Percent != nil {
+		percent = sql.NullInt64{Int64: int64(*refund.Percent), Valid: true}
+	}
+	if _, err := tx.Exec(`
+	INSERT INTO refunds (call_id, refund_id, reason, amount, currency, percent, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	ON CONFLICT (call_id, refund_id) DO NOTHING;`,
+		e.CallID, refund.ID(), e.RefundReason, amount, amountCurrency, percent); err != nil {
+		return false, fmt.Errorf("error aplicando refund: %w", err)
+	}
+	if err := recordEvent(tx, model.RefundEvent(refund, refund.Reversed)); err != nil {
+		return false, fmt.Errorf("error aplicando refund: %w", err)
+	}
+
+	if !exists {
+		_, err = tx.Exec(`
+		INSERT INTO calls (call_id, refunded, refund_reason, cost, status, processed_at)
+		VALUES ($1, $2, $3, 0, 'REFUND_PARTIALLY', NOW())
+		ON CONFLICT (call_id) DO NOTHING;`, e.CallID, refund.Full() && !refund.Reversed, e.RefundReason)
+	} else if refund.Full() && !refund.Reversed {
+		next := model.RefundStatus(refund, status)
+		_, err = tx.Exec(`
+		UPDATE calls
+		SET refunded = true,
+			refund_reason = $2,
+			status = $3,
+			prior_status = COALESCE($4, prior_status),
+			processed_at = NOW()
+		WHERE call_id = $1;`, e.CallID, e.RefundReason, next, nullString(model.StatusBeforeRefund(status, next)))
+	}
+	if err != nil {
+		return false, fmt.Errorf("error aplicando refund: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("error aplicando refund: %w", err)
+	}
+	return true, nil
+}
+
+// ReverseRefund anula el refund bajo el mismo bloqueo que ApplyRefund. Si el
+// refund todavía no llegó la anulación queda pendiente en refund_reversals.
+func (r *PostgresCallRepository) ReverseRefund(v model.RefundReversal) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error anulando refund: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	var prior sql.NullString
+	err = tx.QueryRow(`SELECT status, prior_status FROM calls WHERE call_id = $1 FOR UPDATE`, v.CallID).
+		Scan(&status, &prior)
+	exists := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error anulando refund: %w", err)
+	}
+	var known bool
+	err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM refund_reversals WHERE call_id = $1 AND COALESCE(refund_id, '') = $2)`,
+		v.CallID, v.RefundID).Scan(&known)
+	if err != nil {
+		return fmt.Errorf("error anulando refund: %w", err)
+	}
+	if known {
+		// Reentrega de una anulación ya registrada, aunque cambie el motivo
+		return nil
+	}
+	refunds, err := loadRefunds(tx, v.CallID)
+	if err != nil {
+		return fmt.Errorf("error anulando refund: %w", err)
+	}
+	i := v.Target(refunds)
+	if i < 0 && v.AlreadyReversed(refunds) {
+		return nil
+	}
+	var appliedTo sql.NullString
+	if i >= 0 {
+		appliedTo = sql.NullString{String: refunds[i].ID(), Valid: true}
+	}
+	res, err := tx.Exec(`
+	INSERT INTO refund_reversals (call_id, reversal_id, refund_id, reason, applied_to, created_at)
+	VALUES ($1, $2, $3, $4, $5, NOW())
+	ON CONFLICT (call_id, reversal_id) DO NOTHING;`,
+		v.CallID, v.ID(), nullString(v.RefundID), nullString(v.Reason), appliedTo)
+	if err != nil {
+		return fmt.Errorf("error anulando refund: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		// Reentrega de una anulación ya registrada
+		return err
+	}
+
+	event := model.ReversalEvent(v, nil, "", "")
+	if i >= 0 {
+		refunds[i].Reversed = true
+		event = model.ReversalEvent(v, &refunds[i], status, status)
+	}
+	if i >= 0 && exists {
+		next := model.ReversedStatus(status, prior.String, refunds)
+		if _, err := tx.Exec(`
+		UPDATE calls
+		SET status = $2,
+			refunded = $3,
+			processed_at = NOW()
+		WHERE call_id = $1;`, v.CallID, next, model.FilledStatus(refunds) == "REFUNDED"); err != nil {
+			return fmt.Errorf("error anulando refund: %w", err)
+		}
+		event = model.ReversalEvent(v, &refunds[i], status, next)
+	}
+	if err := recordEvent(tx, event); err != nil {
+		return fmt.Errorf("error anulando refund: %w", err)
+	}
+	return tx.Commit()
+}
+
+// AdjustCost reemplaza el ajuste anterior y lo registra en el historial
+// solo si cambia algo, para que una reentrega no duplique el evento.
+func (r *PostgresCallRepository) AdjustCost(adj model.CostAdjustment) (bool, error) {
+	if err := adj.Cost.CheckPrecision(repository.CostPrecision, repository.CostScale); err != nil {
+		return false, fmt.Errorf("error ajustando costo: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("error ajustando costo: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Mismo bloqueo que ApplyRefund: un refund por monto no puede colarse
+	// entre la verificación de moneda y el ajuste
+	if _, err := tx.Exec(`SELECT 1 FROM calls WHERE call_id = $1 FOR UPDATE`, adj.CallID); err != nil {
+		return false, fmt.Errorf("error ajustando costo: %w", err)
+	}
+	refunds, err := loadRefunds(tx, adj.CallID)
+	if err != nil {
+		return false, fmt.Errorf("error ajustando costo: %w", err)
+	}
+	if err := adj.CheckRefunds(refunds); err != nil {
+		return false, fmt.Errorf("error ajustando costo: %w", err)
+	}
+
+	res, err := tx.Exec(`
+	INSERT INTO cost_adjustments (call_id, cost, currency, reason, actor, created_at)
+	VALUES ($1, $2, $3, $4, $5, NOW())
 	ON CONFLICT (call_id) DO UPDATE
-	SET refunded = true,
-		refund_reason = EXCLUDED.refund_reason,
-		cost = 0,
-		status = 'REFUNDED',
-		processed_at = NOW();
-	`
+	SET cost = EXCLUDED.cost,
+		currency = EXCLUDED.currency,
+		reason = EXCLUDED.reason,
+		actor = EXCLUDED.actor,
+		created_at = EXCLUDED.created_at
+	WHERE (cost_adjustments.cost, cost_adjustments.currency, cost_adjustments.reason, cost_adjustments.actor)
+		IS DISTINCT FROM (EXCLUDED.cost, EXCLUDED.currency, EXCLUDED.reason, EXCLUDED.actor);`,
+		adj.CallID, adj.Cost.String(), adj.Cost.Currency, adj.Reason, adj.Actor)
+	if err != nil {
+		return false, fmt.Errorf("error ajustando costo: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	if err := recordEvent(tx, model.AdjustmentEvent(adj)); err != nil {
+		return false, fmt.Errorf("error ajustando costo: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("error ajustando costo: %w", err)
+	}
+	return true, nil
+}
+
+func (r *PostgresCallRepository) RefundCandidates(f model.BulkRefundFilter, afterCallID string, limit int) ([]string, error) {
+	rows, err := r.db.Query(`
+	SELECT call_id
+	FROM calls
+	WHERE start_timestamp >= $1 AND start_timestamp < $2
+	AND (cardinality($3::text[]) = 0 OR caller = ANY($3))
+	AND ($4 = '' OR left(caller, length($4)) = $4)
+	AND (cardinality($5::text[]) = 0 OR status = ANY($5))
+	AND ($6::uuid IS NULL OR call_id > $6)
+	ORDER BY call_id
+	LIMIT $7;`,
+		f.From, f.To, pq.Array(f.Callers), f.CallerPrefix, pq.Array(f.Statuses),
+		sql.NullString{String: afterCallID, Valid: afterCallID != ""}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando llamadas del refund masivo: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error buscando llamadas del refund masivo: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *PostgresCallRepository) BulkRefundProgress(bulkID string) (model.BulkRefundProgress, error) {
+	p := model.BulkRefundProgress{BulkID: bulkID}
+	err := r.db.QueryRow(`
+	SELECT COALESCE(cursor::text, ''), matched, refunded, rejected, done
+	FROM bulk_refunds
+	WHERE bulk_id = $1;`, bulkID).Scan(&p.Cursor, &p.Matched, &p.Refunded, &p.Rejected, &p.Done)
+	if err == sql.ErrNoRows {
+		return p, nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("error leyendo avance del refund masivo: %w", err)
+	}
+	return p, nil
+}
+
+func (r *PostgresCallRepository) SaveBulkRefundProgress(p model.BulkRefundProgress) error {
+	_, err := r.db.Exec(`
+	INSERT INTO bulk_refunds (bulk_id, cursor, matched, refunded, rejected, done, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	ON CONFLICT (bulk_id) DO UPDATE
+	SET cursor = EXCLUDED.cursor,
+		matched = EXCLUDED.matched,
+		refunded = EXCLUDED.refunded,
+		rejected = EXCLUDED.rejected,
+		done = EXCLUDED.done,
+		updated_at = EXCLUDED.updated_at;`,
+		p.BulkID, sql.NullString{String: p.Cursor, Valid: p.Cursor != ""}, p.Matched, p.Refunded, p.Rejected, p.Done)
+	if err != nil {
+		return fmt.Errorf("error guardando avance del refund masivo: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresCallRepository) ParkEvent(e model.PendingEvent) (bool, error) {
+	var parked bool
+	err := r.db.QueryRow(`
+	WITH park AS (
+		SELECT NOT EXISTS (SELECT 1 FROM calls WHERE call_id = $1 AND (caller IS NOT NULL OR status = 'EXPIRED'))
+			OR EXISTS (SELECT 1 FROM pending_events WHERE call_id = $1) AS park
+	), inserted AS (
+		INSERT INTO pending_events (call_id, event_id, type, body)
+		SELECT $1, $2, $3, $4 FROM park WHERE park
+		ON CONFLICT (call_id, event_id) DO NOTHING
+	)
+	SELECT park FROM park;`, e.CallID, e.ID(), e.Type, string(e.Body)).Scan(&parked)
+	if err != nil {
+		return false, fmt.Errorf("error estacionando evento: %w", err)
+	}
+	return parked, nil
+}
 
-	if _, err := r.db.Exec(query, e.CallID, e.RefundReason); err != nil {
-		return fmt.Errorf("error aplicando refund: %w", err)
+func (r *PostgresCallRepository) PendingEvents(callID string) ([]model.PendingEvent, error) {
+	rows, err := r.db.Query(`
+	SELECT seq, call_id, type, body, created_at
+	FROM pending_events
+	WHERE call_id = $1
+	ORDER BY seq;`, callID)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo eventos estacionados: %w", err)
+	}
+	defer rows.Close()
+
+	var events []model.PendingEvent
+	for rows.Next() {
+		var e model.PendingEvent
+		var body string
+		if err := rows.Scan(&e.Seq, &e.CallID, &e.Type, &body, &e.ReceivedAt); err != nil {
+			return nil, fmt.Errorf("error leyendo eventos estacionados: %w", err)
+		}
+		e.Body = []byte(body)
+		events = append(events, e)
 	}
+	return events, rows.Err()
+}
 
+func (r *PostgresCallRepository) DeletePendingEvent(callID string, seq int64) error {
+	_, err := r.db.Exec(`DELETE FROM pending_events WHERE call_id = $1 AND seq = $2;`, callID, seq)
+	if err != nil {
+		return fmt.Errorf("error borrando evento estacionado: %w", err)
+	}
 	return nil
 }
 
+func (r *PostgresCallRepository) ExpirePendingEvents(receivedBefore time.Time) ([]model.PendingEvent, error) {
+	rows, err := r.db.Query(`
+	DELETE FROM pending_events
+	WHERE created_at < $1
+	RETURNING seq, call_id, type, body, created_at;`, receivedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("error expirando eventos estacionados: %w", err)
+	}
+	defer rows.Close()
+
+	var expired []model.PendingEvent
+	for rows.Next() {
+		var e model.PendingEvent
+		var body string
+		if err := rows.Scan(&e.Seq, &e.CallID, &e.Type, &body, &e.ReceivedAt); err != nil {
+			return nil, fmt.Errorf("error expirando eventos estacionados: %w", err)
+		}
+		e.Body = []byte(body)
+		e.ReceivedAt = e.ReceivedAt.UTC()
+		expired = append(expired, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error expirando eventos estacionados: %w", err)
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].Seq < expired[j].Seq })
+	return expired, nil
+}
+
+func (r *PostgresCallRepository) Placeholders() ([]model.Placeholder, error) {
+	rows, err := r.db.Query(`
+	SELECT call_id, created_at
+	FROM calls
+	WHERE status = 'REFUND_PARTIALLY' AND caller IS NULL
+	ORDER BY created_at;`)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo placeholders: %w", err)
+	}
+	defer rows.Close()
+	return scanPlaceholders(rows)
+}
+
+func (r *PostgresCallRepository) ExpirePlaceholders(createdBefore time.Time) ([]model.Placeholder, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error expirando placeholders: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+	UPDATE calls
+	SET status = 'EXPIRED',
+		processed_at = NOW()
+	WHERE status = 'REFUND_PARTIALLY' AND caller IS NULL AND created_at < $1
+	RETURNING call_id, created_at;`, createdBefore)
+	if err != nil {
+		return nil, fmt.Errorf("error expirando placeholders: %w", err)
+	}
+	expired, err := scanPlaceholders(rows)
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error expirando placeholders: %w", err)
+	}
+
+	for _, p := range expired {
+		if err := recordEvent(tx, model.ExpiryEvent(p)); err != nil {
+			return nil, fmt.Errorf("error expirando placeholders: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM pending_events WHERE call_id = $1;`, p.CallID); err != nil {
+			return nil, fmt.Errorf("error expirando placeholders: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error expirando placeholders: %w", err)
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].CreatedAt.Before(expired[j].CreatedAt) })
+	return expired, nil
+}
+
+func scanPlaceholders(rows *sql.Rows) ([]model.Placeholder, error) {
+	var list []model.Placeholder
+	for rows.Next() {
+		var p model.Placeholder
+		if err := rows.Scan(&p.CallID, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		p.CreatedAt = p.CreatedAt.UTC()
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+func loadPendingReversals(q queryer, callID string) ([]model.RefundReversal, error) {
+	rows, err := q.Query(`
+	SELECT call_id, COALESCE(refund_id, ''), COALESCE(reason, '')
+	FROM refund_reversals
+	WHERE call_id = $1 AND applied_to IS NULL
+	ORDER BY created_at;`, callID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pending []model.RefundReversal
+	for rows.Next() {
+		var v model.RefundReversal
+		if err := rows.Scan(&v.CallID, &v.RefundID, &v.Reason); err != nil {
+			return nil, err
+		}
+		pending = append(pending, v)
+	}
+	return pending, rows.Err()
+}
+
+func recordEvent(tx *sql.Tx, e model.CallEvent) error {
+	_, err := tx.Exec(`INSERT INTO call_events (call_id, type, detail, created_at) VALUES ($1, $2, $3, NOW())`,
+		e.CallID, e.Type, e.Detail)
+	return err
+}
+
+func (r *PostgresCallRepository) CallHistory(callID string) ([]model.CallEvent, error) {
+	rows, err := r.db.Query(`
+	SELECT call_id, type, COALESCE(detail, ''), created_at
+	FROM call_events
+	WHERE call_id = $1
+	ORDER BY id;`, callID)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo historial: %w", err)
+	}
+	defer rows.Close()
+
+	var events []model.CallEvent
+	for rows.Next() {
+		var e model.CallEvent
+		if err := rows.Scan(&e.CallID, &e.Type, &e.Detail, &e.At); err != nil {
+			return nil, fmt.Errorf("error leyendo historial: %w", err)
+		}
+		e.At = e.At.UTC()
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func loadRefunds(q queryer, callID string) ([]model.RefundCall, error) {
+	rows, err := q.Query(`
+	SELECT rf.call_id, rf.refund_id, COALESCE(rf.reason, ''), rf.amount::text, rf.currency, rf.percent,
+		EXISTS (SELECT 1 FROM refund_reversals v WHERE v.call_id = rf.call_id AND v.applied_to = rf.refund_id),
+		COALESCE(rf.rejected, '')
+	FROM refunds rf
+	WHERE rf.call_id = $1
+	ORDER BY rf.created_at;`, callID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []model.RefundCall
+	for rows.Next() {
+		refund, err := scanRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+	return refunds, rows.Err()
+}
+
+func scanRefund(rows *sql.Rows) (model.RefundCall, error) {
+	var refund model.RefundCall
+	var amount, currency sql.NullString
+	var percent sql.NullInt64
+	if err := rows.Scan(&refund.CallID, &refund.RefundID, &refund.Reason, &amount, &currency, &percent, &refund.Reversed, &refund.Rejected); err != nil {
+		return refund, err
+	}
+	if amount.Valid {
+		m, err := model.ParseMoney(amount.String, currency.String)
+		if err != nil {
+			return refund, err
+		}
+		refund.Amount = &m
+	}
+	if percent.Valid {
+		p := int(percent.Int64)
+		refund.Percent = &p
+	}
+	return refund, nil
+}
+
 func (r *PostgresCallRepository) GetCallStatus(callID string) (string, error) {
 	const query = `SELECT status FROM calls WHERE call_id = $1`
 	var status string
@@ -117,6 +735,40 @@ func (r *PostgresCallRepository) GetCallStatus(callID string) (string, error) {
 	return status, err
 }
 
+func (r *PostgresCallRepository) GetCall(callID string) (*model.NewIncomingCall, error) {
+	const query = `
+	SELECT caller, receiver, duration_in_seconds, start_timestamp,
+		COALESCE(caller_country, ''), COALESCE(caller_type, ''),
+		COALESCE(receiver_country, ''), COALESCE(receiver_type, '')
+	FROM calls
+	WHERE call_id = $1;`
+
+	var caller, receiver sql.NullString
+	var duration sql.NullInt64
+	var start sql.NullTime
+	var n model.CallNumbering
+	err := r.db.QueryRow(query, callID).Scan(&caller, &receiver, &duration, &start,
+		&n.CallerCountry, &n.CallerType, &n.ReceiverCountry, &n.ReceiverType)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo llamada: %w", err)
+	}
+
+	call := &model.NewIncomingCall{
+		CallID:        callID,
+		Caller:        caller.String,
+		Receiver:      receiver.String,
+		DurationInSec: int(duration.Int64),
+		Numbering:     n,
+	}
+	if start.Valid {
+		call.StartTimestamp = start.Time.UTC()
+	}
+	return call, nil
+}
+
 func (r *PostgresCallRepository) FillMissingCallData(call model.NewIncomingCall) error {
 	const query = `
 	UPDATE calls
@@ -124,10 +776,230 @@ func (r *PostgresCallRepository) FillMissingCallData(call model.NewIncomingCall)
 		receiver = $2,
 		duration_in_seconds = $3,
 		start_timestamp = $4,
-		status = 'REFUNDED'
-	WHERE call_id = $5 AND status = 'REFUND_PARTIALLY';`
+		caller_country = $5,
+		caller_type = $6,
+		receiver_country = $7,
+		receiver_type = $8,
+		status = $9,
+		prior_status = 'PENDING'
+	WHERE call_id = $10 AND status = 'REFUND_PARTIALLY';`
 
-	_, err := r.db.Exec(query, call.Caller, call.Receiver, call.DurationInSec, call.StartTimestamp, call.CallID)
+	// Con solo refunds parciales la llamada queda PENDING para tarifarla
+	refunds, err := loadRefunds(r.db, call.CallID)
+	if err != nil {
+		return err
+	}
+	n := call.Numbering
+	_, err = r.db.Exec(query, call.Caller, call.Receiver, call.DurationInSec, call.StartTimestamp,
+		nullString(n.CallerCountry), nullString(string(n.CallerType)), nullString(n.ReceiverCountry), nullString(string(n.ReceiverType)),
+		model.FilledStatus(refunds), call.CallID)
 	return err
 }
 
+func (r *PostgresCallRepository) BilledCalls(from, to time.Time) ([]model.BilledCall, error) {
+	const query = `
+	SELECT c.call_id, COALESCE(c.caller, ''), c.start_timestamp, c.status,
+		COALESCE(c.cost, 0)::text, COALESCE(c.currency, ''), c.base_cost::text, c.base_currency,
+		COALESCE(c.provider, ''), c.taxes, COALESCE(c.rate_id, ''), c.billed_duration_sec,
+		COALESCE(c.provider_reference, ''),
+		COALESCE(c.caller_country, ''), COALESCE(c.caller_type, ''),
+		COALESCE(c.receiver_country, ''), COALESCE(c.receiver_type, ''),
+		cr.severity, cr.issue_type, cr.percent,
+		a.cost::text, a.currency, a.reason, a.actor, a.created_at
+	FROM calls c
+	LEFT JOIN call_credits cr ON cr.call_id = c.call_id
+	LEFT JOIN cost_adjustments a ON a.call_id = c.call_id
+	WHERE c.start_timestamp >= $1 AND c.start_timestamp < $2
+	AND c.status IN ('OK', 'REFUNDED')
+	ORDER BY c.start_timestamp;`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo llamadas facturadas: %w", err)
+	}
+	defer rows.Close()
+
+	var calls []model.BilledCall
+	for rows.Next() {
+		var c model.BilledCall
+		var cost, currency string
+		var baseCost, baseCurrency sql.NullString
+		var taxes sql.NullString
+		var billed sql.NullInt64
+		var severity, issueType sql.NullString
+		var percent sql.NullInt64
+		var adjCost, adjCurrency, adjReason, adjActor sql.NullString
+		var adjAt sql.NullTime
+		if err := rows.Scan(&c.CallID, &c.Caller, &c.StartTimestamp, &c.Status, &cost, &currency, &baseCost, &baseCurrency, &c.Provider,
+			&taxes, &c.Breakdown.RateID, &billed, &c.Breakdown.ProviderReference,
+			&c.Numbering.CallerCountry, &c.Numbering.CallerType, &c.Numbering.ReceiverCountry, &c.Numbering.ReceiverType,
+			&severity, &issueType, &percent,
+			&adjCost, &adjCurrency, &adjReason, &adjActor, &adjAt); err != nil {
+			return nil, err
+		}
+		if adjCost.Valid {
+			m, err := model.ParseMoney(adjCost.String, adjCurrency.String)
+			if err != nil {
+				return nil, err
+			}
+			c.Adjustment = &model.CostAdjustment{CallID: c.CallID, Cost: m, Reason: adjReason.String, Actor: adjActor.String, At: adjAt.Time.UTC()}
+		}
+		if percent.Valid {
+			c.Credit = &model.QualityCredit{CallID: c.CallID, Severity: model.QualitySeverity(severity.String),
+				IssueType: issueType.String, Percent: int(percent.Int64)}
+		}
+		if taxes.Valid {
+			if err := json.Unmarshal([]byte(taxes.String), &c.Breakdown.Taxes); err != nil {
+				return nil, fmt.Errorf("error leyendo impuestos de call_id=%s: %w", c.CallID, err)
+			}
+		}
+		if billed.Valid {
+			d := int(billed.Int64)
+			c.Breakdown.BilledDurationSec = &d
+		}
+		if c.Cost, err = model.ParseMoney(cost, currency); err != nil {
+			return nil, err
+		}
+		if baseCost.Valid {
+			base, err := model.ParseMoney(baseCost.String, baseCurrency.String)
+			if err != nil {
+				return nil, err
+			}
+			c.BaseCost = &base
+		}
+		calls = append(calls, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.attachRefunds(calls, from, to); err != nil {
+		return nil, fmt.Errorf("error leyendo refunds: %w", err)
+	}
+	return calls, nil
+}
+
+func (r *PostgresCallRepository) attachRefunds(calls []model.BilledCall, from, to time.Time) error {
+	rows, err := r.db.Query(`
+	SELECT rf.call_id, rf.refund_id, COALESCE(rf.reason, ''), rf.amount::text, rf.currency, rf.percent,
+		EXISTS (SELECT 1 FROM refund_reversals v WHERE v.call_id = rf.call_id AND v.applied_to = rf.refund_id),
+		COALESCE(rf.rejected, '')
+	FROM refunds rf
+	JOIN calls c ON c.call_id = rf.call_id
+	WHERE c.start_timestamp >= $1 AND c.start_timestamp < $2
+	AND c.status IN ('OK', 'REFUNDED')
+	ORDER BY rf.created_at;`, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := make(map[string]int, len(calls))
+	for i, c := range calls {
+		index[c.CallID] = i
+	}
+	for rows.Next() {
+		refund, err := scanRefund(rows)
+		if err != nil {
+			return err
+		}
+		if i, ok := index[refund.CallID]; ok {
+			calls[i].Refunds = append(calls[i].Refunds, refund)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *PostgresCallRepository) SaveQualityCredit(credit model.QualityCredit) (bool, error) {
+	const query = `
+	INSERT INTO call_credits (call_id, severity, issue_type, percent, created_at)
+	VALUES ($1, $2, $3, $4, NOW())
+	ON CONFLICT (call_id) DO UPDATE
+	SET severity = EXCLUDED.severity,
+		issue_type = EXCLUDED.issue_type,
+		percent = EXCLUDED.percent,
+		created_at = EXCLUDED.created_at
+	WHERE call_credits.percent < EXCLUDED.percent;`
+	res, err := r.db.Exec(query, credit.CallID, string(credit.Severity), credit.IssueType, credit.Percent)
+	if err != nil {
+		return false, fmt.Errorf("error guardando crédito por calidad: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error guardando crédito por calidad: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *PostgresCallRepository) SaveShadowCost(callID string, shadow model.ShadowCost) error {
+	var cost, currency, errMsg sql.NullString
+	if shadow.Cost != nil {
+		if err := shadow.Cost.CheckPrecision(repository.CostPrecision, repository.CostScale); err != nil {
+			return fmt.Errorf("error guardando costo en sombra: %w", err)
+		}
+		cost = sql.NullString{String: shadow.Cost.String(), Valid: true}
+		currency = sql.NullString{String: shadow.Cost.Currency, Valid: true}
+	}
+	if shadow.Error != "" {
+		errMsg = sql.NullString{String: shadow.Error, Valid: true}
+	}
+
+	const query = `
+	INSERT INTO shadow_costs (call_id, provider, cost, currency, error, created_at)
+	VALUES ($1, $2, $3, $4, $5, NOW())
+	ON CONFLICT (call_id) DO UPDATE
+	SET provider = EXCLUDED.provider,
+		cost = EXCLUDED.cost,
+		currency = EXCLUDED.currency,
+		error = EXCLUDED.error,
+		created_at = EXCLUDED.created_at;`
+	if _, err := r.db.Exec(query, callID, shadow.Provider, cost, currency, errMsg); err != nil {
+		return fmt.Errorf("error guardando costo en sombra: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresCallRepository) ShadowComparisons(from, to time.Time) ([]model.ShadowComparison, error) {
+	const query = `
+	SELECT c.call_id, COALESCE(c.receiver, ''), c.start_timestamp, c.status,
+		c.cost::text, c.currency, s.provider, s.cost::text, s.currency, COALESCE(s.error, '')
+	FROM shadow_costs s
+	JOIN calls c ON c.call_id = s.call_id
+	WHERE c.start_timestamp >= $1 AND c.start_timestamp < $2
+	ORDER BY c.start_timestamp;`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo costos en sombra: %w", err)
+	}
+	defer rows.Close()
+
+	var comparisons []model.ShadowComparison
+	for rows.Next() {
+		var c model.ShadowComparison
+		var cost, currency, shadowCost, shadowCurrency sql.NullString
+		if err := rows.Scan(&c.CallID, &c.Receiver, &c.StartTimestamp, &c.Status,
+			&cost, &currency, &c.Shadow.Provider, &shadowCost, &shadowCurrency, &c.Shadow.Error); err != nil {
+			return nil, err
+		}
+		if c.Status == "OK" && cost.Valid {
+			m, err := model.ParseMoney(cost.String, currency.String)
+			if err != nil {
+				return nil, err
+			}
+			c.Cost = &m
+		}
+		if shadowCost.Valid {
+			m, err := model.ParseMoney(shadowCost.String, shadowCurrency.String)
+			if err != nil {
+				return nil, err
+			}
+			c.Shadow.Cost = &m
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
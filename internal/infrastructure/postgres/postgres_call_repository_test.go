@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+	"phonecall-cost-processor-service/internal/domain/port/repository/repositorytest"
 	"testing"
 	"time"
 
@@ -53,10 +55,15 @@ func createTable() {
 	if err != nil {
 		log.Fatalf("❌ Error creando tabla: %v", err)
 	}
+	if err := migrate(db); err != nil {
+		log.Fatalf("❌ Error migrando schema: %v", err)
+	}
 }
 
 func clearCallsTable() {
 	_, _ = db.Exec("DELETE FROM calls;")
+	_, _ = db.Exec("DELETE FROM fx_rates;")
+	_, _ = db.Exec("DELETE FROM rate_cards;")
 }
 
 func setupTest(t *testing.T) *PostgresCallRepository {
@@ -71,7 +78,7 @@ func TestSaveIncomingCall(t *testing.T) {
 		Caller:         "juan",
 		Receiver:       "pedro",
 		DurationInSec:  60,
-		StartTimestamp: time.Now().Format(time.RFC3339),
+		StartTimestamp: time.Now(),
 	}
 	if err := repo.SaveIncomingCall(call); err != nil {
 		t.Fatalf("error guardando call: %v", err)
@@ -81,7 +88,7 @@ func TestSaveIncomingCall(t *testing.T) {
 func TestSaveAndGetStatus(t *testing.T) {
 	repo := setupTest(t)
 	callID := uuid.New().String()
-	call := model.NewIncomingCall{CallID: callID, Caller: "Juan", Receiver: "Maria", DurationInSec: 120, StartTimestamp: time.Now().Format(time.RFC3339)}
+	call := model.NewIncomingCall{CallID: callID, Caller: "Juan", Receiver: "Maria", DurationInSec: 120, StartTimestamp: time.Now()}
 	_ = repo.SaveIncomingCall(call)
 	status, err := repo.GetCallStatus(callID)
 	if err != nil || status != "PENDING" {
@@ -92,9 +99,9 @@ func TestSaveAndGetStatus(t *testing.T) {
 func TestUpdateCallCost(t *testing.T) {
 	repo := setupTest(t)
 	callID := uuid.New().String()
-	call := model.NewIncomingCall{CallID: callID, Caller: "Ana", Receiver: "Luis", DurationInSec: 80, StartTimestamp: time.Now().Format(time.RFC3339)}
+	call := model.NewIncomingCall{CallID: callID, Caller: "Ana", Receiver: "Luis", DurationInSec: 80, StartTimestamp: time.Now()}
 	_ = repo.SaveIncomingCall(call)
-	_ = repo.UpdateCallCost(callID, 19.99, "USD")
+	_ = repo.UpdateCallCost(callID, model.CostResult{Cost: model.MustParseMoney("19.99", "USD")})
 	status, err := repo.GetCallStatus(callID)
 	if err != nil || status != "OK" {
 		t.Fatalf("expected status OK, got %s (err: %v)", status, err)
@@ -105,7 +112,7 @@ func TestApplyRefund_NewCall(t *testing.T) {
 	repo := setupTest(t)
 	callID := uuid.New().String()
 	refund := model.RefundCall{CallID: callID, Reason: "Cobro duplicado"}
-	_ = repo.ApplyRefund(refund)
+	_, _ = repo.ApplyRefund(refund)
 	status, err := repo.GetCallStatus(callID)
 	if err != nil || status != "REFUND_PARTIALLY" {
 		t.Fatalf("expected status REFUND_PARTIALLY, got %s (err: %v)", status, err)
@@ -115,7 +122,7 @@ func TestApplyRefund_NewCall(t *testing.T) {
 func TestMarkCostAsFailed(t *testing.T) {
 	repo := setupTest(t)
 	callID := uuid.New().String()
-	call := model.NewIncomingCall{CallID: callID, Caller: "Pepe", Receiver: "Lalo", DurationInSec: 45, StartTimestamp: time.Now().Format(time.RFC3339)}
+	call := model.NewIncomingCall{CallID: callID, Caller: "Pepe", Receiver: "Lalo", DurationInSec: 45, StartTimestamp: time.Now()}
 	_ = repo.SaveIncomingCall(call)
 	_ = repo.MarkCostAsFailed(callID)
 	status, err := repo.GetCallStatus(callID)
@@ -127,7 +134,7 @@ func TestMarkCostAsFailed(t *testing.T) {
 func TestMarkCallAsInvalid(t *testing.T) {
 	repo := setupTest(t)
 	callID := uuid.New().String()
-	call := model.NewIncomingCall{CallID: callID, Caller: "Tom", Receiver: "Jerry", DurationInSec: 20, StartTimestamp: time.Now().Format(time.RFC3339)}
+	call := model.NewIncomingCall{CallID: callID, Caller: "Tom", Receiver: "Jerry", DurationInSec: 20, StartTimestamp: time.Now()}
 	_ = repo.SaveIncomingCall(call)
 	_ = repo.MarkCallAsInvalid(callID)
 	status, err := repo.GetCallStatus(callID)
@@ -140,8 +147,8 @@ func TestFillMissingCallData(t *testing.T) {
 	repo := setupTest(t)
 	callID := uuid.New().String()
 	refund := model.RefundCall{CallID: callID, Reason: "error"}
-	_ = repo.ApplyRefund(refund)
-	fill := model.NewIncomingCall{CallID: callID, Caller: "Carlos", Receiver: "Daniela", DurationInSec: 100, StartTimestamp: time.Now().Format(time.RFC3339)}
+	_, _ = repo.ApplyRefund(refund)
+	fill := model.NewIncomingCall{CallID: callID, Caller: "Carlos", Receiver: "Daniela", DurationInSec: 100, StartTimestamp: time.Now()}
 	if err := repo.FillMissingCallData(fill); err != nil {
 		t.Fatalf("expected no error filling data, got %v", err)
 	}
@@ -165,7 +172,7 @@ func TestRefundPartiallyThenFill_ShouldBecomeRefunded(t *testing.T) {
 	callID := uuid.New().String()
 
 	refund := model.RefundCall{CallID: callID, Reason: "Cobro anticipado"}
-	if err := repo.ApplyRefund(refund); err != nil {
+	if _, err := repo.ApplyRefund(refund); err != nil {
 		t.Fatalf("error aplicando refund: %v", err)
 	}
 	status, _ := repo.GetCallStatus(callID)
@@ -178,7 +185,7 @@ func TestRefundPartiallyThenFill_ShouldBecomeRefunded(t *testing.T) {
 		Caller:         "Leo",
 		Receiver:       "Max",
 		DurationInSec:  50,
-		StartTimestamp: time.Now().Format(time.RFC3339),
+		StartTimestamp: time.Now(),
 	}
 	if err := repo.FillMissingCallData(call); err != nil {
 		t.Fatalf("error llenando datos faltantes: %v", err)
@@ -188,3 +195,99 @@ func TestRefundPartiallyThenFill_ShouldBecomeRefunded(t *testing.T) {
 		t.Fatalf("expected status REFUNDED after filling, got %s", status)
 	}
 }
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.CallRepository {
+		return setupTest(t)
+	})
+}
+
+func TestMigrate_BackfillsPlaceholderCreatedAt(t *testing.T) {
+	clearCallsTable()
+	placeholder := "11111111-1111-1111-1111-111111111111"
+	call := "22222222-2222-2222-2222-222222222222"
+	if _, err := db.Exec("ALTER TABLE calls DROP COLUMN created_at"); err != nil {
+		t.Fatalf("error quitando created_at: %v", err)
+	}
+	_, err := db.Exec(`INSERT INTO calls (call_id, caller, status, processed_at) VALUES
+		($1, NULL, 'REFUND_PARTIALLY', now() - interval '5 days'),
+		($2, '+5491100000001', 'OK', now() - interval '5 days')`, placeholder, call)
+	if err != nil {
+		t.Fatalf("error insertando filas previas: %v", err)
+	}
+
+	if err := migrate(db); err != nil {
+		t.Fatalf("error migrando: %v", err)
+	}
+	backfilled := func(callID string) bool {
+		var same bool
+		if err := db.QueryRow("SELECT created_at = processed_at FROM calls WHERE call_id = $1", callID).Scan(&same); err != nil {
+			t.Fatalf("error leyendo created_at: %v", err)
+		}
+		return same
+	}
+	if !backfilled(placeholder) {
+		t.Fatalf("expected placeholder created_at backfilled from processed_at")
+	}
+	if backfilled(call) {
+		t.Fatalf("expected call created_at left at migration time")
+	}
+
+	// Migraciones posteriores no vuelven a mover created_at
+	if _, err := db.Exec("UPDATE calls SET processed_at = now() WHERE call_id = $1", placeholder); err != nil {
+		t.Fatalf("error actualizando processed_at: %v", err)
+	}
+	if err := migrate(db); err != nil {
+		t.Fatalf("error migrando: %v", err)
+	}
+	if backfilled(placeholder) {
+		t.Fatalf("expected backfill to run only once")
+	}
+}
+
+func TestFXRateRepository_GetRateEffectiveOnDate(t *testing.T) {
+	clearCallsTable()
+	repo := NewPostgresFXRateRepository(db)
+	day1 := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	r1, _ := model.ParseFXRate(day1, "USD", "ARS", "900")
+	r2, _ := model.ParseFXRate(day1.AddDate(0, 0, 2), "USD", "ARS", "950.5")
+	if err := repo.SaveRates([]model.FXRate{r1, r2}); err != nil {
+		t.Fatalf("error guardando cotizaciones: %v", err)
+	}
+
+	got, err := repo.GetRate("USD", "ARS", day1.AddDate(0, 0, 1).Add(15*time.Hour))
+	if err != nil || got.Rate.Cmp(r1.Rate) != 0 {
+		t.Fatalf("expected rate 900, got %v (err: %v)", got.Rate, err)
+	}
+
+	if _, err := repo.GetRate("USD", "ARS", day1.AddDate(0, 0, -1)); err != repository.ErrRateNotFound {
+		t.Fatalf("expected ErrRateNotFound, got %v", err)
+	}
+}
+
+func TestRateCardRepository_ActiveRateCard(t *testing.T) {
+	clearCallsTable()
+	repo := NewPostgresRateCardRepository(db)
+	v1 := model.RateCard{
+		Version:       "2024-08",
+		EffectiveFrom: time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+		Currency:      "ARS",
+		Rates:         []model.Rate{{Prefix: "+54", PerMinute: "10", IncrementSeconds: 60}},
+	}
+	v2 := v1
+	v2.Version = "2024-09"
+	v2.EffectiveFrom = time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	for _, card := range []model.RateCard{v1, v2} {
+		if err := repo.SaveRateCard(card); err != nil {
+			t.Fatalf("error guardando tarifario: %v", err)
+		}
+	}
+
+	got, err := repo.ActiveRateCard(time.Date(2024, 8, 20, 0, 0, 0, 0, time.UTC))
+	if err != nil || got.Version != "2024-08" {
+		t.Fatalf("expected 2024-08, got %q (err: %v)", got.Version, err)
+	}
+	if _, err := repo.ActiveRateCard(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)); err != repository.ErrRateCardNotFound {
+		t.Fatalf("expected ErrRateCardNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+type PostgresFXRateRepository struct {
+	db *sql.DB
+}
+
+var (
+	_ repository.FXRateReader = (*PostgresFXRateRepository)(nil)
+	_ repository.FXRateWriter = (*PostgresFXRateRepository)(nil)
+)
+
+func NewPostgresFXRateRepository(db *sql.DB) *PostgresFXRateRepository {
+	return &PostgresFXRateRepository{db: db}
+}
+
+func (r *PostgresFXRateRepository) SaveRates(rates []model.FXRate) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const query = `
+	INSERT INTO fx_rates (rate_date, from_currency, to_currency, rate)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (rate_date, from_currency, to_currency) DO UPDATE
+	SET rate = EXCLUDED.rate;`
+	for _, rate := range rates {
+		if _, err := tx.Exec(query, rate.Date, rate.From, rate.To, rate.Rate.FloatString(10)); err != nil {
+			return fmt.Errorf("error guardando cotización %s/%s %s: %w", rate.From, rate.To, rate.Date.Format("2006-01-02"), err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresFXRateRepository) GetRate(from, to string, on time.Time) (model.FXRate, error) {
+	const query = `
+	SELECT rate_date, rate::text
+	FROM fx_rates
+	WHERE from_currency = $1 AND to_currency = $2 AND rate_date <= $3
+	ORDER BY rate_date DESC
+	LIMIT 1;`
+
+	var date time.Time
+	var rate string
+	err := r.db.QueryRow(query, from, to, model.DateOnly(on)).Scan(&date, &rate)
+	if err == sql.ErrNoRows {
+		return model.FXRate{}, repository.ErrRateNotFound
+	}
+	if err != nil {
+		return model.FXRate{}, fmt.Errorf("error leyendo cotización: %w", err)
+	}
+	return model.ParseFXRate(date, from, to, rate)
+}
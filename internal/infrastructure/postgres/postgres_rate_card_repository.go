@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+// PostgresRateCardRepository guarda cada versión del tarifario como JSONB.
+type PostgresRateCardRepository struct {
+	db *sql.DB
+}
+
+var (
+	_ repository.RateCardReader = (*PostgresRateCardRepository)(nil)
+	_ repository.RateCardWriter = (*PostgresRateCardRepository)(nil)
+)
+
+func NewPostgresRateCardRepository(db *sql.DB) *PostgresRateCardRepository {
+	return &PostgresRateCardRepository{db: db}
+}
+
+func (r *PostgresRateCardRepository) SaveRateCard(card model.RateCard) error {
+	if err := card.Validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+	INSERT INTO rate_cards (version, effective_from, card)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (version) DO UPDATE
+	SET effective_from = EXCLUDED.effective_from,
+		card = EXCLUDED.card;`
+	if _, err := r.db.Exec(query, card.Version, card.EffectiveFrom, data); err != nil {
+		return fmt.Errorf("error guardando tarifario %s: %w", card.Version, err)
+	}
+	return nil
+}
+
+func (r *PostgresRateCardRepository) ActiveRateCard(at time.Time) (model.RateCard, error) {
+	const query = `
+	SELECT card
+	FROM rate_cards
+	WHERE effective_from <= $1
+	ORDER BY effective_from DESC
+	LIMIT 1;`
+
+	var data []byte
+	err := r.db.QueryRow(query, at).Scan(&data)
+	if err == sql.ErrNoRows {
+		return model.RateCard{}, repository.ErrRateCardNotFound
+	}
+	if err != nil {
+		return model.RateCard{}, fmt.Errorf("error leyendo tarifario: %w", err)
+	}
+
+	var card model.RateCard
+	if err := json.Unmarshal(data, &card); err != nil {
+		return model.RateCard{}, fmt.Errorf("error decodificando tarifario: %w", err)
+	}
+	return card, nil
+}
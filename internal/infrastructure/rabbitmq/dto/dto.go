@@ -1,15 +1,81 @@
 package dto
 
+import (
+  "encoding/json"
+  "time"
+)
+
 type NewIncomingCallDTO struct {
-  CallID         string `json:"call_id"`
-  Caller         string `json:"caller"`
-  Receiver       string `json:"receiver"`
-  DurationInSec  int    `json:"duration_in_seconds"`
-  StartTimestamp string `json:"start_timestamp"`
+  CallID         string    `json:"call_id"`
+  Caller         string    `json:"caller"`
+  Receiver       string    `json:"receiver"`
+  DurationInSec  int       `json:"duration_in_seconds"`
+  StartTimestamp Timestamp `json:"start_timestamp"`
 }
 
+// RefundCallDTO: sin amount ni percent el refund es total. amount se toma
+// literal (sin pasar por float64) en la moneda de currency.
 type RefundCallDTO struct {
-  CallID string `json:"call_id"`
-  Reason string `json:"reason"`
+  CallID   string      `json:"call_id"`
+  Reason   string      `json:"reason"`
+  RefundID string      `json:"refund_id,omitempty"`
+  Amount   json.Number `json:"amount,omitempty"`
+  Currency string      `json:"currency,omitempty"`
+  Percent  *int        `json:"percent,omitempty"`
+}
+
+// RefundReversedDTO: sin refund_id anula el refund total de la llamada.
+type RefundReversedDTO struct {
+  CallID   string `json:"call_id"`
+  RefundID string `json:"refund_id,omitempty"`
+  Reason   string `json:"reason"`
 }
 
+// CallCostAdjustedDTO: cost se toma literal (sin pasar por float64) en la
+// moneda de currency. Lo usan el mensaje y el endpoint de administración.
+type CallCostAdjustedDTO struct {
+  CallID   string      `json:"call_id"`
+  Cost     json.Number `json:"cost"`
+  Currency string      `json:"currency"`
+  Reason   string      `json:"reason"`
+  Actor    string      `json:"actor"`
+}
+
+// BulkRefundDTO: callers (números exactos) o caller_prefix, llamadas con
+// start_timestamp en [from, to) en RFC3339. Sin statuses acepta cualquier
+// estado; sin percent los refunds son totales. Lo usan el mensaje y el
+// endpoint de administración.
+type BulkRefundDTO struct {
+  BulkID       string    `json:"bulk_id,omitempty"`
+  Callers      []string  `json:"callers,omitempty"`
+  CallerPrefix string    `json:"caller_prefix,omitempty"`
+  From         time.Time `json:"from"`
+  To           time.Time `json:"to"`
+  Statuses     []string  `json:"statuses,omitempty"`
+  Reason       string    `json:"reason"`
+  Percent      *int      `json:"percent,omitempty"`
+}
+
+type CallQualityIssueDTO struct {
+  CallID    string `json:"call_id"`
+  Severity  string `json:"severity"`
+  IssueType string `json:"issue_type"`
+}
+
+// Timestamp conserva start_timestamp tal como llegó: texto o número (epoch).
+// Lo interpreta model.TimestampParser.
+type Timestamp string
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+  var s string
+  if err := json.Unmarshal(data, &s); err == nil {
+    *t = Timestamp(s)
+    return nil
+  }
+  var n json.Number
+  if err := json.Unmarshal(data, &n); err != nil {
+    return err
+  }
+  *t = Timestamp(n)
+  return nil
+}
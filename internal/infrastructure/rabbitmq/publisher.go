@@ -0,0 +1,53 @@
+package rabbitmq
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"phonecall-cost-processor-service/internal/infrastructure/messaging"
+
+	"github.com/streadway/amqp"
+)
+
+// Publisher publica eventos como CloudEvents en modo estructurado.
+type Publisher struct {
+	ch     *amqp.Channel
+	queue  string
+	source string
+}
+
+func NewPublisher(ch *amqp.Channel, queue, source string) *Publisher {
+	return &Publisher{ch: ch, queue: queue, source: source}
+}
+
+// Publish envía un evento del tipo dado. El subject es el call_id afectado.
+func (p *Publisher) Publish(eventType, callID string, data interface{}) error {
+	body, err := messaging.NewCloudEvent(p.source, eventType, callID, data)
+	if err != nil {
+		return err
+	}
+
+	return p.ch.Publish("", p.queue, false, false, amqp.Publishing{
+		ContentType:  messaging.CloudEventsContentType,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Body:         body,
+	})
+}
+
+// PublishLegacy envía el mensaje con el sobre {type, body} que usan los
+// productores anteriores a CloudEvents.
+func (p *Publisher) PublishLegacy(msgType string, data interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"type": msgType, "body": data})
+	if err != nil {
+		return fmt.Errorf("error serializando mensaje: %w", err)
+	}
+
+	return p.ch.Publish("", p.queue, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Body:         body,
+	})
+}
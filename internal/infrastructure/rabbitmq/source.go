@@ -0,0 +1,161 @@
+package rabbitmq
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/port/messaging"
+
+	"github.com/streadway/amqp"
+)
+
+// Header con la cantidad de reintentos ya hechos de un mensaje.
+const retryCountHeader = "x-retry-count"
+
+// Source adapta una cola de RabbitMQ a messaging.MessageSource con ack manual.
+// Los reintentos esperan en una cola <queue>.retry.<ms>ms por cada espera, que
+// por TTL los devuelve a la cola principal, y los mensajes que agotan los
+// reintentos van a <queue>.dead-letter.
+type Source struct {
+	ch    *amqp.Channel
+	queue string
+
+	mu          sync.Mutex
+	retryQueues map[time.Duration]string
+}
+
+var (
+	_ messaging.MessageSource     = (*Source)(nil)
+	_ messaging.RetryableDelivery = (*delivery)(nil)
+)
+
+func NewSource(ch *amqp.Channel, queue string) *Source {
+	return &Source{ch: ch, queue: queue, retryQueues: map[time.Duration]string{}}
+}
+
+func (s *Source) Deliveries() (<-chan messaging.Delivery, error) {
+	if _, err := s.ch.QueueDeclare(s.queue, true, false, false, false, nil); err != nil {
+		return nil, err
+	}
+	if _, err := s.ch.QueueDeclare(s.deadLetterQueue(), true, false, false, false, nil); err != nil {
+		return nil, err
+	}
+
+	msgs, err := s.ch.Consume(s.queue, "", false, false, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan messaging.Delivery)
+	go func() {
+		defer close(out)
+		for msg := range msgs {
+			out <- &delivery{msg: msg, source: s}
+		}
+	}()
+	return out, nil
+}
+
+func (s *Source) Close() error {
+	return s.ch.Close()
+}
+
+func (s *Source) deadLetterQueue() string { return s.queue + ".dead-letter" }
+
+// retryQueue declara, la primera vez, la cola de reintentos de delay. El TTL
+// es de la cola y no de cada mensaje: RabbitMQ solo vence mensajes desde la
+// cabeza, así que en una cola compartida una espera larga frenaría a las
+// cortas encoladas detrás.
+func (s *Source) retryQueue(delay time.Duration) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name, ok := s.retryQueues[delay]; ok {
+		return name, nil
+	}
+	ttl := delay.Milliseconds()
+	name := s.queue + ".retry." + strconv.FormatInt(ttl, 10) + "ms"
+	args := amqp.Table{"x-dead-letter-exchange": "", "x-dead-letter-routing-key": s.queue, "x-message-ttl": ttl}
+	if _, err := s.ch.QueueDeclare(name, true, false, false, false, args); err != nil {
+		return "", err
+	}
+	s.retryQueues[delay] = name
+	return name, nil
+}
+
+type delivery struct {
+	msg    amqp.Delivery
+	source *Source
+}
+
+func (d *delivery) Body() []byte                    { return d.msg.Body }
+func (d *delivery) ContentType() string             { return d.msg.ContentType }
+func (d *delivery) Headers() map[string]interface{} { return d.msg.Headers }
+func (d *delivery) Ack() error                      { return d.msg.Ack(false) }
+func (d *delivery) Nack(requeue bool) error         { return d.msg.Nack(false, requeue) }
+
+// Attempt cuenta los reintentos del header y, si el broker reentregó el
+// mensaje (p. ej. se cortó el consumidor), esa entrega también.
+func (d *delivery) Attempt() int {
+	attempt := retryCount(d.msg.Headers) + 1
+	if d.msg.Redelivered {
+		attempt++
+	}
+	return attempt
+}
+
+// Retry publica una copia en la cola de reintentos de delay, que la devuelve
+// a la cola principal cuando vence, y recién entonces confirma esta entrega.
+// Hay una cola por espera distinta (a lo sumo una por paso del backoff): con
+// una sola cola y TTL por mensaje, RabbitMQ no vence un mensaje hasta que
+// llega a la cabeza y una espera larga demora a las cortas de atrás.
+func (d *delivery) Retry(delay time.Duration) error {
+	queue, err := d.source.retryQueue(delay)
+	if err != nil {
+		return err
+	}
+	if err := d.source.ch.Publish("", queue, false, false, d.copy(d.Attempt())); err != nil {
+		return err
+	}
+	return d.msg.Ack(false)
+}
+
+func (d *delivery) DeadLetter(reason string) error {
+	p := d.copy(d.Attempt())
+	p.Headers["x-dead-letter-reason"] = reason
+	if err := d.source.ch.Publish("", d.source.deadLetterQueue(), false, false, p); err != nil {
+		return err
+	}
+	return d.msg.Ack(false)
+}
+
+// copy arma el mensaje a republicar con retries reintentos hechos.
+func (d *delivery) copy(retries int) amqp.Publishing {
+	headers := amqp.Table{}
+	for k, v := range d.msg.Headers {
+		headers[k] = v
+	}
+	headers[retryCountHeader] = int32(retries)
+	return amqp.Publishing{
+		Headers:       headers,
+		ContentType:   d.msg.ContentType,
+		DeliveryMode:  amqp.Persistent,
+		CorrelationId: d.msg.CorrelationId,
+		MessageId:     d.msg.MessageId,
+		Timestamp:     d.msg.Timestamp,
+		Type:          d.msg.Type,
+		Body:          d.msg.Body,
+	}
+}
+
+func retryCount(headers amqp.Table) int {
+	switch n := headers[retryCountHeader].(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+// Package rating carga los tarifarios del motor de tarifación local.
+package rating
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/model"
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+)
+
+// RateCards guarda versiones de tarifarios en memoria, ordenadas por
+// effective_from. Se llena desde archivos con LoadDir o con SaveRateCard.
+type RateCards struct {
+	mu    sync.RWMutex
+	cards []model.RateCard
+}
+
+var (
+	_ repository.RateCardReader = (*RateCards)(nil)
+	_ repository.RateCardWriter = (*RateCards)(nil)
+)
+
+func NewRateCards() *RateCards {
+	return &RateCards{}
+}
+
+// LoadDir carga todos los *.json del directorio; cada archivo es una versión.
+func LoadDir(dir string) (*RateCards, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no hay tarifarios en %s", dir)
+	}
+
+	cards := NewRateCards()
+	for _, f := range files {
+		card, err := ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if err := cards.SaveRateCard(card); err != nil {
+			return nil, err
+		}
+	}
+	return cards, nil
+}
+
+// ReadFile lee y valida un tarifario en JSON.
+func ReadFile(path string) (model.RateCard, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return model.RateCard{}, err
+	}
+	var card model.RateCard
+	if err := json.Unmarshal(data, &card); err != nil {
+		return model.RateCard{}, fmt.Errorf("error leyendo %s: %w", path, err)
+	}
+	if err := card.Validate(); err != nil {
+		return model.RateCard{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return card, nil
+}
+
+// SaveRateCard agrega una versión o reemplaza la que tenga el mismo Version.
+func (r *RateCards) SaveRateCard(card model.RateCard) error {
+	if err := card.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.cards {
+		if r.cards[i].Version == card.Version {
+			r.cards[i] = card
+			r.sort()
+			return nil
+		}
+	}
+	r.cards = append(r.cards, card)
+	r.sort()
+	return nil
+}
+
+func (r *RateCards) ActiveRateCard(at time.Time) (model.RateCard, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := len(r.cards) - 1; i >= 0; i-- {
+		if !r.cards[i].EffectiveFrom.After(at) {
+			return r.cards[i], nil
+		}
+	}
+	return model.RateCard{}, repository.ErrRateCardNotFound
+}
+
+func (r *RateCards) sort() {
+	sort.Slice(r.cards, func(i, j int) bool { return r.cards[i].EffectiveFrom.Before(r.cards[j].EffectiveFrom) })
+}
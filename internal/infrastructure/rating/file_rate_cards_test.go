@@ -0,0 +1,42 @@
+package rating
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/domain/port/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDir_SelectsVersionByEffectiveDate(t *testing.T) {
+	cards, err := LoadDir("testdata")
+	assert.NoError(t, err)
+
+	card, err := cards.ActiveRateCard(time.Date(2024, 9, 1, 2, 59, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-08", card.Version)
+
+	card, err = cards.ActiveRateCard(time.Date(2024, 9, 1, 3, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-09", card.Version)
+
+	_, err = cards.ActiveRateCard(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, repository.ErrRateCardNotFound)
+}
+
+func TestLoadDir_RejectsInvalidCard(t *testing.T) {
+	dir := t.TempDir()
+	invalid := `{"version":"x","currency":"ARS","rates":[{"prefix":"+54","per_minute":"1","increment_seconds":0}]}`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "x.json"), []byte(invalid), 0o644))
+
+	_, err := LoadDir(dir)
+	assert.Error(t, err)
+}
+
+func TestLoadDir_EmptyDir(t *testing.T) {
+	_, err := LoadDir(t.TempDir())
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+package traffic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"phonecall-cost-processor-service/internal/infrastructure/rabbitmq/dto"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TypeNewIncomingCall  = "new_incoming_call"
+	TypeRefundCall       = "refund_call"
+	TypeRefundReversed   = "refund_reversed"
+	TypeCallQualityIssue = "call_quality_issue"
+	TypeCallCostAdjusted = "call_cost_adjusted"
+)
+
+// Message es un mensaje listo para publicar con el sobre {type, body}.
+type Message struct {
+	Type   string          `json:"type"`
+	Body   json.RawMessage `json:"body"`
+	CallID string          `json:"-"`
+}
+
+func NewMessage(msgType string, body interface{}) (Message, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return Message{}, err
+	}
+	var ids struct {
+		CallID string `json:"call_id"`
+	}
+	_ = json.Unmarshal(raw, &ids)
+	return Message{Type: msgType, Body: raw, CallID: ids.CallID}, nil
+}
+
+// ParseMessages acepta un objeto, un array o varios objetos concatenados
+// (JSON-lines o pretty-printed) con el sobre {type, body}.
+func ParseMessages(data []byte) ([]Message, error) {
+	data = bytes.TrimSpace(data)
+	var msgs []Message
+
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return nil, err
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var m Message
+			err := dec.Decode(&m)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			msgs = append(msgs, m)
+		}
+	}
+
+	for i := range msgs {
+		if msgs[i].Type == "" {
+			return nil, fmt.Errorf("mensaje %d sin type", i+1)
+		}
+		m, err := NewMessage(msgs[i].Type, msgs[i].Body)
+		if err != nil {
+			return nil, err
+		}
+		msgs[i] = m
+	}
+	return msgs, nil
+}
+
+type GenerateOptions struct {
+	Count int
+	// DuplicateRatio es la fracción de llamadas que se publican dos veces.
+	DuplicateRatio float64
+	// RefundRatio es la fracción de llamadas que reciben un refund_call.
+	RefundRatio float64
+	// OutOfOrder publica el refund antes que la llamada (REFUND_PARTIALLY).
+	OutOfOrder bool
+	// From y To acotan el start_timestamp generado.
+	From, To time.Time
+	Rand     *rand.Rand
+}
+
+// Generate arma llamadas sintéticas con call_id, duración y timestamp aleatorios.
+func Generate(opts GenerateOptions) ([]Message, error) {
+	r := opts.Rand
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if opts.To.IsZero() {
+		opts.To = time.Now().UTC()
+	}
+	if opts.From.IsZero() || !opts.From.Before(opts.To) {
+		opts.From = opts.To.Add(-24 * time.Hour)
+	}
+	window := opts.To.Sub(opts.From)
+
+	var msgs []Message
+	for i := 0; i < opts.Count; i++ {
+		callID := uuid.NewString()
+		call, err := NewMessage(TypeNewIncomingCall, dto.NewIncomingCallDTO{
+			CallID:         callID,
+			Caller:         randomNumber(r),
+			Receiver:       randomNumber(r),
+			DurationInSec:  1 + r.Intn(3600),
+			StartTimestamp: dto.Timestamp(opts.From.Add(time.Duration(r.Int63n(int64(window)))).Format(time.RFC3339)),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var refund *Message
+		if r.Float64() < opts.RefundRatio {
+			m, err := NewMessage(TypeRefundCall, dto.RefundCallDTO{CallID: callID, Reason: "Refund sintético"})
+			if err != nil {
+				return nil, err
+			}
+			refund = &m
+		}
+
+		if refund != nil && opts.OutOfOrder {
+			msgs = append(msgs, *refund)
+		}
+		msgs = append(msgs, call)
+		if r.Float64() < opts.DuplicateRatio {
+			msgs = append(msgs, call)
+		}
+		if refund != nil && !opts.OutOfOrder {
+			msgs = append(msgs, *refund)
+		}
+	}
+	return msgs, nil
+}
+
+func randomNumber(r *rand.Rand) string {
+	return fmt.Sprintf("+54911%08d", r.Intn(100000000))
+}
@@ -0,0 +1,69 @@
+package traffic
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"phonecall-cost-processor-service/internal/infrastructure/rabbitmq/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate_OutOfOrderRefundsAndDuplicates(t *testing.T) {
+	msgs, err := Generate(GenerateOptions{
+		Count:          3,
+		DuplicateRatio: 1,
+		RefundRatio:    1,
+		OutOfOrder:     true,
+		Rand:           rand.New(rand.NewSource(1)),
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 9)
+	for i := 0; i < len(msgs); i += 3 {
+		assert.Equal(t, TypeRefundCall, msgs[i].Type)
+		assert.Equal(t, TypeNewIncomingCall, msgs[i+1].Type)
+		assert.Equal(t, msgs[i+1], msgs[i+2])
+		assert.Equal(t, msgs[i].CallID, msgs[i+1].CallID)
+	}
+}
+
+func TestGenerate_RespectsTimeWindow(t *testing.T) {
+	from := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+
+	msgs, err := Generate(GenerateOptions{Count: 20, From: from, To: to, Rand: rand.New(rand.NewSource(2))})
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 20)
+
+	for _, m := range msgs {
+		var d dto.NewIncomingCallDTO
+		assert.NoError(t, json.Unmarshal(m.Body, &d))
+		ts, err := time.Parse(time.RFC3339, string(d.StartTimestamp))
+		assert.NoError(t, err)
+		assert.False(t, ts.Before(from) || ts.After(to))
+		assert.Greater(t, d.DurationInSec, 0)
+	}
+}
+
+func TestParseMessages_ConcatenatedObjects(t *testing.T) {
+	data := []byte(`{
+  "type": "refund_call",
+  "body": {"call_id": "22222222-2222-2222-2222-222222222222", "reason": "Cobro indebido"}
+}
+{"type":"new_incoming_call","body":{"call_id":"22222222-2222-2222-2222-222222222222"}}`)
+
+	msgs, err := ParseMessages(data)
+
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "22222222-2222-2222-2222-222222222222", msgs[0].CallID)
+	assert.Equal(t, TypeNewIncomingCall, msgs[1].Type)
+}
+
+func TestParseMessages_ArrayWithoutType(t *testing.T) {
+	_, err := ParseMessages([]byte(`[{"body":{}}]`))
+	assert.Error(t, err)
+}
@@ -6,51 +6,65 @@ import (
 	"math/rand"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
 func StartMockCostAPI() {
 	rand.Seed(time.Now().UnixNano())
 
-	var retryCounter = make(map[string]int)
+	http.Handle("/calls/", NewMockCostAPIHandler())
+
+	go func() {
+		fmt.Println("🧪 Mock cost API escuchando en http://localhost:8080")
+		http.ListenAndServe(":8080", nil)
+	}()
+}
 
-	http.HandleFunc("/calls/", func(w http.ResponseWriter, r *http.Request) {
-		callID := extractCallID(r.URL.Path)
+// NewMockCostAPIHandler devuelve el handler de la API mock para montarlo en
+// un servidor propio (p. ej. httptest en los tests end-to-end).
+func NewMockCostAPIHandler() http.Handler {
+	var mu sync.Mutex
+	var retryCounter = make(map[string]int)
 
+	costFor := func(callID string) (int, map[string]interface{}) {
 		switch callID {
 		case "123e4567-e89b-12d3-a456-426614174999": // Falla intermitente: responde 5xx dos veces, luego OK
+			mu.Lock()
 			retryCounter[callID]++
-			if retryCounter[callID] <= 2 {
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(map[string]string{
+			attempt := retryCounter[callID]
+			mu.Unlock()
+			if attempt <= 2 {
+				return http.StatusInternalServerError, map[string]interface{}{
 					"message": "Algo explotó",
 					"code":    "internal_server_error",
-				})
-				return
+				}
 			}
 		case "123e4567-e89b-12d3-a456-426614174997": // Falla 5xx persistente
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{
+			return http.StatusInternalServerError, map[string]interface{}{
 				"message": "Falla permanente del servicio externo",
 				"code":    "permanent_internal_error",
-			})
-			return
+			}
 		case "123e4567-e89b-12d3-a456-426614174998": // 404 permanente
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(map[string]string{
+			return http.StatusNotFound, map[string]interface{}{
 				"message": "Llamada no encontrada",
 				"code":    "call_not_found",
-			})
-			return
+			}
 
 		case "11111111-1111-1111-1111-111111111111": // Llamada común o ya refundeada (dependiendo de DB)
 			// devuelvo un costo fijo para facilitar testing
-			w.WriteHeader(http.StatusOK)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"currency": "ARS",
-				"cost":     8.50,
-			})
-			return
+			return http.StatusOK, map[string]interface{}{
+				"currency":           "ARS",
+				"cost":               8.50,
+				"taxes":              []map[string]interface{}{{"name": "IVA", "amount": 1.48}},
+				"rate_id":            "mock-ars-std",
+				"provider_reference": "mock-" + callID,
+			}
+		case "22222222-2222-2222-2222-222222222222": // Refund antes de la llamada: se tarifa igual por si se anula
+			return http.StatusOK, map[string]interface{}{
+				"currency": "USD",
+				"cost":     5.00,
+			}
 		}
 
 		// Default aleatorio
@@ -58,17 +72,44 @@ func StartMockCostAPI() {
 		currencies := []string{"ARS", "USD", "EUR"}
 		currency := currencies[rand.Intn(len(currencies))]
 
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{
+		return http.StatusOK, map[string]interface{}{
 			"currency": currency,
 			"cost":     cost,
-		})
-	})
+		}
+	}
 
-	go func() {
-		fmt.Println("🧪 Mock cost API escuchando en http://localhost:8080")
-		http.ListenAndServe(":8080", nil)
-	}()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Lote: POST /calls/costs {"call_ids": [...]}. Cada resultado trae el
+		// costo o el error que hubiera devuelto la consulta individual.
+		if r.Method == http.MethodPost && r.URL.Path == "/calls/costs" {
+			var req struct {
+				CallIDs []string `json:"call_ids"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			results := make([]map[string]interface{}, 0, len(req.CallIDs))
+			for _, id := range req.CallIDs {
+				status, body := costFor(id)
+				if status != http.StatusOK {
+					body = map[string]interface{}{"error": map[string]interface{}{
+						"status":  status,
+						"code":    body["code"],
+						"message": body["message"],
+					}}
+				}
+				body["call_id"] = id
+				results = append(results, body)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+			return
+		}
+
+		status, body := costFor(extractCallID(r.URL.Path))
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	})
 }
 
 func extractCallID(path string) string {
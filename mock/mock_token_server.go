@@ -0,0 +1,83 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenServer es un servidor OAuth2 (grant client_credentials) para tests y
+// desarrollo local. Emite tokens opacos que vencen luego de TTL.
+type TokenServer struct {
+	clientID     string
+	clientSecret string
+	ttl          time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]time.Time
+	issued int
+}
+
+func NewTokenServer(clientID, clientSecret string, ttl time.Duration) *TokenServer {
+	return &TokenServer{clientID: clientID, clientSecret: clientSecret, ttl: ttl, tokens: map[string]time.Time{}}
+}
+
+func (s *TokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if r.Method != http.MethodPost || r.FormValue("grant_type") != "client_credentials" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	if !ok || id != s.clientID || secret != s.clientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	token := uuid.NewString()
+	s.mu.Lock()
+	s.tokens[token] = time.Now().Add(s.ttl)
+	s.issued++
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   int(s.ttl.Seconds()),
+	})
+}
+
+// Issued devuelve cuántos tokens se emitieron.
+func (s *TokenServer) Issued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issued
+}
+
+// Revoke invalida todos los tokens emitidos.
+func (s *TokenServer) Revoke() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = map[string]time.Time{}
+}
+
+// RequireBearer responde 401 si el request no trae un token vigente.
+func (s *TokenServer) RequireBearer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		s.mu.Lock()
+		expiry, ok := s.tokens[token]
+		s.mu.Unlock()
+		if !ok || time.Now().After(expiry) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}